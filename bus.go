@@ -28,8 +28,12 @@ type Bus struct {
 	publisherMiddleware []MiddlewarePublisherFunc
 
 	driver                 Driver
+	driverName             string
 	internalSchemaRegistry *internalSchemaRegistry
 	subscriberRegistry     *subscriberRegistry
+	outbox                 *outboxRelay
+	replyTopic             string
+	replies                *replyRegistry
 }
 
 // NewBus Allocate a new Bus with default configurations.
@@ -46,7 +50,7 @@ func NewBus(driver string, opts ...Option) *Bus {
 			records: map[string]string{},
 		}
 	}
-	return &Bus{
+	b := &Bus{
 		BaseContext: options.baseContext,
 		Marshaler:   options.marshaler,
 		Factories: Factories{
@@ -54,18 +58,34 @@ func NewBus(driver string, opts ...Option) *Bus {
 		},
 		SchemaRegistry: schemaRegistry,
 		Configuration: BusConfiguration{
-			MajorVersion:  options.majorVersion,
-			Driver:        options.driverConfig,
-			ConsumerGroup: options.consumerGroup,
+			MajorVersion:     options.majorVersion,
+			Driver:           options.driverConfig,
+			ConsumerGroup:    options.consumerGroup,
+			RetryPolicy:      options.retryPolicy,
+			DeadLetterPolicy: options.deadLetterPolicy,
+			DriverHooks:      options.driverHooks,
+			ContentMode:      options.contentMode,
 		},
 		Logger:                 options.logger,
 		Addresses:              options.cluster,
 		consumerMiddleware:     options.consumerMiddleware,
 		publisherMiddleware:    options.publisherMiddleware,
-		driver:                 drivers[driver],
+		driver:                 newDriver(driver),
+		driverName:             driver,
 		internalSchemaRegistry: newInternalSchemaRegistry(),
 		subscriberRegistry:     newSubscriberRegistry(),
+		replyTopic:             options.replyTopic,
+		replies:                newReplyRegistry(),
 	}
+	if b.replyTopic != "" {
+		b.SubscribeTopic(b.replyTopic).
+			Group(b.replyTopic).
+			HandlerFunc(b.replies.handle)
+	}
+	if options.outbox != nil && options.outbox.Store != nil {
+		b.outbox = newOutboxRelay(b, *options.outbox)
+	}
+	return b
 }
 
 func newBusDefaults() options {
@@ -85,25 +105,38 @@ func newBusDefaults() options {
 
 // RegisterSchema Link a message schema to specific metadata (MessageMetadata) and store it for Bus further operations.
 func (b *Bus) RegisterSchema(schema interface{}, opts ...SchemaRegistryOption) {
+	b.internalSchemaRegistry.register(schema, newMessageMetadata(opts...))
+}
+
+func newMessageMetadata(opts ...SchemaRegistryOption) MessageMetadata {
 	options := internalSchemaRegistryOptions{}
 	for _, o := range opts {
 		o.apply(&options)
 	}
-	b.internalSchemaRegistry.register(schema, MessageMetadata{
+	return MessageMetadata{
 		Topic:         options.topic,
 		Source:        options.source,
 		SchemaName:    options.schemaName,
 		SchemaVersion: options.version,
-	})
+		PartitionKey:  options.keyFunc,
+	}
 }
 
 // ListenAndServe Bootstrap and start a Bus along its internal components (subscribers).
 func (b *Bus) ListenAndServe() error {
 	b.driver.SetParentBus(b)
 	b.driver.SetInternalHandler(getInternalHandler(b))
+	if p, ok := b.driver.(Provisioner); ok {
+		if err := p.Provision(b.BaseContext); err != nil {
+			return err
+		}
+	}
 	if err := b.driver.Start(b.BaseContext); err != nil {
 		return err
 	}
+	if b.outbox != nil {
+		b.outbox.start(b.BaseContext)
+	}
 	return b.startSubscriberJobs()
 }
 
@@ -123,7 +156,7 @@ func (b *Bus) startSubscriberJobs() error {
 
 // Subscribe Set a subscription task using schema metadata.
 //
-// It will return nil if no schema was found on local schema registry.
+// It will return nil if no schema was found on local schema registry. Use SubscribeTo to get an error instead.
 func (b *Bus) Subscribe(schema interface{}) *Subscriber {
 	meta, err := b.internalSchemaRegistry.get(schema)
 	if err != nil {
@@ -141,6 +174,14 @@ func (b *Bus) SubscribeTopic(topic string) *Subscriber {
 	return entry
 }
 
+// getConsumerGroup Retrieve the consumer group of a Subscriber, falling back to the Bus global consumer group.
+func getConsumerGroup(b *Bus, sub *Subscriber) string {
+	if g := sub.GetGroup(); g != "" {
+		return g
+	}
+	return b.Configuration.ConsumerGroup
+}
+
 // ListSubscribersFromTopic Get the subscription task queue of a registered topic.
 func (b *Bus) ListSubscribersFromTopic(t string) []*Subscriber {
 	return b.subscriberRegistry.get(t)
@@ -148,13 +189,15 @@ func (b *Bus) ListSubscribersFromTopic(t string) []*Subscriber {
 
 // Publish Propagate a message to the ecosystem using the internal topic registry agent to generate the topic.
 //
+// Options may set CloudEvents attributes (WithSubject, WithExtension, WithPartitionKey) or select the message schema
+// (WithPublishTopic, WithPublishType).
+//
+// If the transactional outbox is enabled (WithOutbox), every publishing operation writes the message into the
+// OutboxStore instead of calling the driver.
+//
 // 	Note: To propagate correlation and causation IDs, use Subscription's context.
-func (b *Bus) Publish(ctx context.Context, data interface{}) error {
-	meta, err := b.internalSchemaRegistry.get(data)
-	if err != nil {
-		return err
-	}
-	msg, err := b.generateTransportMessage(meta, data)
+func (b *Bus) Publish(ctx context.Context, data interface{}, opts ...PublishOption) error {
+	msg, err := b.generatePublishMessage(data, newPublishOptions(opts))
 	if err != nil {
 		return err
 	}
@@ -163,90 +206,104 @@ func (b *Bus) Publish(ctx context.Context, data interface{}) error {
 
 // PublishWithTopic Propagate a message to the ecosystem using the internal schema registry to get the topic.
 //
-// 	Note: To propagate correlation and causation IDs, use Subscription's context.
+// Deprecated: Use Publish along WithPublishTopic instead.
 func (b *Bus) PublishWithTopic(ctx context.Context, topic string, data interface{}) error {
-	meta := b.internalSchemaRegistry.getByTopic(topic)
-	msg, err := b.generateTransportMessage(meta, data)
-	if err != nil {
-		return err
-	}
-	return b.publish(ctx, msg)
+	return b.Publish(ctx, data, WithPublishTopic(topic))
 }
 
 // PublishWithType Propagate a message to the ecosystem using the internal schema registry Go's struct type.
 //
-// 	Note: To propagate correlation and causation IDs, use Subscription's context.
+// Deprecated: Use Publish along WithPublishType instead.
 func (b *Bus) PublishWithType(ctx context.Context, msgType string, data interface{}) error {
-	meta, err := b.internalSchemaRegistry.getByKey(msgType)
-	if err != nil {
-		return err
-	}
-	msg, err := b.generateTransportMessage(meta, data)
-	if err != nil {
-		return err
-	}
-	return b.publish(ctx, msg)
+	return b.Publish(ctx, data, WithPublishType(msgType))
 }
 
 // PublishWithTypeAndSubject Propagate a message to the ecosystem using the internal schema registry Go's struct type
 // and the subject.
 //
-// 	Note: To propagate correlation and causation IDs, use Subscription's context.
+// Deprecated: Use Publish along WithPublishType and WithSubject instead.
 func (b *Bus) PublishWithTypeAndSubject(ctx context.Context, msgType, subject string, data interface{}) error {
-	meta, err := b.internalSchemaRegistry.getByKey(msgType)
-	if err != nil {
-		return err
-	}
-	msg, err := b.generateTransportMessage(meta, data)
-	if err != nil {
-		return err
-	}
-	msg.Subject = subject
-	return b.publish(ctx, msg)
+	return b.Publish(ctx, data, WithPublishType(msgType), WithSubject(subject))
 }
 
 // PublishWithTopicAndSubject Propagate a message to the ecosystem using the internal topic registry agent to generate the topic.
 //
-// This method also exposes the `Subject` property to define the CloudEvent property with the same name.
-//
-// 	Note: To propagate correlation and causation IDs, use Subscription's context.
+// Deprecated: Use Publish along WithPublishTopic and WithSubject instead.
 func (b *Bus) PublishWithTopicAndSubject(ctx context.Context, topic, subject string, data interface{}) error {
-	meta := b.internalSchemaRegistry.getByTopic(topic)
-	msg, err := b.generateTransportMessage(meta, data)
-	if err != nil {
-		return err
-	}
-	msg.Subject = subject
-	return b.publish(ctx, msg)
+	return b.Publish(ctx, data, WithPublishTopic(topic), WithSubject(subject))
 }
 
 // PublishWithSubject Propagate a message to the ecosystem using the internal topic registry agent to generate the topic.
 //
-// This method also exposes the `Subject` property to define the CloudEvent property with the same name.
+// Deprecated: Use Publish along WithSubject instead.
 func (b *Bus) PublishWithSubject(ctx context.Context, data interface{}, subject string) error {
-	meta, err := b.internalSchemaRegistry.get(data)
-	if err != nil {
-		return err
-	}
-	msg, err := b.generateTransportMessage(meta, data)
-	if err != nil {
-		return err
-	}
-	msg.Subject = subject
-	return b.publish(ctx, msg)
+	return b.Publish(ctx, data, WithSubject(subject))
 }
 
 // PublishBulk Propagate multiple messages to the ecosystem.
+//
+// If the driver implements BatchPublisher and no publisher middleware is registered, messages are propagated to the
+// driver as a single batch. Otherwise, messages go through the publisher middleware chain and the driver one by one.
 func (b *Bus) PublishBulk(ctx context.Context, data ...interface{}) error {
 	errs := new(multierror.Error)
+	msgs := make([]*TransportMessage, 0, len(data))
 	for _, d := range data {
-		if err := b.Publish(ctx, d); err != nil {
+		msg, err := b.generatePublishMessage(d, publishOptions{})
+		if err != nil {
 			errs = multierror.Append(err, errs)
+			continue
 		}
+		b.injectMessageContext(ctx, msg)
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) == 0 {
+		return errs.ErrorOrNil()
+	}
+	if err := b.publishBatch(ctx, msgs); err != nil {
+		errs = multierror.Append(err, errs)
 	}
 	return errs.ErrorOrNil()
 }
 
+func (b *Bus) publishBatch(ctx context.Context, msgs []*TransportMessage) error {
+	if b.outbox != nil {
+		return b.outbox.cfg.Store.Save(ctx, msgs...)
+	}
+	errs := new(multierror.Error)
+	batchPublisher, ok := b.driver.(BatchPublisher)
+	// publisher middlewares (e.g. metrics, tracing) observe the outcome of a single message delivery, so batches are
+	// only propagated as-is when no middleware is registered
+	if !ok || len(b.publisherMiddleware) > 0 {
+		for _, msg := range msgs {
+			if err := b.publishTransportMessage(ctx, msg); err != nil {
+				errs = multierror.Append(err, errs)
+			}
+		}
+		return errs.ErrorOrNil()
+	}
+
+	ctx = context.WithValue(ctx, contextDriver, gluonContextKey(b.driverName))
+	return batchPublisher.PublishBatch(ctx, msgs)
+}
+
+func (b *Bus) generatePublishMessage(data interface{}, opts publishOptions) (*TransportMessage, error) {
+	meta, err := opts.getMetadata(b.internalSchemaRegistry, data)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := b.generateTransportMessage(meta, data)
+	if err != nil {
+		return nil, err
+	}
+	if meta.PartitionKey != nil {
+		msg.Extensions = map[string]string{ExtensionPartitionKey: meta.PartitionKey(data)}
+	}
+	if err = opts.applyMessage(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
 func (b *Bus) generateTransportMessage(meta *MessageMetadata, data interface{}) (*TransportMessage, error) {
 	msgID, err := b.Factories.IDFactory.NewID()
 	if err != nil {
@@ -300,6 +357,16 @@ func (b *Bus) PublishRaw(ctx context.Context, msg *TransportMessage) error {
 
 func (b *Bus) publish(ctx context.Context, msg *TransportMessage) error {
 	b.injectMessageContext(ctx, msg)
+	if b.outbox != nil {
+		return b.outbox.cfg.Store.Save(ctx, msg)
+	}
+	return b.publishTransportMessage(ctx, msg)
+}
+
+// publishTransportMessage Propagate a message through the publisher middleware chain and the driver without
+// modifying its correlation and causation IDs.
+func (b *Bus) publishTransportMessage(ctx context.Context, msg *TransportMessage) error {
+	ctx = context.WithValue(ctx, contextDriver, gluonContextKey(b.driverName))
 	var handlerFunc PublisherFunc
 	handlerFunc = b.driver.Publish
 	for _, mw := range b.publisherMiddleware {
@@ -336,9 +403,32 @@ func (b *Bus) GetSchemaMetadataFromTopic(topic string) *MessageMetadata {
 
 // Shutdown Close a Bus and its internal resources gracefully.
 func (b *Bus) Shutdown(ctx context.Context) error {
+	if b.outbox != nil {
+		b.outbox.close()
+	}
 	return b.driver.Shutdown(ctx)
 }
 
 func (b *Bus) isLoggerEnabled() bool {
 	return true
 }
+
+// LogError Log an error using the Bus logger, detailing the kind and parent of Error values. Drivers should use
+// this method to report errors which cannot be returned to callers (e.g. failed acknowledgements).
+func (b *Bus) LogError(err error) {
+	logInternalError(b, err)
+}
+
+func logInternalError(b *Bus, err error) {
+	if err == nil || !b.isLoggerEnabled() {
+		return
+	}
+	if errG, ok := err.(Error); ok {
+		b.Logger.Error().
+			Str("error_type", errG.Kind()).
+			Str("error_parent", errG.ParentDescription()).
+			Msg(errG.Description())
+		return
+	}
+	b.Logger.Error().Msg(err.Error())
+}
@@ -3,6 +3,14 @@ package gluon
 type BusConfiguration struct {
 	ConsumerGroup string
 	MajorVersion  int
+	// RetryPolicy Global retry policy for consumer handlers, overridden by Subscriber.RetryPolicy
+	RetryPolicy *RetryPolicy
+	// DeadLetterPolicy Global dead-letter policy for consumer handlers, overridden by Subscriber.DeadLetterPolicy
+	DeadLetterPolicy *DeadLetterPolicy
+	// DriverHooks Callbacks used by drivers to report internal operations
+	DriverHooks DriverHooks
+	// ContentMode CloudEvents content mode used by drivers to encode messages
+	ContentMode ContentMode
 	// Driver Custom driver configuration(s)
 	Driver interface{}
 }
@@ -1,6 +1,7 @@
 package gluon
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -19,3 +20,64 @@ func TestBus_GetSchemaMetadata(t *testing.T) {
 		assert.Equal(t, "foo.topic", meta.Topic)
 	}
 }
+
+func TestNewBus_DriverInstances(t *testing.T) {
+	Register("recorder", func() Driver {
+		return &publishRecorderDriver{}
+	})
+	busA, busB := NewBus("recorder"), NewBus("recorder")
+	assert.NotNil(t, busA.driver)
+	assert.NotSame(t, busA.driver, busB.driver)
+	assert.Nil(t, NewBus("unknown").driver)
+	assert.Panics(t, func() {
+		Register("nil", nil)
+	})
+}
+
+type batchRecorderDriver struct {
+	publishRecorderDriver
+	batches [][]*TransportMessage
+}
+
+var _ BatchPublisher = &batchRecorderDriver{}
+
+func (d *batchRecorderDriver) PublishBatch(_ context.Context, messages []*TransportMessage) error {
+	d.batches = append(d.batches, messages)
+	return nil
+}
+
+func TestBus_PublishBulk(t *testing.T) {
+	driver := &batchRecorderDriver{}
+	bus := NewBus("local")
+	bus.driver = driver
+	bus.RegisterSchema(dummySchema{}, WithTopic("foo.topic"))
+
+	err := bus.PublishBulk(context.Background(), dummySchema{Foo: "a"}, struct{}{}, dummySchema{Foo: "b"})
+	assert.ErrorIs(t, err, ErrMessageNotRegistered)
+	assert.Empty(t, driver.published)
+	if assert.Len(t, driver.batches, 1) {
+		assert.Len(t, driver.batches[0], 2)
+	}
+
+	// middlewares wrap every driver publish call, so batches are split
+	intercepted := 0
+	bus = NewBus("local", WithPublisherMiddleware(func(next PublisherFunc) PublisherFunc {
+		return func(ctx context.Context, message *TransportMessage) error {
+			intercepted++
+			return next(ctx, message)
+		}
+	}))
+	driver = &batchRecorderDriver{}
+	bus.driver = driver
+	bus.RegisterSchema(dummySchema{}, WithTopic("foo.topic"))
+	assert.NoError(t, bus.PublishBulk(context.Background(), dummySchema{Foo: "a"}, dummySchema{Foo: "b"}))
+	assert.Equal(t, 2, intercepted)
+	assert.Len(t, driver.published, 2)
+	assert.Empty(t, driver.batches)
+
+	// drivers without batch support
+	recorder := &publishRecorderDriver{}
+	bus.driver = recorder
+	assert.NoError(t, bus.PublishBulk(context.Background(), dummySchema{Foo: "a"}, dummySchema{Foo: "b"}))
+	assert.Len(t, recorder.published, 2)
+}
@@ -0,0 +1,116 @@
+package gluon
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// ErrUnexpectedMessageType The in-transit message data type does not match the type expected by a typed subscriber.
+var ErrUnexpectedMessageType = errors.New("gluon: The in-transit message data type is not the expected one")
+
+// RegisterSchema Link the message schema T to specific metadata (MessageMetadata) and store it for Bus further
+// operations.
+//
+// Unlike Bus.RegisterSchema, it returns ErrMessageAlreadyRegistered if T was already registered. Both functions share
+// the same internal schema registry, so T may be used with reflection-based operations (e.g. Bus.Subscribe).
+func RegisterSchema[T any](b *Bus, opts ...SchemaRegistryOption) error {
+	return b.internalSchemaRegistry.registerType(schemaTypeOf[T](), newMessageMetadata(opts...))
+}
+
+// PublishTyped Propagate a message of schema T to the ecosystem using the internal topic registry agent to generate
+// the topic.
+//
+// Returns ErrMessageNotRegistered if T was not registered.
+//
+//	Note: To propagate correlation and causation IDs, use Subscription's context.
+func PublishTyped[T any](ctx context.Context, b *Bus, data T, opts ...PublishOption) error {
+	options := newPublishOptions(append([]PublishOption{WithPublishType(schemaTypeOf[T]().String())}, opts...))
+	msg, err := b.generatePublishMessage(data, options)
+	if err != nil {
+		return err
+	}
+	return b.publish(ctx, msg)
+}
+
+// SubscribeTo Set a subscription task for the message schema T.
+//
+// Unlike Bus.Subscribe, the subscription task is registered once a handler is set (TypedSubscriber.HandlerFunc or
+// TypedSubscriber.Handler), returning ErrMessageNotRegistered if T was not registered.
+func SubscribeTo[T any](b *Bus) *TypedSubscriber[T] {
+	meta, err := b.internalSchemaRegistry.getByKey(schemaTypeOf[T]().String())
+	sub := &TypedSubscriber[T]{
+		bus: b,
+		err: err,
+	}
+	if err == nil {
+		sub.entry = newSubscriber(meta.Topic)
+	} else {
+		sub.entry = newSubscriber("")
+	}
+	return sub
+}
+
+func schemaTypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// TypedSubscriber Is a Subscriber builder for a concrete message schema T.
+type TypedSubscriber[T any] struct {
+	bus   *Bus
+	entry *Subscriber
+	err   error
+}
+
+// Group Set a consumer group (if Driver allows them).
+func (s *TypedSubscriber[T]) Group(g string) *TypedSubscriber[T] {
+	s.entry.Group(g)
+	return s
+}
+
+// DriverConfiguration Set configuration for a specific driver.
+func (s *TypedSubscriber[T]) DriverConfiguration(cfg interface{}) *TypedSubscriber[T] {
+	s.entry.DriverConfiguration(cfg)
+	return s
+}
+
+// RetryPolicy Set a RetryPolicy for the Subscriber's handler.
+func (s *TypedSubscriber[T]) RetryPolicy(p RetryPolicy) *TypedSubscriber[T] {
+	s.entry.RetryPolicy(p)
+	return s
+}
+
+// DeadLetterPolicy Set a DeadLetterPolicy for the Subscriber's handler.
+func (s *TypedSubscriber[T]) DeadLetterPolicy(p DeadLetterPolicy) *TypedSubscriber[T] {
+	s.entry.DeadLetterPolicy(p)
+	return s
+}
+
+// Handler Set a TypedHandler component and register the subscription task.
+func (s *TypedSubscriber[T]) Handler(h TypedHandler[T]) error {
+	return s.HandlerFunc(h.Handle)
+}
+
+// HandlerFunc Set a TypedHandlerFunc component and register the subscription task.
+func (s *TypedSubscriber[T]) HandlerFunc(h TypedHandlerFunc[T]) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.entry.HandlerFunc(func(ctx context.Context, msg *Message) error {
+		data, ok := msg.Data.(T)
+		if !ok && msg.Data != nil {
+			return ErrUnexpectedMessageType
+		}
+		return h(ctx, &TypedMessage[T]{
+			Message: msg,
+			Data:    data,
+		})
+	})
+	s.bus.subscriberRegistry.register(s.entry.GetTopic(), s.entry)
+	return nil
+}
+
+// Subscriber Retrieve the underlying Subscriber.
+func (s *TypedSubscriber[T]) Subscriber() *Subscriber {
+	return s.entry
+}
@@ -0,0 +1,77 @@
+package gluon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedDummySchema struct {
+	Foo string
+}
+
+func TestRegisterSchema(t *testing.T) {
+	bus := NewBus("local")
+	assert.NoError(t, RegisterSchema[typedDummySchema](bus, WithTopic("foo.topic")))
+	assert.ErrorIs(t, RegisterSchema[typedDummySchema](bus, WithTopic("bar.topic")), ErrMessageAlreadyRegistered)
+
+	// keeps compatibility with reflection-based operations
+	meta, err := bus.GetSchemaMetadata(typedDummySchema{})
+	require.NoError(t, err)
+	assert.Equal(t, "foo.topic", meta.Topic)
+}
+
+func TestSubscribeTo(t *testing.T) {
+	bus := NewBus("local")
+	err := SubscribeTo[typedDummySchema](bus).
+		HandlerFunc(func(_ context.Context, _ *TypedMessage[typedDummySchema]) error {
+			return nil
+		})
+	assert.ErrorIs(t, err, ErrMessageNotRegistered)
+	assert.Len(t, bus.ListSubscribersFromTopic(""), 0)
+
+	require.NoError(t, RegisterSchema[typedDummySchema](bus, WithTopic("foo.topic")))
+	var got *TypedMessage[typedDummySchema]
+	err = SubscribeTo[typedDummySchema](bus).
+		Group("foo-service").
+		HandlerFunc(func(_ context.Context, msg *TypedMessage[typedDummySchema]) error {
+			got = msg
+			return nil
+		})
+	require.NoError(t, err)
+	subs := bus.ListSubscribersFromTopic("foo.topic")
+	require.Len(t, subs, 1)
+	assert.Equal(t, "foo-service", subs[0].GetGroup())
+
+	err = getInternalHandler(bus)(context.Background(), subs[0], &TransportMessage{
+		ID:   "123",
+		Data: []byte(`{"Foo":"bar"}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "bar", got.Data.Foo)
+	assert.Equal(t, "123", got.GetMessageID())
+
+	// messages without data (e.g. tombstones)
+	err = getInternalHandler(bus)(context.Background(), subs[0], &TransportMessage{
+		ID: "456",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, got.Message.Data)
+	assert.Equal(t, typedDummySchema{}, got.Data)
+	assert.Equal(t, "456", got.GetMessageID())
+}
+
+func TestPublishTyped(t *testing.T) {
+	driver := &publishRecorderDriver{}
+	bus := NewBus("local")
+	bus.driver = driver
+	assert.ErrorIs(t, PublishTyped(context.Background(), bus, typedDummySchema{Foo: "bar"}), ErrMessageNotRegistered)
+
+	require.NoError(t, RegisterSchema[typedDummySchema](bus, WithTopic("foo.topic")))
+	require.NoError(t, PublishTyped(context.Background(), bus, typedDummySchema{Foo: "bar"}))
+	require.Len(t, driver.published, 1)
+	assert.Equal(t, "foo.topic", driver.published[0].Topic)
+	assert.JSONEq(t, `{"Foo":"bar"}`, string(driver.published[0].Data))
+}
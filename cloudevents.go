@@ -0,0 +1,156 @@
+package gluon
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	json "github.com/json-iterator/go"
+)
+
+// ContentMode Is the CloudEvents content mode used by protocol bindings to transport messages.
+//
+// For more information, check https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/spec.md#message
+type ContentMode int
+
+const (
+	// ContentModeBinary Event data is carried by the message body while attributes are mapped to protocol metadata
+	// (e.g. Kafka headers, HTTP headers). This is the default content mode.
+	ContentModeBinary ContentMode = iota
+	// ContentModeStructured Both event data and attributes are encoded within the message body using the
+	// CloudEvents JSON format (application/cloudevents+json).
+	ContentModeStructured
+)
+
+// CloudEventsJSONContentType Is the media type of CloudEvents encoded using the JSON event format (structured mode).
+const CloudEventsJSONContentType = "application/cloudevents+json"
+
+// CloudEvents extension attributes used to transport `Gluon` custom fields.
+const (
+	// ExtensionCorrelationID Is the TransportMessage.CorrelationID attribute.
+	ExtensionCorrelationID = "correlationid"
+	// ExtensionCausationID Is the TransportMessage.CausationID attribute.
+	ExtensionCausationID = "causationid"
+	// ExtensionTraceParent Is the distributed tracing extension carrying the W3C traceparent.
+	//
+	// Protocol bindings carry distributed tracing attributes (ExtensionTraceParent and ExtensionTraceState) as
+	// headers without prefix, so they interoperate with other OpenTelemetry instrumentations.
+	ExtensionTraceParent = "traceparent"
+	// ExtensionTraceState Is the distributed tracing extension carrying the W3C tracestate.
+	ExtensionTraceState = "tracestate"
+)
+
+// ErrInvalidCloudEvent The given data is not a valid CloudEvent encoded using the JSON event format.
+var ErrInvalidCloudEvent = errors.New("gluon: Invalid CloudEvent JSON format")
+
+// IsJSONContentType Indicate if a media type is JSON-based (e.g. application/json, application/cloudevents+json,
+// text/json). Events without data content type are considered JSON-based as stated by the CloudEvents JSON format.
+func IsJSONContentType(contentType string) bool {
+	mediaType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	return mediaType == "" || mediaType == "application/json" || mediaType == "text/json" ||
+		strings.HasSuffix(mediaType, "+json")
+}
+
+// IsStructuredContentType Indicate if a media type belongs to a CloudEvent in structured mode.
+func IsStructuredContentType(contentType string) bool {
+	return strings.HasPrefix(strings.TrimSpace(contentType), "application/cloudevents")
+}
+
+// MarshalStructuredMessage Encode a message using the CloudEvents JSON event format.
+//
+// JSON data is embedded into the `data` member while any other data is encoded into the `data_base64` member. Extensions,
+// correlation, causation and trace context are encoded as extension attributes.
+func MarshalStructuredMessage(msg *TransportMessage) ([]byte, error) {
+	event := make(map[string]interface{}, 12+len(msg.Extensions))
+	for k, v := range msg.Extensions {
+		event[k] = v
+	}
+	for k, v := range msg.TraceContext {
+		event[k] = v
+	}
+	setStructuredAttribute(event, ExtensionCorrelationID, msg.CorrelationID)
+	setStructuredAttribute(event, ExtensionCausationID, msg.CausationID)
+	event["specversion"] = msg.SpecVersion
+	event["id"] = msg.ID
+	event["source"] = msg.Source
+	event["type"] = msg.Type
+	setStructuredAttribute(event, "datacontenttype", msg.DataContentType)
+	setStructuredAttribute(event, "dataschema", msg.DataSchema)
+	setStructuredAttribute(event, "subject", msg.Subject)
+	setStructuredAttribute(event, "time", msg.Time)
+	if len(msg.Data) > 0 {
+		if IsJSONContentType(msg.DataContentType) && json.Valid(msg.Data) {
+			event["data"] = json.RawMessage(msg.Data)
+		} else {
+			event["data_base64"] = base64.StdEncoding.EncodeToString(msg.Data)
+		}
+	}
+	return json.Marshal(event)
+}
+
+func setStructuredAttribute(event map[string]interface{}, key, value string) {
+	if value != "" {
+		event[key] = value
+	}
+}
+
+// UnmarshalStructuredMessage Decode a message encoded using the CloudEvents JSON event format.
+func UnmarshalStructuredMessage(data []byte, msg *TransportMessage) error {
+	event := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return ErrInvalidCloudEvent
+	}
+	for k, raw := range event {
+		switch k {
+		case "data", "data_base64":
+			continue // decoded once datacontenttype is known
+		case "specversion":
+			msg.SpecVersion = decodeStructuredAttribute(raw)
+		case "id":
+			msg.ID = decodeStructuredAttribute(raw)
+		case "source":
+			msg.Source = decodeStructuredAttribute(raw)
+		case "type":
+			msg.Type = decodeStructuredAttribute(raw)
+		case "datacontenttype":
+			msg.DataContentType = decodeStructuredAttribute(raw)
+		case "dataschema":
+			msg.DataSchema = decodeStructuredAttribute(raw)
+		case "subject":
+			msg.Subject = decodeStructuredAttribute(raw)
+		case "time":
+			msg.Time = decodeStructuredAttribute(raw)
+		default:
+			msg.SetAttribute(k, decodeStructuredAttribute(raw))
+		}
+	}
+	if msg.ID == "" || msg.Type == "" || msg.SpecVersion == "" {
+		return ErrInvalidCloudEvent
+	}
+
+	if raw, ok := event["data_base64"]; ok {
+		decoded, err := base64.StdEncoding.DecodeString(decodeStructuredAttribute(raw))
+		if err != nil {
+			return ErrInvalidCloudEvent
+		}
+		msg.Data = decoded
+	} else if raw, ok = event["data"]; ok {
+		if IsJSONContentType(msg.DataContentType) {
+			msg.Data = raw
+		} else {
+			// non-JSON data (e.g. text/plain) is encoded as a JSON string
+			msg.Data = []byte(decodeStructuredAttribute(raw))
+		}
+	}
+	return nil
+}
+
+// decodeStructuredAttribute Decode an attribute value. Non-string values (e.g. integers, booleans) are kept using
+// their JSON representation.
+func decodeStructuredAttribute(raw json.RawMessage) string {
+	var v string
+	if err := json.Unmarshal(raw, &v); err == nil {
+		return v
+	}
+	return string(raw)
+}
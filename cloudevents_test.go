@@ -0,0 +1,111 @@
+package gluon
+
+import (
+	"encoding/json"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCloudEvent(contentType string, data []byte) *TransportMessage {
+	return &TransportMessage{
+		ID:              "123",
+		Source:          "https://api.neutrino.org/marketplace/items",
+		SpecVersion:     CloudEventsSpecVersion,
+		Type:            "org.neutrino.marketplace.item.paid",
+		Data:            data,
+		DataContentType: contentType,
+		DataSchema:      "https://schemas.neutrino.org/item_paid.json",
+		Subject:         "abc",
+		Time:            "2021-01-01T00:00:00Z",
+		CorrelationID:   "456",
+		CausationID:     "789",
+		TraceContext: map[string]string{
+			ExtensionTraceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		},
+		Extensions: map[string]string{
+			"tenantid": "neutrino",
+		},
+	}
+}
+
+var structuredMessageTestCases = []struct {
+	Name        string
+	ContentType string
+	Data        []byte
+	WantBase64  bool
+}{
+	{
+		Name:        "json data",
+		ContentType: "application/json",
+		Data:        []byte(`{"item_id":"abc"}`),
+	},
+	{
+		Name:        "binary data",
+		ContentType: "application/avro",
+		Data:        []byte{0x00, 0x01, 0xff},
+		WantBase64:  true,
+	},
+	{
+		Name:        "text data",
+		ContentType: "text/plain",
+		Data:        []byte("hello"),
+		WantBase64:  true,
+	},
+	{
+		Name:        "no data",
+		ContentType: "application/json",
+	},
+}
+
+func TestStructuredMessage(t *testing.T) {
+	for _, tt := range structuredMessageTestCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			msg := newTestCloudEvent(tt.ContentType, tt.Data)
+			data, err := MarshalStructuredMessage(msg)
+			require.NoError(t, err)
+			members := map[string]json.RawMessage{}
+			require.NoError(t, json.Unmarshal(data, &members))
+			_, hasBase64 := members["data_base64"]
+			assert.Equal(t, tt.WantBase64, hasBase64)
+
+			got := new(TransportMessage)
+			require.NoError(t, UnmarshalStructuredMessage(data, got))
+			assert.Equal(t, msg, got)
+
+			// interoperability with the CloudEvents SDK
+			event := cloudevents.New()
+			require.NoError(t, event.UnmarshalJSON(data))
+			require.NoError(t, event.Validate())
+			assert.Equal(t, msg.ID, event.ID())
+			assert.Equal(t, msg.DataContentType, event.DataContentType())
+			assert.Equal(t, []byte(msg.Data), event.Data())
+			assert.Equal(t, "456", event.Extensions()[ExtensionCorrelationID])
+		})
+	}
+}
+
+func TestUnmarshalStructuredMessage_SDK(t *testing.T) {
+	event := cloudevents.New()
+	event.SetID("123")
+	event.SetSource("https://api.neutrino.org/marketplace/items")
+	event.SetType("org.neutrino.marketplace.item.paid")
+	event.SetExtension("tenantid", "neutrino")
+	event.SetExtension("priority", 5)
+	require.NoError(t, event.SetData("text/plain", "hello"))
+	data, err := event.MarshalJSON()
+	require.NoError(t, err)
+
+	got := new(TransportMessage)
+	require.NoError(t, UnmarshalStructuredMessage(data, got))
+	assert.Equal(t, "123", got.ID)
+	assert.Equal(t, CloudEventsSpecVersion, got.SpecVersion)
+	assert.Equal(t, "text/plain", got.DataContentType)
+	assert.Equal(t, []byte("hello"), got.Data)
+	assert.Equal(t, map[string]string{"tenantid": "neutrino", "priority": "5"}, got.Extensions)
+
+	assert.ErrorIs(t, UnmarshalStructuredMessage([]byte(`{"id":"123"}`), new(TransportMessage)),
+		ErrInvalidCloudEvent)
+}
@@ -1,8 +1,20 @@
 package gluon
 
+import "context"
+
 type gluonContextKey string
 
 const (
 	contextCorrelationID gluonContextKey = "gluon-correlation-id"
 	contextMessageID     gluonContextKey = "gluon-message-id"
+	contextDriver        gluonContextKey = "gluon-driver"
+	contextReplyTo       gluonContextKey = "gluon-reply-to"
 )
+
+// DriverFromContext Retrieve the name of the driver (e.g. kafka, aws_sns_sqs) handling a message.
+//
+// Available on both consumer and publisher middleware contexts.
+func DriverFromContext(ctx context.Context) string {
+	driver, _ := ctx.Value(contextDriver).(gluonContextKey)
+	return string(driver)
+}
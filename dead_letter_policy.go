@@ -0,0 +1,114 @@
+package gluon
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/neutrinocorp/gluon/gutil"
+)
+
+// CloudEvents extension attributes attached to messages routed by a DeadLetterPolicy.
+const (
+	// ExtensionFailureReason Is the error description which made the message fail.
+	ExtensionFailureReason = "failurereason"
+	// ExtensionFailedAttempts Is the total number of handler executions before the message was routed.
+	ExtensionFailedAttempts = "failedattempts"
+	// ExtensionOriginalTopic Is the topic the message was consumed from.
+	ExtensionOriginalTopic = "originaltopic"
+	// ExtensionConsumerGroup Is the consumer group which failed to process the message.
+	ExtensionConsumerGroup = "consumergroup"
+)
+
+// ErrMaxDeliveriesExceeded A message reached the maximum number of deliveries allowed by a driver.
+var ErrMaxDeliveriesExceeded = errors.New("gluon: The message reached the maximum number of deliveries")
+
+// DeadLetterPolicy Is a set of rules used by `Gluon` internals to route messages which could not be processed.
+//
+// A message is routed when it cannot be decoded or when its handler fails after every attempt of the RetryPolicy.
+// The original TransportMessage is republished to a derived topic following gutil.GenerateTopicName conventions
+// (e.g. org.neutrino.iam.event.user.signed_up.dlq) along with failure metadata as CloudEvents extension attributes
+// (ExtensionFailureReason, ExtensionFailedAttempts, ExtensionOriginalTopic and ExtensionConsumerGroup).
+//
+// Once a message is routed, it is acknowledged to the driver.
+//
+// A DeadLetterPolicy may be set globally using WithDeadLetterPolicy or per-subscriber using
+// Subscriber.DeadLetterPolicy.
+type DeadLetterPolicy struct {
+	// UseRetryTopic Route messages which failed because of a retryable error to the `.retry` topic instead of the
+	// `.dlq` topic. Decoding failures, non-retryable errors and failures of `.retry` topic subscribers are always
+	// routed to the `.dlq` topic.
+	UseRetryTopic bool
+}
+
+// getDeadLetterPolicy Retrieve the dead-letter policy of a Subscriber, falling back to the Bus global dead-letter policy.
+func getDeadLetterPolicy(b *Bus, sub *Subscriber) *DeadLetterPolicy {
+	if p := sub.GetDeadLetterPolicy(); p != nil {
+		return p
+	}
+	return b.Configuration.DeadLetterPolicy
+}
+
+// routeDeadLetter Republish a failed message to its dead-letter (or retry) topic if the Subscriber has a
+// DeadLetterPolicy. Returns nil if the message was routed successfully.
+func routeDeadLetter(ctx context.Context, b *Bus, sub *Subscriber, msg *TransportMessage, attempts int,
+	retryable bool, handlerErr error) error {
+	policy := getDeadLetterPolicy(b, sub)
+	if policy == nil || strings.HasSuffix(sub.GetTopic(), gutil.DLQTopicSuffix) {
+		// avoid routing messages from a dead-letter topic to another dead-letter topic
+		return handlerErr
+	}
+
+	topic := gutil.GenerateDLQTopicName(sub.GetTopic())
+	if retryable && policy.UseRetryTopic && !strings.HasSuffix(sub.GetTopic(), gutil.RetryTopicSuffix) {
+		// messages failing on a retry topic are not retried again, otherwise they would cycle forever
+		topic = gutil.GenerateRetryTopicName(sub.GetTopic())
+	}
+	deadLetter := *msg
+	deadLetter.Topic = topic
+	deadLetter.DriverHeaders = nil
+	deadLetter.Extensions = make(map[string]string, len(msg.Extensions)+4)
+	for k, v := range msg.Extensions {
+		deadLetter.Extensions[k] = v
+	}
+	deadLetter.Extensions[ExtensionFailureReason] = handlerErr.Error()
+	deadLetter.Extensions[ExtensionFailedAttempts] = strconv.Itoa(attempts)
+	if _, ok := msg.Extensions[ExtensionOriginalTopic]; !ok {
+		// keeps the topic of the first failure when routing from a retry topic
+		deadLetter.Extensions[ExtensionOriginalTopic] = sub.GetTopic()
+	}
+	deadLetter.Extensions[ExtensionConsumerGroup] = getConsumerGroup(b, sub)
+
+	if err := b.publishTransportMessage(ctx, &deadLetter); err != nil {
+		return multierror.Append(handlerErr, err)
+	}
+	logInternalError(b, NewError("MessageRoutedToDeadLetter",
+		"Message ("+msg.ID+") was routed to topic ("+topic+")", handlerErr))
+	return nil
+}
+
+// RouteDeadLetter Republish a message which a driver stopped delivering (e.g. it reached a maximum number of
+// deliveries) following the DeadLetterPolicy of the Subscriber, using ErrMaxDeliveriesExceeded as failure reason.
+//
+// Returns false if the Subscriber has no DeadLetterPolicy (or consumes a dead-letter topic), so drivers may discard
+// the message instead.
+func (b *Bus) RouteDeadLetter(ctx context.Context, sub *Subscriber, msg *TransportMessage,
+	deliveries int) (bool, error) {
+	if getDeadLetterPolicy(b, sub) == nil || strings.HasSuffix(sub.GetTopic(), gutil.DLQTopicSuffix) {
+		return false, nil
+	}
+	if err := routeDeadLetter(ctx, b, sub, msg, deliveries, false, ErrMaxDeliveriesExceeded); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isRetryableError Indicate if a handler error would be retried by the Subscriber's retry policy.
+func isRetryableError(b *Bus, sub *Subscriber, err error) bool {
+	if policy := getRetryPolicy(b, sub); policy != nil {
+		return policy.ShouldRetry(err)
+	}
+	return RetryPolicy{}.ShouldRetry(err)
+}
@@ -0,0 +1,180 @@
+package gluon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type publishRecorderDriver struct {
+	mu        sync.Mutex
+	published []*TransportMessage
+	err       error
+}
+
+var _ Driver = &publishRecorderDriver{}
+
+func (d *publishRecorderDriver) SetParentBus(_ *Bus) {}
+
+func (d *publishRecorderDriver) SetInternalHandler(_ InternalMessageHandler) {}
+
+func (d *publishRecorderDriver) Start(_ context.Context) error {
+	return nil
+}
+
+func (d *publishRecorderDriver) Shutdown(_ context.Context) error {
+	return nil
+}
+
+func (d *publishRecorderDriver) Subscribe(_ context.Context, _ *Subscriber) error {
+	return nil
+}
+
+func (d *publishRecorderDriver) Publish(_ context.Context, message *TransportMessage) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return d.err
+	}
+	d.published = append(d.published, message)
+	return nil
+}
+
+var deadLetterPolicyTestCases = []struct {
+	Name      string
+	Policy    *DeadLetterPolicy
+	Data      []byte
+	DriverErr error
+	WantTopic string
+	WantErr   bool
+}{
+	{
+		Name:    "no policy",
+		Data:    []byte(`{"Foo":"bar"}`),
+		WantErr: true,
+	},
+	{
+		Name:      "exhausted retries",
+		Policy:    &DeadLetterPolicy{},
+		Data:      []byte(`{"Foo":"bar"}`),
+		WantTopic: "foo.topic.dlq",
+	},
+	{
+		Name:      "exhausted retries using retry topic",
+		Policy:    &DeadLetterPolicy{UseRetryTopic: true},
+		Data:      []byte(`{"Foo":"bar"}`),
+		WantTopic: "foo.topic.retry",
+	},
+	{
+		Name:      "decoding failure",
+		Policy:    &DeadLetterPolicy{UseRetryTopic: true},
+		Data:      []byte(`{"Foo":`),
+		WantTopic: "foo.topic.dlq",
+	},
+	{
+		Name:      "failed routing",
+		Policy:    &DeadLetterPolicy{},
+		Data:      []byte(`{"Foo":"bar"}`),
+		DriverErr: errors.New("driver error"),
+		WantErr:   true,
+	},
+}
+
+func TestDeadLetterPolicy_InternalHandler(t *testing.T) {
+	for _, tt := range deadLetterPolicyTestCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			driver := &publishRecorderDriver{err: tt.DriverErr}
+			bus := NewBus("local",
+				WithConsumerGroup("foo-service"),
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+			bus.driver = driver
+			bus.RegisterSchema(dummySchema{}, WithTopic("foo.topic"))
+			sub := bus.Subscribe(dummySchema{}).
+				HandlerFunc(func(_ context.Context, _ *Message) error {
+					return errRetryDummy
+				})
+			if tt.Policy != nil {
+				sub.DeadLetterPolicy(*tt.Policy)
+			}
+
+			err := getInternalHandler(bus)(context.Background(), sub, &TransportMessage{
+				ID:            "123",
+				Type:          "foo.topic",
+				Topic:         "foo.topic",
+				CorrelationID: "abc",
+				Data:          tt.Data,
+			})
+			if tt.WantErr {
+				assert.Error(t, err)
+				assert.Len(t, driver.published, 0)
+				return
+			}
+			assert.NoError(t, err)
+			if assert.Len(t, driver.published, 1) {
+				deadLetter := driver.published[0]
+				assert.Equal(t, tt.WantTopic, deadLetter.Topic)
+				assert.Equal(t, "123", deadLetter.ID)
+				assert.Equal(t, "abc", deadLetter.CorrelationID)
+				assert.Equal(t, "foo.topic", deadLetter.Extensions[ExtensionOriginalTopic])
+				assert.Equal(t, "foo-service", deadLetter.Extensions[ExtensionConsumerGroup])
+				assert.NotEmpty(t, deadLetter.Extensions[ExtensionFailureReason])
+				assert.NotEmpty(t, deadLetter.Extensions[ExtensionFailedAttempts])
+			}
+		})
+	}
+}
+
+func TestDeadLetterPolicy_InternalHandlerRetryTopic(t *testing.T) {
+	driver := &publishRecorderDriver{}
+	bus := NewBus("local",
+		WithConsumerGroup("foo-service"),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithDeadLetterPolicy(DeadLetterPolicy{UseRetryTopic: true}))
+	bus.driver = driver
+	bus.RegisterSchema(dummySchema{}, WithTopic("foo.topic"))
+	sub := bus.SubscribeTopic("foo.topic.retry").
+		HandlerFunc(func(_ context.Context, _ *Message) error {
+			return errRetryDummy
+		})
+
+	err := getInternalHandler(bus)(context.Background(), sub, &TransportMessage{
+		ID:         "123",
+		Type:       "foo.topic",
+		Topic:      "foo.topic.retry",
+		Data:       []byte(`{"Foo":"bar"}`),
+		Extensions: map[string]string{ExtensionOriginalTopic: "foo.topic"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, driver.published, 1) {
+		assert.Equal(t, "foo.topic.dlq", driver.published[0].Topic)
+		assert.Equal(t, errRetryDummy.Error(), driver.published[0].Extensions[ExtensionFailureReason])
+		assert.Equal(t, "foo.topic", driver.published[0].Extensions[ExtensionOriginalTopic])
+	}
+}
+
+func TestBus_RouteDeadLetter(t *testing.T) {
+	driver := &publishRecorderDriver{}
+	bus := NewBus("local", WithConsumerGroup("foo-service"))
+	bus.driver = driver
+	sub := bus.SubscribeTopic("foo.topic")
+	msg := &TransportMessage{ID: "123", Type: "foo.topic", Topic: "foo.topic"}
+
+	routed, err := bus.RouteDeadLetter(context.Background(), sub, msg, 5)
+	assert.NoError(t, err)
+	assert.False(t, routed)
+	assert.Len(t, driver.published, 0)
+
+	sub.DeadLetterPolicy(DeadLetterPolicy{UseRetryTopic: true})
+	routed, err = bus.RouteDeadLetter(context.Background(), sub, msg, 5)
+	assert.NoError(t, err)
+	assert.True(t, routed)
+	if assert.Len(t, driver.published, 1) {
+		assert.Equal(t, "foo.topic.dlq", driver.published[0].Topic)
+		assert.Equal(t, "5", driver.published[0].Extensions[ExtensionFailedAttempts])
+		assert.Equal(t, ErrMaxDeliveriesExceeded.Error(), driver.published[0].Extensions[ExtensionFailureReason])
+	}
+}
@@ -17,19 +17,49 @@ type Driver interface {
 	Publish(ctx context.Context, message *TransportMessage) error
 }
 
+// BatchPublisher Is an optional Driver interface used to propagate multiple low-level messages within a single
+// operation (e.g. Bus.PublishBulk).
+type BatchPublisher interface {
+	// PublishBatch Propagate a batch of low-level messages (CloudEvents) to the message bus.
+	PublishBatch(ctx context.Context, messages []*TransportMessage) error
+}
+
+// Provisioner Is an optional Driver interface used to create the infrastructure required by a Bus (e.g. topics,
+// queues) before it starts. Bus.ListenAndServe calls Provision before Driver.Start.
+//
+// Implementations should provision only if enabled by the driver configuration and use Bus.Topics to discover the
+// required topics.
+type Provisioner interface {
+	Provision(ctx context.Context) error
+}
+
+// DriverFactory Allocates a new Driver instance. Every Bus gets its own Driver instance, so multiple Bus instances
+// using the same driver may coexist within a process.
+type DriverFactory func() Driver
+
 var (
 	driversMu sync.RWMutex
-	drivers   = make(map[string]Driver)
+	drivers   = make(map[string]DriverFactory)
 )
 
 // Register makes a message broker driver available for the Bus.
 //
-// If Register is called with a driver equals to nil, it panics.
-func Register(name string, driver Driver) {
+// If Register is called with a factory equals to nil, it panics.
+func Register(name string, factory DriverFactory) {
 	driversMu.Lock()
 	defer driversMu.Unlock()
-	if driver == nil {
-		panic("gluon: Given driver is nil")
+	if factory == nil {
+		panic("gluon: Given driver factory is nil")
+	}
+	drivers[name] = factory
+}
+
+// newDriver Allocate a new instance of a registered driver. Returns nil if the driver was not registered.
+func newDriver(name string) Driver {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	if factory, ok := drivers[name]; ok {
+		return factory()
 	}
-	drivers[name] = driver
+	return nil
 }
@@ -0,0 +1,26 @@
+package gluon
+
+// DriverHooks Is a set of callbacks used by drivers to report internal operations (e.g. consumer lag) to
+// observability components.
+//
+// Every callback is optional.
+type DriverHooks struct {
+	// OnConsumerLag Reports the number of messages a consumer is behind the latest message of a topic partition.
+	OnConsumerLag func(driver, topic, group string, partition int32, lag int64)
+	// OnMessagesPolled Reports the number of messages received by a single polling operation.
+	OnMessagesPolled func(driver, topic, group string, total int)
+}
+
+// ReportConsumerLag Execute the OnConsumerLag callback, if any.
+func (h DriverHooks) ReportConsumerLag(driver, topic, group string, partition int32, lag int64) {
+	if h.OnConsumerLag != nil {
+		h.OnConsumerLag(driver, topic, group, partition, lag)
+	}
+}
+
+// ReportMessagesPolled Execute the OnMessagesPolled callback, if any.
+func (h DriverHooks) ReportMessagesPolled(driver, topic, group string, total int) {
+	if h.OnMessagesPolled != nil {
+		h.OnMessagesPolled(driver, topic, group, total)
+	}
+}
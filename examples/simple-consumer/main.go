@@ -97,7 +97,7 @@ func registerSubscribers(bus *gluon.Bus) {
 			event.SentAt.Format(time.RFC3339))
 		log.Printf("[WAREHOUSE_SERVICE] | Message metadata: id: %s, correlation: %s, causation: %s",
 			message.GetMessageID(), message.GetCorrelationID(), message.GetCausationID())
-		return bus.PublishWithTopic(ctx, "org.neutrino.warehouse.order.delivered", OrderDelivered{
+		return bus.Publish(ctx, OrderDelivered{
 			OrderID:     uuid.NewString(),
 			DeliveredAt: time.Now().UTC(),
 		})
@@ -115,7 +115,7 @@ func registerSubscribers(bus *gluon.Bus) {
 
 func publishMessage(bus *gluon.Bus) {
 	rootCtx := context.TODO()
-	err := bus.PublishWithTopic(rootCtx, "org.neutrino.marketplace.item.paid", ItemPaid{
+	err := bus.Publish(rootCtx, ItemPaid{
 		ItemID:   uuid.NewString(),
 		Total:    99.99,
 		Quantity: 2,
@@ -131,10 +131,10 @@ func registerSubscribers(bus *gluon.Bus) {
 				event.PaidAt.Format(time.RFC3339), event.Total)
 			log.Printf("[WAREHOUSE_SERVICE] | Message metadata: id: %s, correlation: %s, causation: %s",
 				message.GetMessageID(), message.GetCorrelationID(), message.GetCausationID())
-			return bus.PublishWithSubject(ctx, OrderSent{
+			return bus.Publish(ctx, OrderSent{
 				OrderID: uuid.NewString(),
 				SentAt:  time.Now().UTC(),
-			}, event.ItemID)
+			}, gluon.WithSubject(event.ItemID))
 		})
 
 	bus.Subscribe(OrderSent{}).
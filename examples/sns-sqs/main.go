@@ -80,12 +80,12 @@ func publishMessage(bus *gluon.Bus) {
 	time.Sleep(time.Second * 5) // wait for cold boot
 	rootCtx := context.TODO()
 	itemId := uuid.NewString()
-	err := bus.PublishWithSubject(rootCtx, ItemPaid{
+	err := bus.Publish(rootCtx, ItemPaid{
 		ItemID:   itemId,
 		Total:    99.99,
 		Quantity: 2,
 		PaidAt:   time.Now().UTC(),
-	}, itemId)
+	}, gluon.WithSubject(itemId))
 	if err != nil {
 		log.Error().Msg(err.Error())
 	}
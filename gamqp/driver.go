@@ -0,0 +1,288 @@
+package gamqp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DriverName Is the name used to register the AMQP 0-9-1 driver.
+const DriverName = "amqp"
+
+// ErrNotConnected The driver lost its connection to the AMQP server and is reconnecting.
+var ErrNotConnected = errors.New("gluon: Not connected to the AMQP server")
+
+// driver Is the AMQP 0-9-1 (e.g. RabbitMQ) driver.
+//
+// Messages are published to a topic exchange using their topic as routing key. Every consumer group declares a
+// durable queue per binding key, so subscribers sharing a consumer group compete for messages while every consumer
+// group gets every message (fan-out). Subscribers without consumer group get an exclusive, auto-deleted queue.
+//
+// If the connection is lost, the driver reconnects and subscribes again.
+type driver struct {
+	parentBus      *gluon.Bus
+	messageHandler gluon.InternalMessageHandler
+	config         Configuration
+
+	mu          sync.Mutex
+	conn        *amqp.Connection
+	pubChannel  *amqp.Channel
+	closeNotify chan *amqp.Error
+	subscribers []*gluon.Subscriber
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+var _ gluon.Driver = &driver{}
+
+func init() {
+	gluon.Register(DriverName, func() gluon.Driver {
+		return &driver{
+			ctx: context.Background(),
+		}
+	})
+}
+
+func (d *driver) SetParentBus(b *gluon.Bus) {
+	d.parentBus = b
+	if cfg, ok := b.Configuration.Driver.(Configuration); ok {
+		d.config = cfg
+	}
+}
+
+func (d *driver) SetInternalHandler(h gluon.InternalMessageHandler) {
+	d.messageHandler = h
+}
+
+func (d *driver) Start(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ctx, d.cancel = context.WithCancel(ctx)
+	if d.conn == nil {
+		if err := d.connect(); err != nil {
+			return err
+		}
+	}
+	d.wg.Add(1)
+	go d.watchConnection()
+	return nil
+}
+
+func (d *driver) Shutdown(_ context.Context) error {
+	d.mu.Lock()
+	if d.cancel != nil {
+		d.cancel()
+	}
+	var err error
+	if d.conn != nil {
+		err = d.conn.Close()
+		d.conn, d.pubChannel = nil, nil
+	}
+	d.mu.Unlock()
+	d.wg.Wait()
+	if errors.Is(err, amqp.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func (d *driver) Publish(ctx context.Context, message *gluon.TransportMessage) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn == nil && d.cancel == nil {
+		// driver was not started yet
+		if err := d.connect(); err != nil {
+			return err
+		}
+	}
+	if d.pubChannel == nil {
+		return ErrNotConnected
+	}
+	err := d.pubChannel.PublishWithContext(ctx, d.config.GetExchange(), message.Topic, false, false,
+		marshalAmqpMessage(message))
+	if err != nil {
+		return gluon.NewError("AmqpFailedPublishing", "Failed to publish to exchange ("+
+			d.config.GetExchange()+") using routing key ("+message.Topic+")", err)
+	}
+	return nil
+}
+
+func (d *driver) Subscribe(_ context.Context, subscriber *gluon.Subscriber) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscribers = append(d.subscribers, subscriber)
+	if d.conn == nil {
+		// consumer will start once connected
+		return nil
+	}
+	return d.consume(subscriber)
+}
+
+func (d *driver) getURL() string {
+	if d.config.URL != "" {
+		return d.config.URL
+	} else if d.parentBus != nil && len(d.parentBus.Addresses) > 0 {
+		return d.parentBus.Addresses[0]
+	}
+	return defaultURL
+}
+
+// connect Dial the AMQP server, declare the exchange and start consumers of every subscriber. Mutex must be locked.
+func (d *driver) connect() (err error) {
+	var conn *amqp.Connection
+	if d.config.AmqpConfig != nil {
+		conn, err = amqp.DialConfig(d.getURL(), *d.config.AmqpConfig)
+	} else {
+		conn, err = amqp.Dial(d.getURL())
+	}
+	if err != nil {
+		return gluon.NewError("AmqpFailedConnecting", "Failed to connect to server", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = conn.Close()
+		}
+	}()
+
+	pubChannel, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	err = pubChannel.ExchangeDeclare(d.config.GetExchange(), amqp.ExchangeTopic, true, false, false, false, nil)
+	if err != nil {
+		return gluon.NewError("AmqpFailedProvisioning", "Failed to declare exchange ("+
+			d.config.GetExchange()+")", err)
+	}
+	d.conn, d.pubChannel = conn, pubChannel
+	d.closeNotify = conn.NotifyClose(make(chan *amqp.Error, 1))
+	for _, sub := range d.subscribers {
+		if err = d.consume(sub); err != nil {
+			d.conn, d.pubChannel = nil, nil
+			return err
+		}
+	}
+	return nil
+}
+
+// watchConnection Reconnect to the AMQP server after a connection loss until the driver is shut down.
+func (d *driver) watchConnection() {
+	defer d.wg.Done()
+	for {
+		d.mu.Lock()
+		closeNotify := d.closeNotify
+		d.mu.Unlock()
+		select {
+		case <-d.ctx.Done():
+			return
+		case errConn, ok := <-closeNotify:
+			if !ok || errConn == nil {
+				// graceful close
+				return
+			}
+			d.parentBus.LogError(gluon.NewError("AmqpConnectionLost", "Lost connection to server, reconnecting", errConn))
+		}
+
+		d.mu.Lock()
+		d.conn, d.pubChannel = nil, nil
+		d.mu.Unlock()
+		for !d.reconnect() {
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-time.After(d.config.GetReconnectDelay()):
+			}
+		}
+	}
+}
+
+func (d *driver) reconnect() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ctx.Err() != nil {
+		return true
+	}
+	err := d.connect()
+	d.parentBus.LogError(err)
+	return err == nil
+}
+
+// consume Declare and bind the queue of a subscriber, then start delivering its messages. Mutex must be locked.
+func (d *driver) consume(sub *gluon.Subscriber) error {
+	ch, err := d.conn.Channel()
+	if err != nil {
+		return err
+	}
+	cfg := getConsumerConfiguration(sub)
+	if err = ch.Qos(cfg.GetPrefetchCount(), cfg.PrefetchSize, false); err != nil {
+		return err
+	}
+
+	group := sub.GetGroup()
+	if group == "" {
+		group = d.parentBus.Configuration.ConsumerGroup
+	}
+	bindingKey := getBindingKey(sub)
+	var queue amqp.Queue
+	if group == "" {
+		queue, err = ch.QueueDeclare("", false, true, true, false, nil)
+	} else {
+		queue, err = ch.QueueDeclare(generateQueueName(group, bindingKey), true, false, false, false,
+			d.config.QueueArguments)
+	}
+	if err == nil {
+		err = ch.QueueBind(queue.Name, bindingKey, d.config.GetExchange(), false, nil)
+	}
+	if err != nil {
+		return gluon.NewError("AmqpFailedProvisioning", "Failed to declare queue for binding key ("+
+			bindingKey+")", err)
+	}
+
+	deliveries, err := ch.Consume(queue.Name, "", false, false, false, false, nil)
+	if err != nil {
+		return gluon.NewError("AmqpFailedSubscribing", "Failed to consume from queue ("+queue.Name+")", err)
+	}
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		// deliveries is closed when the channel or connection is closed
+		for delivery := range deliveries {
+			d.handleDelivery(sub, &delivery)
+		}
+	}()
+	return nil
+}
+
+func (d *driver) handleDelivery(sub *gluon.Subscriber, delivery *amqp.Delivery) {
+	if err := d.messageHandler(d.ctx, sub, unmarshalAmqpMessage(delivery)); err != nil {
+		d.parentBus.LogError(gluon.NewError("AmqpHandlerFailed", "Failed to handle the message with routing key ("+
+			delivery.RoutingKey+")", err))
+		d.nack(delivery)
+		return
+	}
+	if err := delivery.Ack(false); err != nil {
+		d.parentBus.LogError(gluon.NewError("AmqpFailedToAcknowledge", "Failed to acknowledge message with routing key ("+
+			delivery.RoutingKey+")", err))
+	}
+}
+
+// nack Requeue a failed message after Configuration.RedeliveryDelay, or reject it if it reached
+// Configuration.MaxDeliveries. The message is kept unacknowledged while waiting, so other messages are still
+// delivered to the consumer.
+func (d *driver) nack(delivery *amqp.Delivery) {
+	if isDeliveryLimitReached(delivery, d.config.GetMaxDeliveries()) {
+		d.parentBus.LogError(gluon.NewError("AmqpMessageRejected", "Message with routing key ("+
+			delivery.RoutingKey+") reached maximum number of deliveries", nil))
+		d.parentBus.LogError(delivery.Nack(false, false))
+		return
+	}
+	d.wg.Add(1)
+	time.AfterFunc(d.config.GetRedeliveryDelay(), func() {
+		defer d.wg.Done()
+		d.parentBus.LogError(delivery.Nack(false, true))
+	})
+}
@@ -0,0 +1,21 @@
+package gamqp
+
+// AMQP custom gluon headers
+const (
+	HeaderDeliveryTag = "amqp-delivery-tag"
+	HeaderRedelivered = "amqp-redelivered"
+	HeaderRoutingKey  = "amqp-routing-key"
+
+	// Internal AMQP message headers, following the CloudEvents AMQP protocol binding
+
+	headerMessageID   = "cloudEvents:id"
+	headerSource      = "cloudEvents:source"
+	headerSpecVersion = "cloudEvents:specversion"
+	headerMessageType = "cloudEvents:type"
+	headerMessageTime = "cloudEvents:time"
+	headerSchema      = "cloudEvents:dataschema"
+	headerSubject     = "cloudEvents:subject"
+	// headerExtensionPrefix is used to carry CloudEvents extension attributes (e.g. cloudEvents:tenantid,
+	// cloudEvents:correlationid)
+	headerExtensionPrefix = "cloudEvents:"
+)
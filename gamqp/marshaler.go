@@ -0,0 +1,93 @@
+package gamqp
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func marshalAmqpMessage(msg *gluon.TransportMessage) amqp.Publishing {
+	ts, _ := time.Parse(time.RFC3339, msg.Time)
+	return amqp.Publishing{
+		Headers:      marshalAmqpHeaders(msg),
+		ContentType:  msg.DataContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    msg.ID,
+		Timestamp:    ts,
+		Type:         msg.Type,
+		Body:         msg.Data,
+	}
+}
+
+func marshalAmqpHeaders(msg *gluon.TransportMessage) amqp.Table {
+	headers := amqp.Table{
+		headerMessageID:   msg.ID,
+		headerSource:      msg.Source,
+		headerSpecVersion: msg.SpecVersion,
+		headerMessageType: msg.Type,
+		headerMessageTime: msg.Time,
+		headerSchema:      msg.DataSchema,
+		headerSubject:     msg.Subject,
+		headerExtensionPrefix + gluon.ExtensionCorrelationID: msg.CorrelationID,
+		headerExtensionPrefix + gluon.ExtensionCausationID:   msg.CausationID,
+	}
+	for k, v := range msg.TraceContext {
+		headers[k] = v
+	}
+	for k, v := range msg.Extensions {
+		headers[headerExtensionPrefix+k] = v
+	}
+	return headers
+}
+
+func unmarshalAmqpMessage(delivery *amqp.Delivery) *gluon.TransportMessage {
+	msg := &gluon.TransportMessage{
+		ID:              delivery.MessageId,
+		Type:            delivery.Type,
+		Data:            delivery.Body,
+		DataContentType: delivery.ContentType,
+		Topic:           delivery.RoutingKey,
+		DriverHeaders: map[string]string{
+			HeaderDeliveryTag: strconv.FormatUint(delivery.DeliveryTag, 10),
+			HeaderRedelivered: strconv.FormatBool(delivery.Redelivered),
+			HeaderRoutingKey:  delivery.RoutingKey,
+		},
+	}
+	for k, raw := range delivery.Headers {
+		v, ok := raw.(string)
+		if !ok {
+			continue
+		}
+		switch k {
+		case headerMessageID:
+			msg.ID = v
+		case headerSource:
+			msg.Source = v
+		case headerSpecVersion:
+			msg.SpecVersion = v
+		case headerMessageType:
+			msg.Type = v
+		case headerMessageTime:
+			msg.Time = v
+		case headerSchema:
+			msg.DataSchema = v
+		case headerSubject:
+			msg.Subject = v
+		case gluon.ExtensionTraceParent, gluon.ExtensionTraceState:
+			msg.SetAttribute(k, v)
+		default:
+			unmarshalAmqpExtension(k, v, msg)
+		}
+	}
+	return msg
+}
+
+func unmarshalAmqpExtension(key, value string, msg *gluon.TransportMessage) {
+	if !strings.HasPrefix(key, headerExtensionPrefix) {
+		return
+	}
+	msg.SetAttribute(strings.TrimPrefix(key, headerExtensionPrefix), value)
+}
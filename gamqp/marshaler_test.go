@@ -0,0 +1,54 @@
+package gamqp
+
+import (
+	"testing"
+
+	"github.com/neutrinocorp/gluon"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalAmqpMessage(t *testing.T) {
+	msg := &gluon.TransportMessage{
+		ID:              "123",
+		Source:          "https://api.neutrino.org/marketplace/items",
+		SpecVersion:     gluon.CloudEventsSpecVersion,
+		Type:            "org.neutrino.marketplace.item.paid",
+		Data:            []byte(`{"item_id":"abc"}`),
+		DataContentType: "application/json",
+		DataSchema:      "https://schemas.neutrino.org/item_paid.json",
+		Subject:         "abc",
+		Time:            "2021-01-01T00:00:00Z",
+		CorrelationID:   "456",
+		CausationID:     "789",
+		TraceContext: map[string]string{
+			"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		},
+		Extensions: map[string]string{
+			"tenantid": "neutrino",
+		},
+		Topic: "org.neutrino.marketplace.item.paid",
+	}
+	publishing := marshalAmqpMessage(msg)
+	assert.Equal(t, amqp.Persistent, publishing.DeliveryMode)
+	assert.Equal(t, "neutrino", publishing.Headers["cloudEvents:tenantid"])
+	assert.NoError(t, publishing.Headers.Validate())
+
+	got := unmarshalAmqpMessage(&amqp.Delivery{
+		Headers:     publishing.Headers,
+		ContentType: publishing.ContentType,
+		MessageId:   publishing.MessageId,
+		Type:        publishing.Type,
+		Body:        publishing.Body,
+		DeliveryTag: 7,
+		Redelivered: true,
+		RoutingKey:  msg.Topic,
+	})
+	assert.Equal(t, map[string]string{
+		HeaderDeliveryTag: "7",
+		HeaderRedelivered: "true",
+		HeaderRoutingKey:  msg.Topic,
+	}, got.DriverHeaders)
+	got.DriverHeaders = nil
+	assert.Equal(t, msg, got)
+}
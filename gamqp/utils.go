@@ -0,0 +1,40 @@
+package gamqp
+
+import (
+	"github.com/neutrinocorp/gluon"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// generateQueueName Generate the queue name of a consumer group subscribed to a binding key (e.g.
+// warehouse-service.org.neutrino.marketplace.item.paid).
+func generateQueueName(group, bindingKey string) string {
+	return group + "." + bindingKey
+}
+
+func getConsumerConfiguration(sub *gluon.Subscriber) ConsumerConfiguration {
+	if cfg, ok := sub.GetDriverConfiguration().(ConsumerConfiguration); ok {
+		return cfg
+	}
+	return ConsumerConfiguration{}
+}
+
+func getBindingKey(sub *gluon.Subscriber) string {
+	if key := getConsumerConfiguration(sub).BindingKey; key != "" {
+		return key
+	}
+	return sub.GetTopic()
+}
+
+// isDeliveryLimitReached Indicate if a message reached the maximum number of deliveries.
+//
+// Quorum queues report previous deliveries using the x-delivery-count header. Other queues (e.g. classic queues)
+// only report if the message was redelivered, so redelivered messages are considered at their limit.
+func isDeliveryLimitReached(delivery *amqp.Delivery, maxDeliveries int) bool {
+	switch count := delivery.Headers["x-delivery-count"].(type) {
+	case int64:
+		return int(count)+1 >= maxDeliveries
+	case int32:
+		return int(count)+1 >= maxDeliveries
+	}
+	return delivery.Redelivered || maxDeliveries <= 1
+}
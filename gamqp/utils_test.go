@@ -0,0 +1,24 @@
+package gamqp
+
+import (
+	"testing"
+
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBindingKey(t *testing.T) {
+	bus := gluon.NewBus(DriverName)
+	sub := bus.SubscribeTopic("org.neutrino.iam.command.user.created")
+	assert.Equal(t, "org.neutrino.iam.command.user.created", getBindingKey(sub))
+	assert.Equal(t, defaultPrefetchCount, getConsumerConfiguration(sub).GetPrefetchCount())
+
+	sub.DriverConfiguration(ConsumerConfiguration{
+		BindingKey:    "org.neutrino.iam.command.user.*",
+		PrefetchCount: 1,
+	})
+	assert.Equal(t, "org.neutrino.iam.command.user.*", getBindingKey(sub))
+	assert.Equal(t, 1, getConsumerConfiguration(sub).GetPrefetchCount())
+	assert.Equal(t, "iam-service.org.neutrino.iam.command.user.*",
+		generateQueueName("iam-service", getBindingKey(sub)))
+}
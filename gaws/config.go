@@ -22,11 +22,15 @@ type SnsSqsConfig struct {
 	SnsClient                 *sns.Client
 	SqsClient                 *sqs.Client
 	CustomSqsEndpoint         string
+	CustomSnsEndpoint         string
 	MaxNumberOfMessagesPolled int32
 	VisibilityTimeout         int32
 	WaitTimeSeconds           int32
 	MaxBatchPollingRetries    int
 	FailedPollingBackoff      time.Duration
+	// Provisioning Enable infrastructure provisioning. Topics, queues and subscriptions are created once the Bus
+	// starts (optional).
+	Provisioning *SnsSqsProvisioningConfig
 }
 
 func (c SnsSqsConfig) GetMaxNumberOfMessagesPolled() int32 {
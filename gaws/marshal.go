@@ -18,20 +18,41 @@ func unmarshalSnsMessage(msg *string) (*gluon.TransportMessage, error) {
 	if msg == nil {
 		return nil, ErrCannotUnmarshalSnsMessage
 	}
-	snsMsg := snsMessage{}
-	if err := json.Unmarshal([]byte(*msg), &snsMsg); err != nil {
+	envelope := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(*msg), &envelope); err != nil {
 		return nil, err
 	}
+	body := *msg
+	if snsMsg, ok := envelope["Message"].(string); ok {
+		body = snsMsg
+	} // otherwise, the message was delivered using SNS raw message delivery
 
 	gluonMsg := gluon.TransportMessage{}
-	if err := json.Unmarshal([]byte(snsMsg.Message), &gluonMsg); err != nil {
+	if isLegacySnsMessage(body) {
+		if err := json.Unmarshal([]byte(body), &gluonMsg); err != nil {
+			return nil, err
+		}
+		return &gluonMsg, nil
+	}
+	if err := gluon.UnmarshalStructuredMessage([]byte(body), &gluonMsg); err != nil {
 		return nil, err
 	}
 	return &gluonMsg, nil
 }
 
+// isLegacySnsMessage Indicate if a message was encoded by a previous version of `Gluon` (TransportMessage encoded as
+// JSON) instead of the CloudEvents JSON format.
+func isLegacySnsMessage(msg string) bool {
+	legacyMsg := struct {
+		CorrelationID *string `json:"gluon_correlation_id"`
+	}{}
+	return json.Unmarshal([]byte(msg), &legacyMsg) == nil && legacyMsg.CorrelationID != nil
+}
+
+// marshalSnsMessage Encode a message using the CloudEvents JSON format (structured content mode) as AWS SNS only
+// carries a message body.
 func marshalSnsMessage(msg *gluon.TransportMessage) (*string, error) {
-	msgJSON, err := json.Marshal(msg)
+	msgJSON, err := gluon.MarshalStructuredMessage(msg)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,70 @@
+package gaws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	json "github.com/json-iterator/go"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSnsEnvelope(t *testing.T, msg string) *string {
+	envelope, err := json.Marshal(snsMessage{Message: msg})
+	require.NoError(t, err)
+	return aws.String(string(envelope))
+}
+
+func TestMarshalSnsMessage(t *testing.T) {
+	msg := &gluon.TransportMessage{
+		ID:              "123",
+		Source:          "https://api.neutrino.org/marketplace/items",
+		SpecVersion:     gluon.CloudEventsSpecVersion,
+		Type:            "org.neutrino.marketplace.item.paid",
+		Data:            []byte(`{"item_id":"abc"}`),
+		DataContentType: "application/json",
+		CorrelationID:   "456",
+		CausationID:     "789",
+	}
+	snsMsg, err := marshalSnsMessage(msg)
+	require.NoError(t, err)
+	assert.Contains(t, *snsMsg, `"data":{"item_id":"abc"}`)
+
+	got, err := unmarshalSnsMessage(newSnsEnvelope(t, *snsMsg))
+	require.NoError(t, err)
+	assert.Equal(t, msg, got)
+}
+
+func TestUnmarshalSnsMessage_Legacy(t *testing.T) {
+	legacyMsg, err := json.Marshal(&gluon.TransportMessage{
+		ID:            "123",
+		SpecVersion:   gluon.CloudEventsSpecVersion,
+		Type:          "org.neutrino.marketplace.item.paid",
+		Data:          []byte(`{"item_id":"abc"}`),
+		CorrelationID: "456",
+	})
+	require.NoError(t, err)
+
+	got, err := unmarshalSnsMessage(newSnsEnvelope(t, string(legacyMsg)))
+	require.NoError(t, err)
+	assert.Equal(t, "456", got.CorrelationID)
+	assert.Equal(t, []byte(`{"item_id":"abc"}`), got.Data)
+}
+
+func TestUnmarshalSnsMessage_RawDelivery(t *testing.T) {
+	msg := &gluon.TransportMessage{
+		ID:            "123",
+		SpecVersion:   gluon.CloudEventsSpecVersion,
+		Type:          "org.neutrino.marketplace.item.paid",
+		Data:          []byte(`{"item_id":"abc"}`),
+		CorrelationID: "456",
+		CausationID:   "789",
+	}
+	rawMsg, err := marshalSnsMessage(msg)
+	require.NoError(t, err)
+
+	got, err := unmarshalSnsMessage(rawMsg)
+	require.NoError(t, err)
+	assert.Equal(t, msg, got)
+}
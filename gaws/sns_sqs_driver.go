@@ -7,10 +7,19 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/neutrinocorp/gluon"
 )
 
+// DriverName Is the name used to register the AWS SNS/SQS driver.
+const DriverName = "aws_sns_sqs"
+
+// topicMessageAttribute Is the SNS message attribute holding the topic of a message. Using raw message delivery,
+// SNS message attributes are delivered as SQS message attributes, so messages of a queue subscribed to many topics
+// are routed to the subscriber of their topic.
+const topicMessageAttribute = "gluon-topic"
+
 // Topic-queue chaining implementation
 // For more info: https://aws.amazon.com/blogs/compute/application-integration-patterns-for-microservices-fan-out-strategies/
 type snsSqsDriver struct {
@@ -25,28 +34,44 @@ type snsSqsDriver struct {
 }
 
 var (
-	_                     gluon.Driver = &snsSqsDriver{}
-	defaultDriver         *snsSqsDriver
-	snsSqsDriverSingleton = sync.Once{}
+	_ gluon.Driver      = &snsSqsDriver{}
+	_ gluon.Provisioner = &snsSqsDriver{}
 )
 
 func init() {
-	snsSqsDriverSingleton.Do(func() {
-		defaultDriver = &snsSqsDriver{}
-		defaultDriver.subscriberWorkerPool = sync.Pool{New: func() interface{} {
-			return newSnsSqsSubscriptionWorker(defaultDriver)
-		}}
-		gluon.Register("aws_sns_sqs", defaultDriver)
+	gluon.Register(DriverName, func() gluon.Driver {
+		return newSnsSqsDriver()
 	})
 }
 
+func newSnsSqsDriver() *snsSqsDriver {
+	d := &snsSqsDriver{}
+	d.subscriberWorkerPool = sync.Pool{New: func() interface{} {
+		return newSnsSqsSubscriptionWorker(d)
+	}}
+	return d
+}
+
 func (d *snsSqsDriver) SetParentBus(b *gluon.Bus) {
 	d.parentBus = b
 	if cfg, ok := b.Configuration.Driver.(SnsSqsConfig); ok {
 		d.config = cfg
 		d.snsClient = cfg.SnsClient
 		d.sqsClient = cfg.SqsClient
-
+	}
+	if d.snsClient == nil {
+		d.snsClient = sns.NewFromConfig(d.config.AwsConfig, func(o *sns.Options) {
+			if d.config.CustomSnsEndpoint != "" {
+				o.EndpointResolver = sns.EndpointResolverFromURL(d.config.CustomSnsEndpoint)
+			}
+		})
+	}
+	if d.sqsClient == nil {
+		d.sqsClient = sqs.NewFromConfig(d.config.AwsConfig, func(o *sqs.Options) {
+			if d.config.CustomSqsEndpoint != "" {
+				o.EndpointResolver = sqs.EndpointResolverFromURL(d.config.CustomSqsEndpoint)
+			}
+		})
 	}
 }
 
@@ -54,6 +79,15 @@ func (d *snsSqsDriver) SetInternalHandler(h gluon.InternalMessageHandler) {
 	d.messageHandler = h
 }
 
+// Provision Create the topics, queues and subscriptions required by the Bus if provisioning is enabled
+// (SnsSqsConfig.Provisioning).
+func (d *snsSqsDriver) Provision(ctx context.Context) error {
+	if d.config.Provisioning == nil {
+		return nil
+	}
+	return newSnsSqsProvisioner(d, *d.config.Provisioning).provision(ctx)
+}
+
 func (d *snsSqsDriver) Start(_ context.Context) error {
 	return nil
 }
@@ -88,10 +122,34 @@ func (d *snsSqsDriver) Publish(ctx context.Context, message *gluon.TransportMess
 	_, err = d.snsClient.Publish(ctx, &sns.PublishInput{
 		Message:  snsMsg,
 		TopicArn: aws.String(generateSnsTopicArn(d.config, message.Topic)),
+		MessageAttributes: map[string]snstypes.MessageAttributeValue{
+			topicMessageAttribute: {
+				DataType:    aws.String("String"),
+				StringValue: aws.String(message.Topic),
+			},
+		},
 	})
 	return
 }
 
+// getConsumerGroup Retrieve the consumer group of a subscriber, which is the name of its SQS queue.
+func (d *snsSqsDriver) getConsumerGroup(sub *gluon.Subscriber) string {
+	if group := sub.GetGroup(); group != "" {
+		return group
+	}
+	return d.parentBus.Configuration.ConsumerGroup
+}
+
+// getTopicSubscriber Retrieve the subscriber of a topic within a consumer group (queue), nil if not found.
+func (d *snsSqsDriver) getTopicSubscriber(group, topic string) *gluon.Subscriber {
+	for _, sub := range d.parentBus.Subscribers() {
+		if sub.GetTopic() == topic && d.getConsumerGroup(sub) == group {
+			return sub
+		}
+	}
+	return nil
+}
+
 func (d *snsSqsDriver) logError(err error) {
 	if err == nil {
 		return
@@ -0,0 +1,56 @@
+package gaws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnsSqsDriver_PublishTopicAttribute(t *testing.T) {
+	srv := newFakeAwsServer(t)
+	_, d := newTestProvisioningDriver(srv, nil)
+	require.NoError(t, d.Publish(context.Background(), &gluon.TransportMessage{
+		ID:          "123",
+		Source:      "https://api.neutrino.org",
+		SpecVersion: gluon.CloudEventsSpecVersion,
+		Type:        "foo.topic",
+		Topic:       "foo.topic.retry",
+	}))
+	reqs := srv.getRequests("Publish")
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "arn:aws:sns:us-east-1:000000000000:foo-topic-retry", reqs[0].Get("TopicArn"))
+	assert.Equal(t, map[string]string{topicMessageAttribute: "foo.topic.retry"},
+		getAttributes(reqs[0], "MessageAttributes.entry", "Name", "Value.StringValue"))
+}
+
+func TestSnsSqsSubscriptionWorker_GetMessageSubscriber(t *testing.T) {
+	srv := newFakeAwsServer(t)
+	b, d := newTestProvisioningDriver(srv, nil)
+	fooSub := b.SubscribeTopic("foo.topic")
+	barSub := b.SubscribeTopic("bar.topic")
+	b.SubscribeTopic("baz.topic").Group("baz-service")
+	w := newSnsSqsSubscriptionWorker(d)
+	w.rootSub = fooSub
+
+	newMessage := func(topic string) types.Message {
+		msg := types.Message{MessageAttributes: map[string]types.MessageAttributeValue{}}
+		if topic != "" {
+			msg.MessageAttributes[topicMessageAttribute] = types.MessageAttributeValue{
+				DataType:    aws.String("String"),
+				StringValue: aws.String(topic),
+			}
+		}
+		return msg
+	}
+	assert.Same(t, fooSub, w.getMessageSubscriber(newMessage("foo.topic")))
+	assert.Same(t, barSub, w.getMessageSubscriber(newMessage("bar.topic")))
+	// messages published without Gluon
+	assert.Same(t, fooSub, w.getMessageSubscriber(newMessage("")))
+	// subscribers of other consumer groups (queues)
+	assert.Nil(t, w.getMessageSubscriber(newMessage("baz.topic")))
+}
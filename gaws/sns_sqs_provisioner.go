@@ -0,0 +1,274 @@
+package gaws
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	json "github.com/json-iterator/go"
+	"github.com/neutrinocorp/gluon"
+)
+
+const (
+	defaultMaxReceiveCount = 5
+	deadLetterQueueSuffix  = "-dlq"
+	sqsPolicyVersion       = "2012-10-17"
+	sqsPolicyStatementID   = "AllowSnsSendMessage"
+)
+
+// SnsSqsProvisioningConfig Is the infrastructure provisioning configuration of the AWS SNS/SQS driver.
+//
+// Once the Bus starts, an SNS topic is created for every topic required by the Bus (gluon.Bus.Topics) and an SQS
+// queue is created for every consumer group. Queues are subscribed to the topics of their subscribers using raw
+// message delivery, allowed to receive messages from those topics (queue policy) and configured with a redrive
+// policy to a dead-letter queue (`<queue>-dlq`). Statements of existing queue policies are kept.
+//
+// A queue subscribed to many topics delivers every message to the subscriber of its topic within the consumer group.
+type SnsSqsProvisioningConfig struct {
+	// MaxReceiveCount Number of receptions of a message before SQS moves it to the dead-letter queue (5 by default).
+	MaxReceiveCount int
+	// TopicAttributes Attributes of created SNS topics (e.g. KmsMasterKeyId).
+	TopicAttributes map[string]string
+	// QueueAttributes Attributes of provisioned SQS queues (e.g. MessageRetentionPeriod).
+	QueueAttributes map[string]string
+}
+
+// GetMaxReceiveCount Retrieve the number of receptions of a message before it is moved to the dead-letter queue.
+func (c SnsSqsProvisioningConfig) GetMaxReceiveCount() int {
+	if c.MaxReceiveCount <= 0 {
+		return defaultMaxReceiveCount
+	}
+	return c.MaxReceiveCount
+}
+
+type sqsRedrivePolicy struct {
+	DeadLetterTargetArn string `json:"deadLetterTargetArn"`
+	MaxReceiveCount     string `json:"maxReceiveCount"`
+}
+
+type sqsPolicyStatement struct {
+	Sid       string                         `json:"Sid"`
+	Effect    string                         `json:"Effect"`
+	Principal map[string]string              `json:"Principal"`
+	Action    string                         `json:"Action"`
+	Resource  string                         `json:"Resource"`
+	Condition map[string]map[string][]string `json:"Condition"`
+}
+
+// mergeSqsPolicy Add a statement allowing the given SNS topics to send messages to the queue to its current policy.
+//
+// Statements of the current policy are kept as-is, except a previous statement generated by Gluon which is replaced.
+func mergeSqsPolicy(current, queueArn string, topicArns []string) (string, error) {
+	policy := map[string]json.RawMessage{}
+	if current != "" {
+		if err := json.Unmarshal([]byte(current), &policy); err != nil {
+			return "", err
+		}
+	}
+	var statements []json.RawMessage
+	if raw := bytes.TrimSpace(policy["Statement"]); len(raw) > 0 && raw[0] == '{' {
+		// a policy may hold a single statement object
+		statements = append(statements, raw)
+	} else if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &statements); err != nil {
+			return "", err
+		}
+	}
+
+	merged := make([]json.RawMessage, 0, len(statements)+1)
+	for _, statement := range statements {
+		sid := struct {
+			Sid string `json:"Sid"`
+		}{}
+		if err := json.Unmarshal(statement, &sid); err != nil {
+			return "", err
+		}
+		if sid.Sid != sqsPolicyStatementID {
+			merged = append(merged, statement)
+		}
+	}
+	statement, err := json.Marshal(sqsPolicyStatement{
+		Sid:       sqsPolicyStatementID,
+		Effect:    "Allow",
+		Principal: map[string]string{"Service": "sns.amazonaws.com"},
+		Action:    "sqs:SendMessage",
+		Resource:  queueArn,
+		Condition: map[string]map[string][]string{
+			"ArnEquals": {"aws:SourceArn": topicArns},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	merged = append(merged, statement)
+	if policy["Statement"], err = json.Marshal(merged); err != nil {
+		return "", err
+	}
+	if _, ok := policy["Version"]; !ok {
+		policy["Version"], _ = json.Marshal(sqsPolicyVersion)
+	}
+	out, err := json.Marshal(policy)
+	return string(out), err
+}
+
+// snsSqsProvisioner Create the topic-queue chaining infrastructure required by a Bus.
+type snsSqsProvisioner struct {
+	parentDriver *snsSqsDriver
+	config       SnsSqsProvisioningConfig
+
+	topicArns map[string]string // Key: gluon topic, Val: SNS topic ARN
+}
+
+func newSnsSqsProvisioner(d *snsSqsDriver, cfg SnsSqsProvisioningConfig) *snsSqsProvisioner {
+	return &snsSqsProvisioner{
+		parentDriver: d,
+		config:       cfg,
+		topicArns:    map[string]string{},
+	}
+}
+
+func (p *snsSqsProvisioner) provision(ctx context.Context) error {
+	for _, topic := range p.parentDriver.parentBus.Topics() {
+		if err := p.provisionTopic(ctx, topic); err != nil {
+			return err
+		}
+	}
+
+	// a queue may be subscribed to many topics, so topics are grouped by queue to generate its policy
+	queueTopics := map[string][]string{}
+	var queues []string
+	for _, sub := range p.parentDriver.parentBus.Subscribers() {
+		group := p.parentDriver.getConsumerGroup(sub)
+		if group == "" {
+			continue
+		}
+		topics, ok := queueTopics[group]
+		if !ok {
+			queues = append(queues, group)
+		}
+		if !containsString(topics, sub.GetTopic()) {
+			queueTopics[group] = append(topics, sub.GetTopic())
+		}
+	}
+	for _, group := range queues {
+		if err := p.provisionQueue(ctx, group, queueTopics[group]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *snsSqsProvisioner) provisionTopic(ctx context.Context, topic string) error {
+	out, err := p.parentDriver.snsClient.CreateTopic(ctx, &sns.CreateTopicInput{
+		Name:       aws.String(generateSnsTopicName(topic)),
+		Attributes: p.config.TopicAttributes,
+	})
+	if err != nil {
+		return gluon.NewError("SnsFailedProvisioning", fmt.Sprintf("Failed to create topic (%s)", topic), err)
+	}
+	p.topicArns[topic] = aws.ToString(out.TopicArn)
+	return nil
+}
+
+func (p *snsSqsProvisioner) provisionQueue(ctx context.Context, group string, topics []string) error {
+	dlqUrl, dlqAttrs, err := p.createQueue(ctx, generateSqsQueueName(group)+deadLetterQueueSuffix)
+	if err != nil {
+		return err
+	}
+	queueUrl, queueAttrs, err := p.createQueue(ctx, generateSqsQueueName(group))
+	if err != nil {
+		return err
+	}
+	queueArn := queueAttrs[string(types.QueueAttributeNameQueueArn)]
+
+	topicArns := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		topicArn := p.topicArns[topic]
+		topicArns = append(topicArns, topicArn)
+		_, err = p.parentDriver.snsClient.Subscribe(ctx, &sns.SubscribeInput{
+			TopicArn:              aws.String(topicArn),
+			Protocol:              aws.String("sqs"),
+			Endpoint:              aws.String(queueArn),
+			Attributes:            map[string]string{"RawMessageDelivery": "true"},
+			ReturnSubscriptionArn: true,
+		})
+		if err != nil {
+			return gluon.NewError("SnsFailedProvisioning",
+				fmt.Sprintf("Failed to subscribe queue (%s) to topic (%s)", queueUrl, topic), err)
+		}
+	}
+
+	policy, err := mergeSqsPolicy(queueAttrs[string(types.QueueAttributeNamePolicy)], queueArn, topicArns)
+	if err != nil {
+		return err
+	}
+	redrivePolicy, err := json.Marshal(sqsRedrivePolicy{
+		DeadLetterTargetArn: dlqAttrs[string(types.QueueAttributeNameQueueArn)],
+		MaxReceiveCount:     strconv.Itoa(p.config.GetMaxReceiveCount()),
+	})
+	if err != nil {
+		return err
+	}
+	attributes := make(map[string]string, len(p.config.QueueAttributes)+2)
+	for k, v := range p.config.QueueAttributes {
+		attributes[k] = v
+	}
+	attributes[string(types.QueueAttributeNamePolicy)] = policy
+	attributes[string(types.QueueAttributeNameRedrivePolicy)] = string(redrivePolicy)
+	if err = p.setQueueAttributes(ctx, queueUrl, attributes); err != nil {
+		return err
+	}
+	return p.setQueueAttributes(ctx, dlqUrl, p.config.QueueAttributes)
+}
+
+// createQueue Create a queue (if missing) and retrieve its URL along its ARN and policy attributes.
+func (p *snsSqsProvisioner) createQueue(ctx context.Context, name string) (string, map[string]string, error) {
+	out, err := p.parentDriver.sqsClient.CreateQueue(ctx, &sqs.CreateQueueInput{
+		QueueName: aws.String(name),
+	})
+	if err != nil {
+		return "", nil, gluon.NewError("SqsFailedProvisioning", fmt.Sprintf("Failed to create queue (%s)", name), err)
+	}
+	attrs, err := p.parentDriver.sqsClient.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl: out.QueueUrl,
+		AttributeNames: []types.QueueAttributeName{
+			types.QueueAttributeNameQueueArn,
+			types.QueueAttributeNamePolicy,
+		},
+	})
+	if err != nil {
+		return "", nil, gluon.NewError("SqsFailedProvisioning",
+			fmt.Sprintf("Failed to retrieve queue (%s) attributes", name), err)
+	}
+	return aws.ToString(out.QueueUrl), attrs.Attributes, nil
+}
+
+func (p *snsSqsProvisioner) setQueueAttributes(ctx context.Context, queueUrl string,
+	attributes map[string]string) error {
+	if len(attributes) == 0 {
+		return nil
+	}
+	_, err := p.parentDriver.sqsClient.SetQueueAttributes(ctx, &sqs.SetQueueAttributesInput{
+		QueueUrl:   aws.String(queueUrl),
+		Attributes: attributes,
+	})
+	if err != nil {
+		return gluon.NewError("SqsFailedProvisioning",
+			fmt.Sprintf("Failed to set queue (%s) attributes", queueUrl), err)
+	}
+	return nil
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package gaws
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	json "github.com/json-iterator/go"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAwsServer Is an in-memory SNS/SQS endpoint (AWS query protocol) recording received requests, it replaces
+// LocalStack in tests.
+type fakeAwsServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []url.Values
+	policies map[string]string // Key: queue name, Val: queue policy
+}
+
+func newFakeAwsServer(t *testing.T) *fakeAwsServer {
+	s := &fakeAwsServer{policies: map[string]string{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeAwsServer) handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.PostForm)
+
+	action := r.PostForm.Get("Action")
+	queueUrl := r.PostForm.Get("QueueUrl")
+	queueName := queueUrl[strings.LastIndex(queueUrl, "/")+1:]
+	var result string
+	switch action {
+	case "CreateTopic":
+		result = "<TopicArn>arn:aws:sns:us-east-1:000000000000:" + r.PostForm.Get("Name") + "</TopicArn>"
+	case "Subscribe":
+		result = "<SubscriptionArn>" + r.PostForm.Get("TopicArn") + ":1</SubscriptionArn>"
+	case "CreateQueue":
+		result = "<QueueUrl>" + s.URL + "/000000000000/" + r.PostForm.Get("QueueName") + "</QueueUrl>"
+	case "GetQueueAttributes":
+		result = "<Attribute><Name>QueueArn</Name><Value>arn:aws:sqs:us-east-1:000000000000:" +
+			queueName + "</Value></Attribute>"
+		if policy, ok := s.policies[queueName]; ok {
+			escaped := new(strings.Builder)
+			_ = xml.EscapeText(escaped, []byte(policy))
+			result += "<Attribute><Name>Policy</Name><Value>" + escaped.String() + "</Value></Attribute>"
+		}
+	case "SetQueueAttributes":
+		if policy, ok := getAttributes(r.PostForm, "Attribute", "Name", "Value")["Policy"]; ok {
+			s.policies[queueName] = policy
+		}
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	_, _ = fmt.Fprintf(w, `<%sResponse><%sResult>%s</%sResult>`+
+		`<ResponseMetadata><RequestId>1</RequestId></ResponseMetadata></%sResponse>`,
+		action, action, result, action, action)
+}
+
+// getRequests Retrieve received requests of the given action.
+func (s *fakeAwsServer) getRequests(action string) []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reqs := make([]url.Values, 0)
+	for _, req := range s.requests {
+		if req.Get("Action") == action {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+// getAttributes Retrieve the attributes of a query request (e.g. Attribute.1.Name, Attribute.1.Value).
+func getAttributes(req url.Values, prefix, keyField, valueField string) map[string]string {
+	attrs := map[string]string{}
+	for i := 1; req.Has(fmt.Sprintf("%s.%d.%s", prefix, i, keyField)); i++ {
+		attrs[req.Get(fmt.Sprintf("%s.%d.%s", prefix, i, keyField))] =
+			req.Get(fmt.Sprintf("%s.%d.%s", prefix, i, valueField))
+	}
+	return attrs
+}
+
+func newTestProvisioningDriver(srv *fakeAwsServer, provisioning *SnsSqsProvisioningConfig) (*gluon.Bus,
+	*snsSqsDriver) {
+	b := gluon.NewBus(DriverName, gluon.WithConsumerGroup("foo-service"),
+		gluon.WithDriverConfiguration(SnsSqsConfig{
+			AwsConfig: aws.Config{
+				Region: "us-east-1",
+				Credentials: aws.CredentialsProviderFunc(func(_ context.Context) (aws.Credentials, error) {
+					return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+				}),
+			},
+			AccountID:         "000000000000",
+			CustomSnsEndpoint: srv.URL,
+			CustomSqsEndpoint: srv.URL,
+			Provisioning:      provisioning,
+		}))
+	d := &snsSqsDriver{}
+	d.SetParentBus(b)
+	return b, d
+}
+
+func TestSnsSqsDriver_Provision(t *testing.T) {
+	srv := newFakeAwsServer(t)
+	b, d := newTestProvisioningDriver(srv, &SnsSqsProvisioningConfig{
+		QueueAttributes: map[string]string{"MessageRetentionPeriod": "3600"},
+	})
+	b.SubscribeTopic("foo.topic")
+	b.SubscribeTopic("bar.topic")
+	b.SubscribeTopic("baz.topic").Group("baz-service")
+	require.NoError(t, d.Provision(context.Background()))
+
+	topics := make([]string, 0)
+	for _, req := range srv.getRequests("CreateTopic") {
+		topics = append(topics, req.Get("Name"))
+	}
+	assert.Equal(t, []string{"bar-topic", "baz-topic", "foo-topic"}, topics)
+
+	queues := make([]string, 0)
+	for _, req := range srv.getRequests("CreateQueue") {
+		queues = append(queues, req.Get("QueueName"))
+	}
+	assert.ElementsMatch(t, []string{"foo-service-dlq", "foo-service", "baz-service-dlq", "baz-service"}, queues)
+
+	subscriptions := srv.getRequests("Subscribe")
+	require.Len(t, subscriptions, 3)
+	for _, req := range subscriptions {
+		assert.Equal(t, "sqs", req.Get("Protocol"))
+		assert.Equal(t, map[string]string{"RawMessageDelivery": "true"},
+			getAttributes(req, "Attributes.entry", "key", "value"))
+	}
+
+	queueAttrs := map[string]map[string]string{}
+	for _, req := range srv.getRequests("SetQueueAttributes") {
+		queueUrl := req.Get("QueueUrl")
+		queueAttrs[queueUrl[strings.LastIndex(queueUrl, "/")+1:]] = getAttributes(req, "Attribute", "Name", "Value")
+	}
+	require.Len(t, queueAttrs, 4)
+	assert.Equal(t, map[string]string{"MessageRetentionPeriod": "3600"}, queueAttrs["foo-service-dlq"])
+
+	attrs := queueAttrs["foo-service"]
+	assert.Equal(t, "3600", attrs["MessageRetentionPeriod"])
+	assert.JSONEq(t, `{"deadLetterTargetArn":"arn:aws:sqs:us-east-1:000000000000:foo-service-dlq",`+
+		`"maxReceiveCount":"5"}`, attrs["RedrivePolicy"])
+	policy := struct {
+		Version   string
+		Statement []sqsPolicyStatement
+	}{}
+	require.NoError(t, json.Unmarshal([]byte(attrs["Policy"]), &policy))
+	assert.Equal(t, "2012-10-17", policy.Version)
+	require.Len(t, policy.Statement, 1)
+	assert.Equal(t, "arn:aws:sqs:us-east-1:000000000000:foo-service", policy.Statement[0].Resource)
+	assert.ElementsMatch(t, []string{
+		"arn:aws:sns:us-east-1:000000000000:foo-topic",
+		"arn:aws:sns:us-east-1:000000000000:bar-topic",
+	}, policy.Statement[0].Condition["ArnEquals"]["aws:SourceArn"])
+}
+
+func TestSnsSqsDriver_Provision_ExistingPolicy(t *testing.T) {
+	srv := newFakeAwsServer(t)
+	srv.policies["foo-service"] = `{"Version":"2012-10-17","Id":"foo-policy","Statement":[` +
+		`{"Sid":"AllowS3","Effect":"Allow","Principal":"*","Action":["sqs:SendMessage"],` +
+		`"Resource":"arn:aws:sqs:us-east-1:000000000000:foo-service"},` +
+		`{"Sid":"AllowSnsSendMessage","Effect":"Allow","Principal":{"Service":"sns.amazonaws.com"},` +
+		`"Action":"sqs:SendMessage","Resource":"arn:aws:sqs:us-east-1:000000000000:foo-service",` +
+		`"Condition":{"ArnEquals":{"aws:SourceArn":["arn:aws:sns:us-east-1:000000000000:old-topic"]}}}]}`
+	b, d := newTestProvisioningDriver(srv, &SnsSqsProvisioningConfig{})
+	b.SubscribeTopic("foo.topic")
+	require.NoError(t, d.Provision(context.Background()))
+
+	policy := struct {
+		Version   string
+		Id        string
+		Statement []json.RawMessage
+	}{}
+	require.NoError(t, json.Unmarshal([]byte(srv.policies["foo-service"]), &policy))
+	assert.Equal(t, "foo-policy", policy.Id)
+	require.Len(t, policy.Statement, 2)
+	assert.JSONEq(t, `{"Sid":"AllowS3","Effect":"Allow","Principal":"*","Action":["sqs:SendMessage"],`+
+		`"Resource":"arn:aws:sqs:us-east-1:000000000000:foo-service"}`, string(policy.Statement[0]))
+	statement := sqsPolicyStatement{}
+	require.NoError(t, json.Unmarshal(policy.Statement[1], &statement))
+	assert.Equal(t, []string{"arn:aws:sns:us-east-1:000000000000:foo-topic"},
+		statement.Condition["ArnEquals"]["aws:SourceArn"])
+}
+
+func TestSnsSqsDriver_Provision_Disabled(t *testing.T) {
+	srv := newFakeAwsServer(t)
+	b, d := newTestProvisioningDriver(srv, nil)
+	b.SubscribeTopic("foo.topic")
+	require.NoError(t, d.Provision(context.Background()))
+	assert.Empty(t, srv.getRequests("CreateTopic"))
+	assert.Empty(t, srv.getRequests("CreateQueue"))
+}
@@ -34,7 +34,7 @@ func (s *snsSqsSubscriptionWorker) start(ctx context.Context, sub *gluon.Subscri
 				QueueUrl:                aws.String(queueUrl),
 				AttributeNames:          nil,
 				MaxNumberOfMessages:     s.parentDriver.config.GetMaxNumberOfMessagesPolled(),
-				MessageAttributeNames:   nil,
+				MessageAttributeNames:   []string{topicMessageAttribute},
 				ReceiveRequestAttemptId: nil,
 				VisibilityTimeout:       s.parentDriver.config.GetVisibilityTimeout(),
 				WaitTimeSeconds:         s.parentDriver.config.GetWaitTimeSeconds(),
@@ -56,6 +56,8 @@ func (s *snsSqsSubscriptionWorker) start(ctx context.Context, sub *gluon.Subscri
 				time.Sleep(s.parentDriver.config.FailedPollingBackoff)
 				continue
 			}
+			s.parentDriver.parentBus.Configuration.DriverHooks.ReportMessagesPolled(DriverName, sub.GetTopic(),
+				s.getDefaultConsumerGroup(sub), len(out.Messages))
 			s.fanOutMessagesProcesses(out.Messages...)
 
 			select {
@@ -87,10 +89,7 @@ func (s *snsSqsSubscriptionWorker) logError(err error) {
 }
 
 func (s *snsSqsSubscriptionWorker) getDefaultConsumerGroup(sub *gluon.Subscriber) string {
-	if group := sub.GetGroup(); group != "" {
-		return group
-	}
-	return s.parentDriver.parentBus.Configuration.ConsumerGroup
+	return s.parentDriver.getConsumerGroup(sub)
 }
 
 func (s *snsSqsSubscriptionWorker) fanOutMessagesProcesses(msgs ...types.Message) {
@@ -105,7 +104,26 @@ func (s *snsSqsSubscriptionWorker) processMessage(snsMessage types.Message) {
 	if err != nil {
 		return
 	}
-	go s.execMessageHandler(snsMessage, gluonMsg, s.rootSub)
+	sub := s.getMessageSubscriber(snsMessage)
+	if sub == nil {
+		// not acknowledged, so the queue redrive policy eventually moves the message to its dead-letter queue
+		s.logError(gluon.NewError("SqsMissingSubscriber",
+			fmt.Sprintf("No subscriber of topic (%s) found for message (%s)",
+				aws.ToString(snsMessage.MessageAttributes[topicMessageAttribute].StringValue), gluonMsg.ID), nil))
+		return
+	}
+	go s.execMessageHandler(snsMessage, gluonMsg, sub)
+}
+
+// getMessageSubscriber Retrieve the subscriber of a message topic, as a consumer group queue may be subscribed to
+// many topics. Falls back to the worker subscriber if the message has no topic (e.g. published without Gluon).
+// Returns nil if the consumer group has no subscriber of the message topic.
+func (s *snsSqsSubscriptionWorker) getMessageSubscriber(snsMessage types.Message) *gluon.Subscriber {
+	topic := aws.ToString(snsMessage.MessageAttributes[topicMessageAttribute].StringValue)
+	if topic == "" || topic == s.rootSub.GetTopic() {
+		return s.rootSub
+	}
+	return s.parentDriver.getTopicSubscriber(s.getDefaultConsumerGroup(s.rootSub), topic)
 }
 
 func (s *snsSqsSubscriptionWorker) execMessageHandler(snsMessage types.Message, msg *gluon.TransportMessage,
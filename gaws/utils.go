@@ -15,15 +15,19 @@ func generateSnsTopicArn(cfg SnsSqsConfig, topic string) string {
 	builder.WriteString(":")
 	builder.WriteString(cfg.AccountID)
 	builder.WriteString(":")
+	builder.WriteString(generateSnsTopicName(topic))
+	return builder.String()
+}
+
+func generateSnsTopicName(topic string) string {
 	// Note: Gluon constructs topics using '.' character. AWS SNS does not accept this character
 	// For more information: https://docs.aws.amazon.com/sns/latest/dg/sns-create-topic.html
-	builder.WriteString(strings.ReplaceAll(topic, ".", "-"))
-	return builder.String()
+	return strings.ReplaceAll(topic, ".", "-")
 }
 
 func generateSqsQueueUrl(cfg SnsSqsConfig, group string) string {
 	if cfg.CustomSqsEndpoint != "" {
-		return cfg.CustomSqsEndpoint + "/" + cfg.AccountID + "/" + strings.ReplaceAll(group, ".", "-")
+		return cfg.CustomSqsEndpoint + "/" + cfg.AccountID + "/" + generateSqsQueueName(group)
 	} else if cfg.AwsConfig.Region == "" || cfg.AccountID == "" || group == "" {
 		return ""
 	}
@@ -34,6 +38,10 @@ func generateSqsQueueUrl(cfg SnsSqsConfig, group string) string {
 	builder.WriteString(".amazonaws.com/")
 	builder.WriteString(cfg.AccountID)
 	builder.WriteString("/")
-	builder.WriteString(strings.ReplaceAll(group, ".", "-"))
+	builder.WriteString(generateSqsQueueName(group))
 	return builder.String()
 }
+
+func generateSqsQueueName(group string) string {
+	return strings.ReplaceAll(group, ".", "-")
+}
@@ -0,0 +1,138 @@
+// Package ghttp implements the CloudEvents HTTP protocol binding for `Gluon` messages, so they may be exchanged
+// with webhooks and any CloudEvents-compliant HTTP producer or consumer.
+package ghttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/neutrinocorp/gluon"
+)
+
+// ErrNotCloudEvent The HTTP message does not carry a CloudEvent.
+var ErrNotCloudEvent = errors.New("gluon: The HTTP message is not a CloudEvent")
+
+// NewRequest Allocate an HTTP POST request carrying a message using the given content mode.
+func NewRequest(ctx context.Context, url string, msg *gluon.TransportMessage,
+	mode gluon.ContentMode) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = WriteRequest(req, msg, mode); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// WriteRequest Encode a message into an HTTP request body and headers using the given content mode.
+func WriteRequest(req *http.Request, msg *gluon.TransportMessage, mode gluon.ContentMode) error {
+	body, err := WriteHeaders(req.Header, msg, mode)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return nil
+}
+
+// WriteHeaders Encode message attributes into HTTP headers using the given content mode. Returns the HTTP body.
+//
+// This may be used to write HTTP responses (e.g. http.ResponseWriter.Header).
+func WriteHeaders(h http.Header, msg *gluon.TransportMessage, mode gluon.ContentMode) ([]byte, error) {
+	for k, v := range msg.TraceContext {
+		h.Set(k, v)
+	}
+	if mode == gluon.ContentModeStructured {
+		h.Set(headerContentType, gluon.CloudEventsJSONContentType+"; charset=UTF-8")
+		return gluon.MarshalStructuredMessage(msg)
+	}
+
+	setAttributeHeader(h, "id", msg.ID)
+	setAttributeHeader(h, "source", msg.Source)
+	setAttributeHeader(h, "specversion", msg.SpecVersion)
+	setAttributeHeader(h, "type", msg.Type)
+	setAttributeHeader(h, "time", msg.Time)
+	setAttributeHeader(h, "dataschema", msg.DataSchema)
+	setAttributeHeader(h, "subject", msg.Subject)
+	setAttributeHeader(h, gluon.ExtensionCorrelationID, msg.CorrelationID)
+	setAttributeHeader(h, gluon.ExtensionCausationID, msg.CausationID)
+	for k, v := range msg.Extensions {
+		setAttributeHeader(h, k, v)
+	}
+	if msg.DataContentType != "" {
+		h.Set(headerContentType, msg.DataContentType)
+	}
+	return msg.Data, nil
+}
+
+func setAttributeHeader(h http.Header, name, value string) {
+	if value != "" {
+		h.Set(headerAttributePrefix+name, encodeHeaderValue(value))
+	}
+}
+
+// ReadRequest Decode a message from an HTTP request, detecting its content mode.
+func ReadRequest(req *http.Request) (*gluon.TransportMessage, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	return ReadMessage(req.Header, body)
+}
+
+// ReadMessage Decode a message from HTTP headers and body (e.g. an HTTP response), detecting its content mode.
+func ReadMessage(h http.Header, body []byte) (*gluon.TransportMessage, error) {
+	msg := new(gluon.TransportMessage)
+	if gluon.IsStructuredContentType(h.Get(headerContentType)) {
+		if err := gluon.UnmarshalStructuredMessage(body, msg); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	for k, v := range h {
+		if len(v) == 0 {
+			continue
+		}
+		name := strings.ToLower(k)
+		if name == gluon.ExtensionTraceParent || name == gluon.ExtensionTraceState {
+			msg.SetAttribute(name, v[0])
+			continue
+		} else if !strings.HasPrefix(name, headerAttributePrefix) {
+			continue
+		}
+		value := decodeHeaderValue(v[0])
+		switch name = strings.TrimPrefix(name, headerAttributePrefix); name {
+		case "id":
+			msg.ID = value
+		case "source":
+			msg.Source = value
+		case "specversion":
+			msg.SpecVersion = value
+		case "type":
+			msg.Type = value
+		case "time":
+			msg.Time = value
+		case "dataschema":
+			msg.DataSchema = value
+		case "subject":
+			msg.Subject = value
+		default:
+			msg.SetAttribute(name, value)
+		}
+	}
+	if msg.ID == "" || msg.Type == "" || msg.SpecVersion == "" {
+		return nil, ErrNotCloudEvent
+	}
+	msg.DataContentType = h.Get(headerContentType)
+	msg.Data = body
+	return msg, nil
+}
@@ -0,0 +1,108 @@
+package ghttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cloudevents "github.com/cloudevents/sdk-go/v2/event"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTransportMessage() *gluon.TransportMessage {
+	return &gluon.TransportMessage{
+		ID:              "123",
+		Source:          "https://api.neutrino.org/marketplace/items",
+		SpecVersion:     gluon.CloudEventsSpecVersion,
+		Type:            "org.neutrino.marketplace.item.paid",
+		Data:            []byte(`{"item_id":"abc"}`),
+		DataContentType: "application/json",
+		DataSchema:      "https://schemas.neutrino.org/item_paid.json",
+		Subject:         "abc",
+		Time:            "2021-01-01T00:00:00Z",
+		CorrelationID:   "456",
+		CausationID:     "789",
+		TraceContext: map[string]string{
+			"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		},
+		Extensions: map[string]string{
+			"tenantid": "neutrino",
+		},
+	}
+}
+
+func TestWriteRequest(t *testing.T) {
+	for _, mode := range []gluon.ContentMode{gluon.ContentModeBinary, gluon.ContentModeStructured} {
+		msg := newTestTransportMessage()
+		req, err := NewRequest(context.Background(), "http://localhost:8080", msg, mode)
+		require.NoError(t, err)
+		if mode == gluon.ContentModeBinary {
+			assert.Equal(t, "123", req.Header.Get("ce-id"))
+		}
+
+		// interoperability with the CloudEvents SDK
+		event, err := cehttp.NewEventFromHTTPRequest(req)
+		require.NoError(t, err)
+		assert.Equal(t, "123", event.ID())
+		assert.Equal(t, "abc", event.Subject())
+		assert.Equal(t, "application/json", event.DataContentType())
+		assert.Equal(t, []byte(msg.Data), event.Data())
+		assert.Equal(t, "neutrino", event.Extensions()["tenantid"])
+
+		req, err = NewRequest(context.Background(), "http://localhost:8080", msg, mode)
+		require.NoError(t, err)
+		got, err := ReadRequest(req)
+		require.NoError(t, err)
+		assert.Equal(t, msg, got)
+	}
+}
+
+func TestWriteRequest_PercentEncoding(t *testing.T) {
+	msg := newTestTransportMessage()
+	msg.Subject = "Euro € 100%"
+	req, err := NewRequest(context.Background(), "http://localhost:8080", msg, gluon.ContentModeBinary)
+	require.NoError(t, err)
+	assert.Equal(t, "Euro%20%E2%82%AC%20100%25", req.Header.Get("ce-subject"))
+
+	got, err := ReadRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, msg.Subject, got.Subject)
+}
+
+func TestReadRequest_SDK(t *testing.T) {
+	event := cloudevents.New()
+	event.SetID("123")
+	event.SetSource("https://api.neutrino.org/marketplace/items")
+	event.SetType("org.neutrino.marketplace.item.paid")
+	event.SetExtension("correlationid", "456")
+	require.NoError(t, event.SetData("application/json", map[string]string{"item_id": "abc"}))
+
+	for _, structured := range []bool{false, true} {
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:8080", nil)
+		require.NoError(t, err)
+		ctx := context.Background()
+		if structured {
+			ctx = binding.WithForceStructured(ctx)
+		}
+		require.NoError(t, cehttp.WriteRequest(ctx, binding.ToMessage(&event), req))
+
+		got, err := ReadRequest(req)
+		require.NoError(t, err)
+		assert.Equal(t, "123", got.ID)
+		assert.Equal(t, "org.neutrino.marketplace.item.paid", got.Type)
+		assert.Equal(t, "456", got.CorrelationID)
+		assert.JSONEq(t, `{"item_id":"abc"}`, string(got.Data))
+	}
+}
+
+func TestReadRequest_NotCloudEvent(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:8080", nil)
+	require.NoError(t, err)
+	req.Body = http.NoBody
+	_, err = ReadRequest(req)
+	assert.ErrorIs(t, err, ErrNotCloudEvent)
+}
@@ -0,0 +1,29 @@
+package ghttp
+
+import (
+	"net/url"
+	"strings"
+)
+
+// encodeHeaderValue Percent-encode header values as stated by the CloudEvents HTTP protocol binding: space, double
+// quote, percent and any character outside printable ASCII.
+func encodeHeaderValue(value string) string {
+	builder := strings.Builder{}
+	for _, b := range []byte(value) {
+		if b <= ' ' || b >= 0x7f || b == '"' || b == '%' {
+			builder.WriteByte('%')
+			builder.WriteByte("0123456789ABCDEF"[b>>4])
+			builder.WriteByte("0123456789ABCDEF"[b&0xf])
+			continue
+		}
+		builder.WriteByte(b)
+	}
+	return builder.String()
+}
+
+func decodeHeaderValue(value string) string {
+	if decoded, err := url.PathUnescape(value); err == nil {
+		return decoded
+	}
+	return value
+}
@@ -0,0 +1,8 @@
+package ghttp
+
+// HTTP headers, following the CloudEvents HTTP protocol binding
+const (
+	headerContentType = "Content-Type"
+	// headerAttributePrefix is used to carry CloudEvents context attributes (e.g. ce-id, ce-type, ce-tenantid)
+	headerAttributePrefix = "ce-"
+)
@@ -0,0 +1,48 @@
+package gkafka
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Shopify/sarama"
+)
+
+// ErrMissingAcknowledgement The context does not belong to a handler of a consumer group message.
+var ErrMissingAcknowledgement = errors.New("gkafka: Context has no consumer group message to acknowledge")
+
+type ackContextKey struct{}
+
+// messageAck Marks the offset of a consumer group message.
+type messageAck struct {
+	session    sarama.ConsumerGroupSession
+	kMsg       *sarama.ConsumerMessage
+	syncCommit bool
+	acked      bool
+}
+
+// ack Mark the message offset, once.
+func (a *messageAck) ack() {
+	if a.acked {
+		return
+	}
+	a.acked = true
+	a.session.MarkMessage(a.kMsg, "")
+	if a.syncCommit {
+		a.session.Commit()
+	}
+}
+
+func withMessageAck(ctx context.Context, a *messageAck) context.Context {
+	return context.WithValue(ctx, ackContextKey{}, a)
+}
+
+// Ack Acknowledge the consumer group message being handled (ctx must be a handler context), so its offset is
+// committed. Required by subscribers using ConsumerConfiguration.ManualCommit.
+func Ack(ctx context.Context) error {
+	a, ok := ctx.Value(ackContextKey{}).(*messageAck)
+	if !ok {
+		return ErrMissingAcknowledgement
+	}
+	a.ack()
+	return nil
+}
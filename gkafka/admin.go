@@ -0,0 +1,227 @@
+package gkafka
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/gluon"
+)
+
+// ErrConsumerGroupActive The consumer group has active members, so its offsets cannot be reset.
+var ErrConsumerGroupActive = errors.New("gkafka: Consumer group has active members")
+
+// PartitionLag Is the position of a consumer group within a topic partition.
+type PartitionLag struct {
+	Partition int32
+	// Committed Is the committed offset of the consumer group, -1 if the group has not committed offsets yet.
+	Committed int64
+	// HighWaterMark Is the offset of the next message produced to the partition.
+	HighWaterMark int64
+	// Lag Is the number of messages the consumer group is behind the high water mark.
+	Lag int64
+}
+
+// Admin Is the administrative interface of the Apache Kafka driver, used to inspect and reposition consumer groups
+// (e.g. replay a topic from a point in time).
+type Admin interface {
+	// ConsumerGroupLag Retrieve the lag of a consumer group for every partition of a topic.
+	ConsumerGroupLag(ctx context.Context, group, topic string) ([]PartitionLag, error)
+	// ResetConsumerGroupOffsets Reposition a consumer group on every partition of a topic.
+	//
+	// Consumer groups with active members cannot be repositioned (ErrConsumerGroupActive), so subscribers of the
+	// group must be stopped first. Once they start again, messages are consumed from the given position.
+	ResetConsumerGroupOffsets(ctx context.Context, group, topic string, pos Position) error
+	// ProvisionTopics Create the missing topics using their settings and retrieve the drifts of existing topics.
+	ProvisionTopics(ctx context.Context, cfg TopicConfiguration, topics ...string) ([]TopicDrift, error)
+	// Close Release the Admin resources.
+	Close() error
+}
+
+type admin struct {
+	client sarama.Client
+}
+
+var _ Admin = &admin{}
+
+// NewAdmin Allocate an Admin using the brokers (gluon.WithCluster or Config.Brokers) and the driver configuration of
+// a Bus.
+func NewAdmin(b *gluon.Bus) (Admin, error) {
+	driverCfg, cfg, err := parseDriverConfiguration(b.Configuration.Driver)
+	if err != nil {
+		return nil, err
+	}
+	return newAdmin(driverCfg.getBrokers(b.Addresses), cfg)
+}
+
+func newAdmin(addrs []string, cfg *sarama.Config) (*admin, error) {
+	client, err := sarama.NewClient(addrs, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &admin{client: client}, nil
+}
+
+func (a *admin) ConsumerGroupLag(ctx context.Context, group, topic string) ([]PartitionLag, error) {
+	partitions, err := a.client.Partitions(topic)
+	if err != nil {
+		return nil, err
+	}
+	coordinator, err := a.client.Coordinator(group)
+	if err != nil {
+		return nil, err
+	}
+	req := &sarama.OffsetFetchRequest{Version: 1, ConsumerGroup: group}
+	for _, partition := range partitions {
+		req.AddPartition(topic, partition)
+	}
+	res, err := coordinator.FetchOffset(req)
+	if err != nil {
+		return nil, err
+	}
+
+	lags := make([]PartitionLag, 0, len(partitions))
+	for _, partition := range partitions {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		lag := PartitionLag{Partition: partition, Committed: -1}
+		if block := res.GetBlock(topic, partition); block != nil {
+			if block.Err != sarama.ErrNoError {
+				return nil, block.Err
+			}
+			lag.Committed = block.Offset
+		}
+		if lag.HighWaterMark, err = a.client.GetOffset(topic, partition, sarama.OffsetNewest); err != nil {
+			return nil, err
+		}
+		consumed := lag.Committed
+		if consumed < 0 {
+			// the whole partition is pending
+			if consumed, err = a.client.GetOffset(topic, partition, sarama.OffsetOldest); err != nil {
+				return nil, err
+			}
+		}
+		if lag.Lag = lag.HighWaterMark - consumed; lag.Lag < 0 {
+			lag.Lag = 0
+		}
+		lags = append(lags, lag)
+	}
+	return lags, nil
+}
+
+func (a *admin) ResetConsumerGroupOffsets(ctx context.Context, group, topic string, pos Position) error {
+	coordinator, err := a.client.Coordinator(group)
+	if err != nil {
+		return err
+	}
+	desc, err := coordinator.DescribeGroups(&sarama.DescribeGroupsRequest{Groups: []string{group}})
+	if err != nil {
+		return err
+	}
+	for _, g := range desc.Groups {
+		if g.State != "" && g.State != "Empty" && g.State != "Dead" {
+			return ErrConsumerGroupActive
+		}
+	}
+
+	partitions, err := a.client.Partitions(topic)
+	if err != nil {
+		return err
+	}
+	req := &sarama.OffsetCommitRequest{
+		Version:                 2,
+		ConsumerGroup:           group,
+		ConsumerGroupGeneration: sarama.GroupGenerationUndefined,
+		RetentionTime:           -1,
+	}
+	for _, partition := range partitions {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		offset, errOffset := resolveOffset(a.client, topic, partition, pos)
+		if errOffset != nil {
+			return errOffset
+		}
+		req.AddBlock(topic, partition, offset, 0, sarama.ReceiveTime, "")
+	}
+	res, err := coordinator.CommitOffset(req)
+	if err != nil {
+		return err
+	}
+	for _, partition := range partitions {
+		if kErr, ok := res.Errors[topic][partition]; ok && kErr != sarama.ErrNoError {
+			return kErr
+		}
+	}
+	return nil
+}
+
+func (a *admin) ProvisionTopics(ctx context.Context, cfg TopicConfiguration, topics ...string) ([]TopicDrift,
+	error) {
+	// the cluster admin shares the client, so it is closed along the Admin
+	clusterAdmin, err := sarama.NewClusterAdminFromClient(a.client)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := clusterAdmin.ListTopics()
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []TopicDrift
+	for _, topic := range topics {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		spec := cfg.GetTopicSpec(topic)
+		if detail, ok := existing[topic]; ok {
+			configs, errConfig := describeTopicConfigs(clusterAdmin, topic, spec)
+			if errConfig != nil {
+				return nil, errConfig
+			}
+			drifts = append(drifts, getTopicDrifts(topic, spec, detail.NumPartitions, detail.ReplicationFactor,
+				configs)...)
+			continue
+		}
+		configs := make(map[string]*string, len(spec.Configs))
+		for k := range spec.Configs {
+			v := spec.Configs[k]
+			configs[k] = &v
+		}
+		err = clusterAdmin.CreateTopic(topic, &sarama.TopicDetail{
+			NumPartitions:     spec.NumPartitions,
+			ReplicationFactor: spec.ReplicationFactor,
+			ConfigEntries:     configs,
+		}, false)
+		if err != nil && !errors.Is(err, sarama.ErrTopicAlreadyExists) {
+			// topics might be created concurrently by other Bus instances
+			return nil, err
+		}
+	}
+	return drifts, nil
+}
+
+// describeTopicConfigs Retrieve the configuration entries of an existing topic, including broker defaults (topic
+// listings only contain overridden entries).
+func describeTopicConfigs(clusterAdmin sarama.ClusterAdmin, topic string, spec TopicSpec) (map[string]string, error) {
+	if len(spec.Configs) == 0 {
+		return nil, nil
+	}
+	entries, err := clusterAdmin.DescribeConfig(sarama.ConfigResource{
+		Type: sarama.TopicResource,
+		Name: topic,
+	})
+	if err != nil {
+		return nil, err
+	}
+	configs := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		configs[entry.Name] = entry.Value
+	}
+	return configs, nil
+}
+
+func (a *admin) Close() error {
+	return a.client.Close()
+}
@@ -0,0 +1,213 @@
+package gkafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var replayTime = time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestAdmin(t *testing.T, groupState string) (*sarama.MockBroker, Admin) {
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("foo.topic", 0, broker.BrokerID()).
+			SetLeader("foo.topic", 1, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("foo.topic", 0, sarama.OffsetOldest, 0).
+			SetOffset("foo.topic", 0, sarama.OffsetNewest, 10).
+			SetOffset("foo.topic", 0, replayTime.UnixMilli(), 7).
+			SetOffset("foo.topic", 1, sarama.OffsetOldest, 3).
+			SetOffset("foo.topic", 1, sarama.OffsetNewest, 5).
+			SetOffset("foo.topic", 1, replayTime.UnixMilli(), -1),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, "foo-service", broker),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("foo-service", "foo.topic", 0, 4, "", sarama.ErrNoError),
+		"DescribeGroupsRequest": sarama.NewMockDescribeGroupsResponse(t).
+			AddGroupDescription("foo-service", &sarama.GroupDescription{GroupId: "foo-service", State: groupState}),
+		"OffsetCommitRequest": sarama.NewMockOffsetCommitResponse(t),
+	})
+
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V2_0_0_0
+	b := gluon.NewBus(DriverName, gluon.WithCluster(broker.Addr()), gluon.WithDriverConfiguration(cfg))
+	a, err := NewAdmin(b)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = a.Close()
+	})
+	return broker, a
+}
+
+func getCommittedOffsets(broker *sarama.MockBroker, topic string) map[int32]int64 {
+	offsets := map[int32]int64{}
+	for _, rr := range broker.History() {
+		req, ok := rr.Request.(*sarama.OffsetCommitRequest)
+		if !ok {
+			continue
+		}
+		for _, partition := range []int32{0, 1} {
+			if offset, _, err := req.Offset(topic, partition); err == nil {
+				offsets[partition] = offset
+			}
+		}
+	}
+	return offsets
+}
+
+func TestAdmin_ConsumerGroupLag(t *testing.T) {
+	_, a := newTestAdmin(t, "Empty")
+	lags, err := a.ConsumerGroupLag(context.Background(), "foo-service", "foo.topic")
+	require.NoError(t, err)
+	assert.Equal(t, []PartitionLag{
+		{Partition: 0, Committed: 4, HighWaterMark: 10, Lag: 6},
+		{Partition: 1, Committed: -1, HighWaterMark: 5, Lag: 2},
+	}, lags)
+}
+
+func TestAdmin_ResetConsumerGroupOffsets(t *testing.T) {
+	tests := []struct {
+		name  string
+		state string
+		pos   Position
+		exp   map[int32]int64
+		err   error
+	}{
+		{
+			name:  "Earliest",
+			state: "Empty",
+			pos:   PositionEarliest,
+			exp:   map[int32]int64{0: 0, 1: 3},
+		},
+		{
+			name:  "Latest",
+			state: "Empty",
+			pos:   PositionLatest,
+			exp:   map[int32]int64{0: 10, 1: 5},
+		},
+		{
+			name:  "Offset",
+			state: "Dead",
+			pos:   PositionAt(2),
+			exp:   map[int32]int64{0: 2, 1: 2},
+		},
+		{
+			name:  "Time",
+			state: "Empty",
+			pos:   PositionAtTime(replayTime),
+			exp:   map[int32]int64{0: 7, 1: 5},
+		},
+		{
+			name:  "Active group",
+			state: "Stable",
+			pos:   PositionEarliest,
+			exp:   map[int32]int64{},
+			err:   ErrConsumerGroupActive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker, a := newTestAdmin(t, tt.state)
+			err := a.ResetConsumerGroupOffsets(context.Background(), "foo-service", "foo.topic", tt.pos)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.exp, getCommittedOffsets(broker, "foo.topic"))
+		})
+	}
+}
+
+func newTestProvisioningBroker(t *testing.T) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetController(broker.BrokerID()).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("foo.topic", 0, broker.BrokerID()).
+			SetLeader("foo.topic", 1, broker.BrokerID()),
+		"DescribeConfigsRequest": sarama.NewMockDescribeConfigsResponse(t),
+		"CreateTopicsRequest":    sarama.NewMockCreateTopicsResponse(t),
+	})
+	return broker
+}
+
+func getCreatedTopics(broker *sarama.MockBroker) map[string]*sarama.TopicDetail {
+	topics := map[string]*sarama.TopicDetail{}
+	for _, rr := range broker.History() {
+		if req, ok := rr.Request.(*sarama.CreateTopicsRequest); ok {
+			for topic, detail := range req.TopicDetails {
+				topics[topic] = detail
+			}
+		}
+	}
+	return topics
+}
+
+func TestAdmin_ProvisionTopics(t *testing.T) {
+	broker := newTestProvisioningBroker(t)
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V2_0_0_0
+	a, err := newAdmin([]string{broker.Addr()}, cfg)
+	require.NoError(t, err)
+	defer a.Close()
+
+	drifts, err := a.ProvisionTopics(context.Background(), TopicConfiguration{
+		Default: TopicSpec{
+			NumPartitions: 2,
+			Configs:       map[string]string{"retention.ms": "5000"},
+		},
+		Topics: map[string]TopicSpec{
+			// max.message.bytes matches the broker default
+			"foo.topic": {Configs: map[string]string{"cleanup.policy": "compact", "max.message.bytes": "1000000"}},
+		},
+	}, "foo.topic", "foo.topic.dlq")
+	require.NoError(t, err)
+	assert.Equal(t, []TopicDrift{
+		{Topic: "foo.topic", Setting: "cleanup.policy", Expected: "compact"},
+	}, drifts)
+
+	retention := "5000"
+	assert.Equal(t, map[string]*sarama.TopicDetail{
+		"foo.topic.dlq": {
+			NumPartitions:     2,
+			ReplicationFactor: 1,
+			ConfigEntries:     map[string]*string{"retention.ms": &retention},
+		},
+	}, getCreatedTopics(broker))
+}
+
+func TestDriver_Provision(t *testing.T) {
+	broker := newTestProvisioningBroker(t)
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V2_0_0_0
+	topicCfg := &TopicConfiguration{
+		Default:     TopicSpec{NumPartitions: 3},
+		FailOnDrift: true,
+	}
+	b := gluon.NewBus(DriverName, gluon.WithCluster(broker.Addr()),
+		gluon.WithDriverConfiguration(Configuration{Config: cfg, Topics: topicCfg}))
+	b.SubscribeTopic("foo.topic").DeadLetterPolicy(gluon.DeadLetterPolicy{UseRetryTopic: true})
+	d := &driver{}
+	d.SetParentBus(b)
+	assert.ErrorIs(t, d.Provision(context.Background()), ErrTopicDrift)
+	created := getCreatedTopics(broker)
+	assert.Contains(t, created, "foo.topic.dlq")
+	assert.Contains(t, created, "foo.topic.retry")
+	assert.NotContains(t, created, "foo.topic")
+
+	// drifts are only logged
+	topicCfg.FailOnDrift = false
+	assert.NoError(t, d.Provision(context.Background()))
+
+	// provisioning is opt-in
+	d.driverConfig.Topics = nil
+	assert.NoError(t, d.Provision(context.Background()))
+}
@@ -0,0 +1,373 @@
+package gkafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/gluon"
+	"github.com/xdg-go/scram"
+)
+
+// ErrMissingAccessToken The SASL/OAUTHBEARER mechanism was enabled without an access token or token provider.
+var ErrMissingAccessToken = errors.New("gkafka: Missing SASL/OAUTHBEARER access token")
+
+// SASLMechanism Is a SASL authentication mechanism supported by Apache Kafka.
+type SASLMechanism string
+
+const (
+	// SASLPlain Authenticate using a username and password in plain text (use along TLS).
+	SASLPlain SASLMechanism = "PLAIN"
+	// SASLScramSHA256 Authenticate using a username and password with the SCRAM-SHA-256 challenge.
+	SASLScramSHA256 SASLMechanism = "SCRAM-SHA-256"
+	// SASLScramSHA512 Authenticate using a username and password with the SCRAM-SHA-512 challenge.
+	SASLScramSHA512 SASLMechanism = "SCRAM-SHA-512"
+	// SASLOAuthBearer Authenticate using an OAuth 2 access token.
+	SASLOAuthBearer SASLMechanism = "OAUTHBEARER"
+)
+
+// Acks Is the number of broker acknowledgements required to consider a message produced.
+type Acks string
+
+const (
+	// AcksNone Messages are not acknowledged by brokers.
+	AcksNone Acks = "none"
+	// AcksLeader Messages are acknowledged once the partition leader stores them.
+	AcksLeader Acks = "leader"
+	// AcksAll Messages are acknowledged once every in-sync replica stores them.
+	AcksAll Acks = "all"
+)
+
+// TokenProviderFunc Retrieve an OAuth 2 access token. Implementations should cache and refresh tokens, as it is
+// called every time a broker connection is established.
+type TokenProviderFunc func() (string, error)
+
+// SASLConfig Is the SASL authentication configuration of the Apache Kafka client.
+type SASLConfig struct {
+	// Mechanism SASL mechanism used to authenticate. SASL is disabled if empty.
+	Mechanism SASLMechanism
+	// Username Authentication identity of PLAIN and SCRAM mechanisms.
+	Username string
+	// Password Password of PLAIN and SCRAM mechanisms.
+	Password string
+	// Token Static access token of the OAUTHBEARER mechanism, used if TokenProvider is nil.
+	Token string
+	// TokenProvider Retrieve access tokens of the OAUTHBEARER mechanism (optional).
+	TokenProvider TokenProviderFunc
+}
+
+// TLSConfig Is the TLS configuration of the Apache Kafka client. Certificates and keys are PEM encoded files.
+type TLSConfig struct {
+	// Enabled Connect to brokers using TLS. TLS is enabled as well if any file is set.
+	Enabled bool
+	// CAFile Certificate authorities used to verify brokers, system authorities are used by default.
+	CAFile string
+	// CertFile Client certificate used for mutual TLS (mTLS), requires KeyFile.
+	CertFile string
+	// KeyFile Private key of the client certificate.
+	KeyFile string
+	// ServerName Name used to verify broker certificates, the broker host by default.
+	ServerName string
+	// InsecureSkipVerify Skip the verification of broker certificates. Use it for testing purposes only.
+	InsecureSkipVerify bool
+}
+
+// IsEnabled Indicate if brokers are connected using TLS.
+func (c TLSConfig) IsEnabled() bool {
+	return c.Enabled || c.CAFile != "" || c.CertFile != "" || c.KeyFile != ""
+}
+
+// newTLSConfig Load the certificates and allocate the crypto/tls configuration.
+func (c TLSConfig) newTLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, gluon.NewError("KafkaInvalidConfiguration",
+				"No certificates found in CA file ("+c.CAFile+")", nil)
+		}
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// Config Is the Apache Kafka client configuration, used to configure the driver without depending on sarama
+// (see Configuration.Client).
+//
+// Zero values keep sarama defaults (or the values of Configuration.Config, if set).
+type Config struct {
+	// Brokers Addresses of the bootstrap brokers. Overrides the Bus cluster addresses (gluon.WithCluster).
+	Brokers []string
+	// ClientID Name of the client sent to brokers, used in logs and quotas.
+	ClientID string
+	// Version Apache Kafka version of the brokers (e.g. 2.8.0).
+	Version string
+
+	SASL SASLConfig
+	TLS  TLSConfig
+
+	// Compression Codec used to compress produced messages: none, gzip, snappy, lz4 or zstd.
+	Compression string
+	// Acks Broker acknowledgements required to consider a message produced (AcksAll if Idempotent is set).
+	Acks Acks
+	// Idempotent Produce every message exactly once per partition, even if the producer retries.
+	Idempotent bool
+
+	// SessionTimeout Waiting time before a consumer group member is considered dead if brokers receive no heartbeats.
+	SessionTimeout time.Duration
+	// HeartbeatInterval Waiting time between consumer group heartbeats.
+	HeartbeatInterval time.Duration
+	// RebalanceTimeout Maximum time consumer group members have to join the group during rebalances.
+	RebalanceTimeout time.Duration
+}
+
+// NewConfigFromEnv Load a Config from environment variables named after the given prefix (e.g. KAFKA_).
+//
+// Supported variables (without prefix) are BROKERS (comma-separated), CLIENT_ID, VERSION, SASL_MECHANISM,
+// SASL_USERNAME, SASL_PASSWORD, SASL_TOKEN, TLS_ENABLED, TLS_CA_FILE, TLS_CERT_FILE, TLS_KEY_FILE, TLS_SERVER_NAME,
+// TLS_INSECURE_SKIP_VERIFY, COMPRESSION, ACKS, IDEMPOTENT, SESSION_TIMEOUT, HEARTBEAT_INTERVAL and
+// REBALANCE_TIMEOUT. Durations use time.ParseDuration format (e.g. 30s).
+func NewConfigFromEnv(prefix string) (Config, error) {
+	env := envLoader{prefix: prefix}
+	cfg := Config{
+		ClientID: env.string("CLIENT_ID"),
+		Version:  env.string("VERSION"),
+		SASL: SASLConfig{
+			Mechanism: SASLMechanism(strings.ToUpper(env.string("SASL_MECHANISM"))),
+			Username:  env.string("SASL_USERNAME"),
+			Password:  env.string("SASL_PASSWORD"),
+			Token:     env.string("SASL_TOKEN"),
+		},
+		TLS: TLSConfig{
+			Enabled:            env.bool("TLS_ENABLED"),
+			CAFile:             env.string("TLS_CA_FILE"),
+			CertFile:           env.string("TLS_CERT_FILE"),
+			KeyFile:            env.string("TLS_KEY_FILE"),
+			ServerName:         env.string("TLS_SERVER_NAME"),
+			InsecureSkipVerify: env.bool("TLS_INSECURE_SKIP_VERIFY"),
+		},
+		Compression:       env.string("COMPRESSION"),
+		Acks:              Acks(strings.ToLower(env.string("ACKS"))),
+		Idempotent:        env.bool("IDEMPOTENT"),
+		SessionTimeout:    env.duration("SESSION_TIMEOUT"),
+		HeartbeatInterval: env.duration("HEARTBEAT_INTERVAL"),
+		RebalanceTimeout:  env.duration("REBALANCE_TIMEOUT"),
+	}
+	if brokers := env.string("BROKERS"); brokers != "" {
+		for _, broker := range strings.Split(brokers, ",") {
+			if broker = strings.TrimSpace(broker); broker != "" {
+				cfg.Brokers = append(cfg.Brokers, broker)
+			}
+		}
+	}
+	return cfg, env.err
+}
+
+// newSaramaConfig Translate the configuration into a sarama configuration based on a copy of base (if any).
+func (c Config) newSaramaConfig(base *sarama.Config) (*sarama.Config, error) {
+	cfg := sarama.NewConfig()
+	if base != nil {
+		copied := *base
+		cfg = &copied
+	}
+	if c.ClientID != "" {
+		cfg.ClientID = c.ClientID
+	}
+	if c.Version != "" {
+		version, err := sarama.ParseKafkaVersion(c.Version)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Version = version
+	}
+	if err := c.applySASL(cfg); err != nil {
+		return nil, err
+	}
+	if c.TLS.IsEnabled() {
+		tlsCfg, err := c.TLS.newTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Net.TLS.Enable = true
+		cfg.Net.TLS.Config = tlsCfg
+	}
+	if err := c.applyProducer(cfg); err != nil {
+		return nil, err
+	}
+	if c.SessionTimeout > 0 {
+		cfg.Consumer.Group.Session.Timeout = c.SessionTimeout
+	}
+	if c.HeartbeatInterval > 0 {
+		cfg.Consumer.Group.Heartbeat.Interval = c.HeartbeatInterval
+	}
+	if c.RebalanceTimeout > 0 {
+		cfg.Consumer.Group.Rebalance.Timeout = c.RebalanceTimeout
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c Config) applySASL(cfg *sarama.Config) error {
+	if c.SASL.Mechanism == "" {
+		return nil
+	}
+	cfg.Net.SASL.Enable = true
+	cfg.Net.SASL.User = c.SASL.Username
+	cfg.Net.SASL.Password = c.SASL.Password
+	if cfg.Version.IsAtLeast(sarama.V1_0_0_0) {
+		cfg.Net.SASL.Version = sarama.SASLHandshakeV1
+	}
+	switch c.SASL.Mechanism {
+	case SASLPlain:
+		cfg.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case SASLScramSHA256:
+		cfg.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		cfg.Net.SASL.SCRAMClientGeneratorFunc = newSCRAMClientGenerator(scram.SHA256)
+	case SASLScramSHA512:
+		cfg.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		cfg.Net.SASL.SCRAMClientGeneratorFunc = newSCRAMClientGenerator(scram.SHA512)
+	case SASLOAuthBearer:
+		provider := c.SASL.TokenProvider
+		if provider == nil && c.SASL.Token != "" {
+			token := c.SASL.Token
+			provider = func() (string, error) {
+				return token, nil
+			}
+		}
+		if provider == nil {
+			return ErrMissingAccessToken
+		}
+		cfg.Net.SASL.Mechanism = sarama.SASLTypeOAuth
+		cfg.Net.SASL.TokenProvider = tokenProvider(provider)
+	default:
+		return gluon.NewError("KafkaInvalidConfiguration",
+			"Unsupported SASL mechanism ("+string(c.SASL.Mechanism)+")", nil)
+	}
+	return nil
+}
+
+func (c Config) applyProducer(cfg *sarama.Config) error {
+	if c.Compression != "" {
+		if err := cfg.Producer.Compression.UnmarshalText([]byte(c.Compression)); err != nil {
+			return err
+		}
+	}
+	switch c.Acks {
+	case "":
+		if c.Idempotent {
+			cfg.Producer.RequiredAcks = sarama.WaitForAll
+		}
+	case AcksNone:
+		cfg.Producer.RequiredAcks = sarama.NoResponse
+	case AcksLeader:
+		cfg.Producer.RequiredAcks = sarama.WaitForLocal
+	case AcksAll:
+		cfg.Producer.RequiredAcks = sarama.WaitForAll
+	default:
+		return gluon.NewError("KafkaInvalidConfiguration", "Unsupported acks ("+string(c.Acks)+")", nil)
+	}
+	if c.Idempotent {
+		cfg.Producer.Idempotent = true
+		cfg.Net.MaxOpenRequests = 1
+	}
+	return nil
+}
+
+// tokenProvider Adapt a TokenProviderFunc to sarama.AccessTokenProvider.
+type tokenProvider TokenProviderFunc
+
+var _ sarama.AccessTokenProvider = tokenProvider(nil)
+
+func (p tokenProvider) Token() (*sarama.AccessToken, error) {
+	token, err := p()
+	if err != nil {
+		return nil, err
+	}
+	return &sarama.AccessToken{Token: token}, nil
+}
+
+// scramClient Adapt a github.com/xdg-go/scram client conversation to sarama.SCRAMClient.
+type scramClient struct {
+	hashGen      scram.HashGeneratorFcn
+	conversation *scram.ClientConversation
+}
+
+var _ sarama.SCRAMClient = &scramClient{}
+
+func newSCRAMClientGenerator(hashGen scram.HashGeneratorFcn) func() sarama.SCRAMClient {
+	return func() sarama.SCRAMClient {
+		return &scramClient{hashGen: hashGen}
+	}
+}
+
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	client, err := c.hashGen.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.conversation = client.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conversation.Step(challenge)
+}
+
+func (c *scramClient) Done() bool {
+	return c.conversation.Done()
+}
+
+// envLoader Read environment variables, keeping the first parsing error.
+type envLoader struct {
+	prefix string
+	err    error
+}
+
+func (l *envLoader) string(key string) string {
+	return strings.TrimSpace(os.Getenv(l.prefix + key))
+}
+
+func (l *envLoader) bool(key string) bool {
+	v := l.string(key)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	l.setError(key, err)
+	return b
+}
+
+func (l *envLoader) duration(key string) time.Duration {
+	v := l.string(key)
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	l.setError(key, err)
+	return d
+}
+
+func (l *envLoader) setError(key string, err error) {
+	if err != nil && l.err == nil {
+		l.err = gluon.NewError("KafkaInvalidConfiguration",
+			"Invalid environment variable ("+l.prefix+key+")", err)
+	}
+}
@@ -0,0 +1,243 @@
+package gkafka
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate Write a self-signed certificate and its private key as PEM files.
+func writeTestCertificate(t *testing.T) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gluon"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		0o600))
+	return certFile, keyFile
+}
+
+func TestConfig_NewSaramaConfig(t *testing.T) {
+	base := sarama.NewConfig()
+	base.ClientID = "base-client"
+	base.Producer.Retry.Max = 10
+
+	tests := []struct {
+		Name   string
+		Config Config
+		Base   *sarama.Config
+		Err    bool
+		Check  func(t *testing.T, cfg *sarama.Config)
+	}{
+		{
+			Name: "Defaults",
+			Check: func(t *testing.T, cfg *sarama.Config) {
+				assert.Equal(t, sarama.NewConfig().ClientID, cfg.ClientID)
+				assert.False(t, cfg.Net.SASL.Enable)
+				assert.False(t, cfg.Net.TLS.Enable)
+			},
+		},
+		{
+			Name:   "Base configuration",
+			Config: Config{ClientID: "foo-service"},
+			Base:   base,
+			Check: func(t *testing.T, cfg *sarama.Config) {
+				assert.Equal(t, "foo-service", cfg.ClientID)
+				assert.Equal(t, 10, cfg.Producer.Retry.Max)
+				assert.Equal(t, "base-client", base.ClientID)
+			},
+		},
+		{
+			Name:   "SASL PLAIN",
+			Config: Config{SASL: SASLConfig{Mechanism: SASLPlain, Username: "foo", Password: "bar"}},
+			Check: func(t *testing.T, cfg *sarama.Config) {
+				assert.True(t, cfg.Net.SASL.Enable)
+				assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypePlaintext), cfg.Net.SASL.Mechanism)
+				assert.Equal(t, sarama.SASLHandshakeV1, cfg.Net.SASL.Version)
+				assert.Equal(t, "foo", cfg.Net.SASL.User)
+				assert.Equal(t, "bar", cfg.Net.SASL.Password)
+			},
+		},
+		{
+			Name:   "SASL SCRAM-SHA-512",
+			Config: Config{SASL: SASLConfig{Mechanism: SASLScramSHA512, Username: "foo", Password: "bar"}},
+			Check: func(t *testing.T, cfg *sarama.Config) {
+				assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA512), cfg.Net.SASL.Mechanism)
+				require.NotNil(t, cfg.Net.SASL.SCRAMClientGeneratorFunc)
+				assert.IsType(t, &scramClient{}, cfg.Net.SASL.SCRAMClientGeneratorFunc())
+			},
+		},
+		{
+			Name:   "SASL OAUTHBEARER",
+			Config: Config{SASL: SASLConfig{Mechanism: SASLOAuthBearer, Token: "secret"}},
+			Check: func(t *testing.T, cfg *sarama.Config) {
+				assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypeOAuth), cfg.Net.SASL.Mechanism)
+				token, err := cfg.Net.SASL.TokenProvider.Token()
+				require.NoError(t, err)
+				assert.Equal(t, "secret", token.Token)
+			},
+		},
+		{
+			Name:   "SASL OAUTHBEARER without token",
+			Config: Config{SASL: SASLConfig{Mechanism: SASLOAuthBearer}},
+			Err:    true,
+		},
+		{
+			Name:   "Unsupported SASL mechanism",
+			Config: Config{SASL: SASLConfig{Mechanism: "GSSAPI"}},
+			Err:    true,
+		},
+		{
+			Name:   "TLS",
+			Config: Config{TLS: TLSConfig{Enabled: true, ServerName: "kafka.local"}},
+			Check: func(t *testing.T, cfg *sarama.Config) {
+				assert.True(t, cfg.Net.TLS.Enable)
+				assert.Equal(t, "kafka.local", cfg.Net.TLS.Config.ServerName)
+			},
+		},
+		{
+			Name:   "Missing CA file",
+			Config: Config{TLS: TLSConfig{CAFile: filepath.Join(t.TempDir(), "ca.pem")}},
+			Err:    true,
+		},
+		{
+			Name: "Producer",
+			Config: Config{
+				Version:     "2.8.0",
+				Compression: "zstd",
+				Acks:        AcksNone,
+			},
+			Check: func(t *testing.T, cfg *sarama.Config) {
+				assert.Equal(t, sarama.V2_8_0_0, cfg.Version)
+				assert.Equal(t, sarama.CompressionZSTD, cfg.Producer.Compression)
+				assert.Equal(t, sarama.NoResponse, cfg.Producer.RequiredAcks)
+			},
+		},
+		{
+			Name:   "Idempotent producer",
+			Config: Config{Idempotent: true},
+			Check: func(t *testing.T, cfg *sarama.Config) {
+				assert.True(t, cfg.Producer.Idempotent)
+				assert.Equal(t, sarama.WaitForAll, cfg.Producer.RequiredAcks)
+				assert.Equal(t, 1, cfg.Net.MaxOpenRequests)
+			},
+		},
+		{
+			Name:   "Idempotent producer without all acks",
+			Config: Config{Idempotent: true, Acks: AcksLeader},
+			Err:    true,
+		},
+		{
+			Name:   "Invalid compression",
+			Config: Config{Compression: "brotli"},
+			Err:    true,
+		},
+		{
+			Name:   "Invalid acks",
+			Config: Config{Acks: "some"},
+			Err:    true,
+		},
+		{
+			Name: "Consumer group",
+			Config: Config{
+				SessionTimeout:    time.Second * 30,
+				HeartbeatInterval: time.Second * 5,
+				RebalanceTimeout:  time.Minute,
+			},
+			Check: func(t *testing.T, cfg *sarama.Config) {
+				assert.Equal(t, time.Second*30, cfg.Consumer.Group.Session.Timeout)
+				assert.Equal(t, time.Second*5, cfg.Consumer.Group.Heartbeat.Interval)
+				assert.Equal(t, time.Minute, cfg.Consumer.Group.Rebalance.Timeout)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			cfg, err := tt.Config.newSaramaConfig(tt.Base)
+			if tt.Err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tt.Check(t, cfg)
+		})
+	}
+}
+
+func TestTLSConfig_NewTLSConfig(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+	cfg, err := TLSConfig{CAFile: certFile, CertFile: certFile, KeyFile: keyFile}.newTLSConfig()
+	require.NoError(t, err)
+	assert.NotNil(t, cfg.RootCAs)
+	assert.Len(t, cfg.Certificates, 1)
+
+	// files without certificates
+	_, err = TLSConfig{CAFile: keyFile}.newTLSConfig()
+	assert.Error(t, err)
+}
+
+func TestNewConfigFromEnv(t *testing.T) {
+	t.Setenv("KAFKA_BROKERS", "kafka-0:9092, kafka-1:9092,")
+	t.Setenv("KAFKA_CLIENT_ID", "foo-service")
+	t.Setenv("KAFKA_SASL_MECHANISM", "scram-sha-256")
+	t.Setenv("KAFKA_SASL_USERNAME", "foo")
+	t.Setenv("KAFKA_SASL_PASSWORD", "bar")
+	t.Setenv("KAFKA_TLS_ENABLED", "true")
+	t.Setenv("KAFKA_ACKS", "ALL")
+	t.Setenv("KAFKA_SESSION_TIMEOUT", "45s")
+	cfg, err := NewConfigFromEnv("KAFKA_")
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Brokers:        []string{"kafka-0:9092", "kafka-1:9092"},
+		ClientID:       "foo-service",
+		SASL:           SASLConfig{Mechanism: SASLScramSHA256, Username: "foo", Password: "bar"},
+		TLS:            TLSConfig{Enabled: true},
+		Acks:           AcksAll,
+		SessionTimeout: time.Second * 45,
+	}, cfg)
+
+	t.Setenv("KAFKA_IDEMPOTENT", "maybe")
+	_, err = NewConfigFromEnv("KAFKA_")
+	assert.Error(t, err)
+}
+
+func TestDriver_SetParentBus_Config(t *testing.T) {
+	d := &driver{}
+	d.SetParentBus(gluon.NewBus(DriverName, gluon.WithCluster("localhost:9092"),
+		gluon.WithDriverConfiguration(Config{Brokers: []string{"kafka:9092"}, ClientID: "foo-service"})))
+	require.NoError(t, d.configErr)
+	assert.Equal(t, []string{"kafka:9092"}, d.addrs)
+	assert.Equal(t, "foo-service", d.config.ClientID)
+
+	d.SetParentBus(gluon.NewBus(DriverName, gluon.WithCluster("localhost:9092"),
+		gluon.WithDriverConfiguration(Configuration{Client: &Config{Acks: "some"}})))
+	assert.Equal(t, []string{"localhost:9092"}, d.addrs)
+	assert.Error(t, d.Start(context.Background()))
+	assert.Error(t, d.Provision(context.Background()))
+}
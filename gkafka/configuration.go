@@ -0,0 +1,116 @@
+package gkafka
+
+import (
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// Configuration Is the Apache Kafka driver configuration. It may be set using gluon.WithDriverConfiguration.
+//
+// A Config or a *sarama.Config are accepted as well.
+type Configuration struct {
+	// Client Apache Kafka client configuration (optional).
+	Client *Config
+	// Config Sarama client configuration (optional). If Client is set as well, Client settings are applied over a
+	// copy of Config, so Config may be used to tune settings not covered by Client.
+	Config *sarama.Config
+	// KeyStrategy Generate the record key of produced messages (PartitionKeyStrategy by default).
+	KeyStrategy KeyStrategy
+
+	// Async Produce messages in background. Publishing operations return once messages are enqueued, so failures
+	// are only reported through OnDelivery (or logged if OnDelivery is nil).
+	Async bool
+	// BatchSize Number of buffered messages which triggers a flush to the brokers.
+	BatchSize int
+	// BatchBytes Number of buffered bytes which triggers a flush to the brokers.
+	BatchBytes int
+	// Linger Maximum waiting time of buffered messages before they are flushed to the brokers.
+	Linger time.Duration
+	// OnDelivery Receive the delivery report of every produced message (optional).
+	OnDelivery DeliveryReportFunc
+
+	// TransactionalID Enable exactly-once consume-transform-produce processing for consumer groups.
+	//
+	// Every consumed message is handled within a Kafka transaction. Messages published using the handler context
+	// and the consumed offset are committed atomically once the handler succeeds. The value is used as prefix of
+	// the transactional ID of every claimed partition. Consumer groups read committed messages only. Every handler
+	// retry (gluon.RetryPolicy) runs within a new transaction.
+	TransactionalID string
+
+	// Topics Enable topic provisioning. Missing topics required by the Bus are created once it starts (optional).
+	Topics *TopicConfiguration
+}
+
+// getBrokers Retrieve the addresses of the bootstrap brokers, Client brokers take precedence over the Bus cluster
+// addresses.
+func (c Configuration) getBrokers(addrs []string) []string {
+	if c.Client != nil && len(c.Client.Brokers) > 0 {
+		return c.Client.Brokers
+	}
+	return addrs
+}
+
+// GetKeyStrategy Retrieve the strategy used to generate record keys.
+func (c Configuration) GetKeyStrategy() KeyStrategy {
+	if c.KeyStrategy == nil {
+		return PartitionKeyStrategy
+	}
+	return c.KeyStrategy
+}
+
+// newProducerConfig Allocate the sarama configuration of the driver producer.
+func (c Configuration) newProducerConfig(base *sarama.Config) *sarama.Config {
+	cfg := sarama.NewConfig()
+	if base != nil {
+		copied := *base
+		cfg = &copied
+	}
+	// required by sarama.SyncProducer and delivery reports
+	cfg.Producer.Return.Successes = true
+	cfg.Producer.Return.Errors = true
+	if c.BatchSize > 0 {
+		cfg.Producer.Flush.Messages = c.BatchSize
+	}
+	if c.BatchBytes > 0 {
+		cfg.Producer.Flush.Bytes = c.BatchBytes
+	}
+	if c.Linger > 0 {
+		cfg.Producer.Flush.Frequency = c.Linger
+	}
+	return cfg
+}
+
+// newTransactionalConfig Allocate the sarama configuration of a transactional producer.
+func (c Configuration) newTransactionalConfig(base *sarama.Config, transactionalID string) *sarama.Config {
+	cfg := c.newProducerConfig(base)
+	cfg.Producer.Transaction.ID = transactionalID
+	cfg.Producer.Idempotent = true
+	cfg.Producer.RequiredAcks = sarama.WaitForAll
+	cfg.Net.MaxOpenRequests = 1
+	return cfg
+}
+
+// newConsumerConfig Allocate the sarama configuration of a subscriber consumer group.
+func (c Configuration) newConsumerConfig(base *sarama.Config, consumerCfg ConsumerConfiguration) *sarama.Config {
+	cfg := sarama.NewConfig()
+	if base != nil {
+		copied := *base
+		cfg = &copied
+	}
+	if c.TransactionalID != "" {
+		// offsets are committed by transactions
+		cfg.Consumer.IsolationLevel = sarama.ReadCommitted
+		cfg.Consumer.Offsets.AutoCommit.Enable = false
+		return cfg
+	}
+	switch consumerCfg.CommitMode {
+	case CommitSync:
+		cfg.Consumer.Offsets.AutoCommit.Enable = false
+	default:
+		if consumerCfg.CommitInterval > 0 {
+			cfg.Consumer.Offsets.AutoCommit.Interval = consumerCfg.CommitInterval
+		}
+	}
+	return cfg
+}
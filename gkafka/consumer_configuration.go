@@ -1,5 +1,66 @@
 package gkafka
 
+import (
+	"context"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+)
+
+// CommitMode Is the strategy used by consumer groups to commit marked offsets.
+type CommitMode int
+
+const (
+	// CommitPeriodic Marked offsets are committed in background every ConsumerConfiguration.CommitInterval (or the
+	// sarama configured interval). This is the default commit mode.
+	CommitPeriodic CommitMode = iota
+	// CommitSync Marked offsets are committed synchronously, right after a message is marked.
+	CommitSync
+)
+
+// PartitionsHookFunc Is a callback used to notify consumer group rebalances. Partitions are grouped by topic.
+type PartitionsHookFunc func(ctx context.Context, partitions map[string][]int32) error
+
+// ConsumerConfiguration Is the subscriber-specific configuration, set using gluon.Subscriber.DriverConfiguration.
 type ConsumerConfiguration struct {
+	// Partitions Partitions consumed by subscribers without consumer group. Defaults to every partition of the topic.
+	Partitions []int32
+	// PartitionID Partition consumed by subscribers without consumer group.
+	//
+	// Deprecated: Use Partitions instead. A non-zero value consumes only the given partition.
 	PartitionID int32
+	// Concurrency Number of workers handling messages of subscribers without consumer group. Messages with the same
+	// record key are handled by the same worker to preserve their order, messages without key are distributed by
+	// partition. Defaults to a single worker per partition.
+	Concurrency int
+	// StartPosition Position where subscribers without consumer group start consuming (e.g. PositionAtTime to replay
+	// a topic). Defaults to the sarama configured initial offset.
+	StartPosition Position
+
+	// OnPartitionsAssigned Is called once partitions are assigned to the consumer group member, before messages are
+	// consumed. Returning an error cancels the consumer group session.
+	OnPartitionsAssigned PartitionsHookFunc
+	// OnPartitionsRevoked Is called before partitions are revoked from the consumer group member, once every message
+	// being handled was processed (e.g. to flush state). The given context expires after 30 seconds.
+	OnPartitionsRevoked PartitionsHookFunc
+	// ManualCommit Offsets are marked only when handlers acknowledge messages using Ack. Otherwise, messages are
+	// marked once the handler succeeds.
+	//
+	// As offsets are committed per partition, acknowledging a message implicitly acknowledges previous messages.
+	// Not supported by consumer groups while Configuration.TransactionalID is set, as offsets are committed along
+	// each transaction.
+	ManualCommit bool
+	// CommitMode Strategy used to commit marked offsets (CommitPeriodic by default).
+	CommitMode CommitMode
+	// CommitInterval Waiting time between commits using CommitPeriodic mode (optional).
+	CommitInterval time.Duration
+}
+
+func getConsumerConfiguration(sub *gluon.Subscriber) ConsumerConfiguration {
+	if cfg, ok := sub.GetDriverConfiguration().(ConsumerConfiguration); ok {
+		// If we try to cast the driver config without `ok` safety mechanism, program will panic.
+		// Hence, this condition is required.
+		return cfg
+	}
+	return ConsumerConfiguration{}
 }
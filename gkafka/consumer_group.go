@@ -2,37 +2,67 @@ package gkafka
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/neutrinocorp/gluon"
 )
 
+// consumeRetryDelay Waiting time before a consumer group joins again after a failed session.
+const consumeRetryDelay = time.Second
+
 type consumerGroup struct {
 	parentDriver *driver
 	group        string
 
 	consumerGroupInternal sarama.ConsumerGroup
+	cancel                context.CancelFunc
+	wg                    sync.WaitGroup
 }
 
 func (s *consumerGroup) consume(ctx context.Context, sub *gluon.Subscriber) error {
 	var err error
-	s.consumerGroupInternal, err = sarama.NewConsumerGroup(s.parentDriver.parentBus.Addresses, s.group, s.parentDriver.config)
+	s.consumerGroupInternal, err = sarama.NewConsumerGroup(s.parentDriver.addrs, s.group,
+		s.parentDriver.driverConfig.newConsumerConfig(s.parentDriver.config, getConsumerConfiguration(sub)))
 	if err != nil {
 		return err
 	}
 
 	s.logErrorStream(s.consumerGroupInternal)
+	ctx, s.cancel = context.WithCancel(ctx)
+	handler := newInternalConsumerGroup(s.parentDriver, sub, s.group)
+	s.wg.Add(1)
 	go func() {
+		defer s.wg.Done()
+		// Consume returns on every rebalance, so the consumer group must join again until it is closed
 		for {
-			_ = s.consumerGroupInternal.
-				Consume(ctx, []string{sub.GetTopic()}, newInternalConsumerGroup(s.parentDriver, sub))
+			err := s.consumerGroupInternal.Consume(ctx, []string{sub.GetTopic()}, handler)
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) || ctx.Err() != nil {
+				return
+			} else if err == nil {
+				continue
+			}
+			s.logError(err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(consumeRetryDelay):
+			}
 		}
 	}()
 	return nil
 }
 
+// close Stop the consume loop and leave the consumer group, committing marked offsets.
 func (s *consumerGroup) close() error {
-	return s.consumerGroupInternal.Close()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	err := s.consumerGroupInternal.Close()
+	s.wg.Wait()
+	return err
 }
 
 func (s *consumerGroup) logErrorStream(group sarama.ConsumerGroup) {
@@ -44,3 +74,9 @@ func (s *consumerGroup) logErrorStream(group sarama.ConsumerGroup) {
 		}()
 	}
 }
+
+func (s *consumerGroup) logError(err error) {
+	if s.parentDriver.isLoggingEnabled() {
+		s.parentDriver.parentBus.Logger.Print(err)
+	}
+}
@@ -2,6 +2,8 @@ package gkafka
 
 import (
 	"context"
+	"hash/fnv"
+	"sync"
 
 	"github.com/hashicorp/go-multierror"
 
@@ -10,53 +12,133 @@ import (
 	"github.com/neutrinocorp/gluon"
 )
 
+// workerQueueSize Number of messages buffered by each worker of a consumerShard.
+const workerQueueSize = 256
+
+// consumerShard Consume the partitions of a topic without consumer group (no offsets are committed).
+//
+// Every partition is consumed by its own goroutine. If ConsumerConfiguration.Concurrency is set, messages are
+// dispatched to a pool of workers using the record key (or the partition), so order is preserved per key.
 type consumerShard struct {
 	parentDriver *driver
+	// ctx Is the handler base context, cancelled once the consumer is closed so retries stop.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	client     sarama.Client
+	consumer   sarama.Consumer
+	partitions []sarama.PartitionConsumer
+	workers    []chan *sarama.ConsumerMessage
 
-	consumer  sarama.Consumer
-	partition sarama.PartitionConsumer
+	partitionsWg sync.WaitGroup
+	workersWg    sync.WaitGroup
 }
 
 var _ consumerStrategy = &consumerShard{}
 
 func (c *consumerShard) consume(ctx context.Context, sub *gluon.Subscriber) error {
 	var err error
-	c.consumer, err = sarama.NewConsumer(c.parentDriver.parentBus.Addresses, c.parentDriver.config)
+	c.client, err = sarama.NewClient(c.parentDriver.addrs, c.parentDriver.config)
 	if err != nil {
 		return err
 	}
-
-	partitionID := c.getDefaultPartitionID(sub)
-	c.partition, err = c.consumer.ConsumePartition(sub.GetTopic(), partitionID, c.getDefaultPartitionOffset())
+	c.consumer, err = sarama.NewConsumerFromClient(c.client)
 	if err != nil {
 		return err
 	}
+	return c.start(ctx, sub)
+}
 
-	go func() {
-		for kMsg := range c.partition.Messages() {
-			scopedCtx := context.TODO()
-			msg := new(gluon.TransportMessage)
-			unmarshalKafkaMessage(kMsg, msg)
-			_ = c.parentDriver.messageHandler(scopedCtx, sub, msg)
+// start Consume the partitions of the subscriber topic using the underlying sarama.Consumer.
+func (c *consumerShard) start(ctx context.Context, sub *gluon.Subscriber) error {
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	cfg := getConsumerConfiguration(sub)
+	partitionIDs, err := c.getPartitionIDs(sub.GetTopic(), cfg)
+	if err != nil {
+		return err
+	}
+	c.startWorkers(sub, cfg.Concurrency)
+	for _, partitionID := range partitionIDs {
+		offset, errOffset := c.getDefaultPartitionOffset(sub, partitionID)
+		if errOffset != nil {
+			return errOffset
+		}
+		partition, errConsume := c.consumer.ConsumePartition(sub.GetTopic(), partitionID, offset)
+		if errConsume != nil {
+			return errConsume
 		}
-	}()
+		c.partitions = append(c.partitions, partition)
+		c.logErrorStream(partition)
+		c.partitionsWg.Add(1)
+		go c.consumePartition(sub, partition)
+	}
 	return nil
 }
 
-func (c *consumerShard) getDefaultPartitionID(sub *gluon.Subscriber) int32 {
-	if cfg, ok := sub.GetDriverConfiguration().(ConsumerConfiguration); ok {
-		// If we try to cast the driver config without `ok` safety mechanism, program will panic.
-		// Hence, this condition is required.
-		return cfg.PartitionID
+func (c *consumerShard) consumePartition(sub *gluon.Subscriber, partition sarama.PartitionConsumer) {
+	defer c.partitionsWg.Done()
+	for kMsg := range partition.Messages() {
+		c.parentDriver.reportConsumerLag(kMsg, "", partition.HighWaterMarkOffset())
+		if len(c.workers) == 0 {
+			c.handle(sub, kMsg)
+			continue
+		}
+		c.workers[c.getWorkerIndex(kMsg)] <- kMsg
+	}
+}
+
+func (c *consumerShard) startWorkers(sub *gluon.Subscriber, concurrency int) {
+	c.workers = make([]chan *sarama.ConsumerMessage, 0, concurrency)
+	for i := 0; i < concurrency; i++ {
+		worker := make(chan *sarama.ConsumerMessage, workerQueueSize)
+		c.workers = append(c.workers, worker)
+		c.workersWg.Add(1)
+		go func() {
+			defer c.workersWg.Done()
+			for kMsg := range worker {
+				c.handle(sub, kMsg)
+			}
+		}()
+	}
+}
+
+// getWorkerIndex Retrieve the worker of a message using its record key or, if missing, its partition.
+func (c *consumerShard) getWorkerIndex(kMsg *sarama.ConsumerMessage) int {
+	if len(kMsg.Key) == 0 {
+		return int(kMsg.Partition) % len(c.workers)
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(kMsg.Key)
+	return int(h.Sum32() % uint32(len(c.workers)))
+}
+
+func (c *consumerShard) handle(sub *gluon.Subscriber, kMsg *sarama.ConsumerMessage) {
+	msg := new(gluon.TransportMessage)
+	if err := unmarshalKafkaMessage(kMsg, msg); err != nil {
+		c.logError(err)
+		return
+	}
+	_ = c.parentDriver.messageHandler(c.ctx, sub, msg)
+}
+
+// getPartitionIDs Retrieve the partitions to consume, every partition of the topic if none were configured.
+func (c *consumerShard) getPartitionIDs(topic string, cfg ConsumerConfiguration) ([]int32, error) {
+	if len(cfg.Partitions) > 0 {
+		return cfg.Partitions, nil
+	} else if cfg.PartitionID != 0 {
+		return []int32{cfg.PartitionID}, nil
 	}
-	return 0
+	return c.consumer.Partitions(topic)
 }
 
-func (c *consumerShard) getDefaultPartitionOffset() int64 {
+func (c *consumerShard) getDefaultPartitionOffset(sub *gluon.Subscriber, partition int32) (int64, error) {
+	if pos := getConsumerConfiguration(sub).StartPosition; !pos.IsZero() {
+		return resolveOffset(c.client, sub.GetTopic(), partition, pos)
+	}
 	if c.parentDriver.config != nil {
-		return c.parentDriver.config.Consumer.Offsets.Initial
+		return c.parentDriver.config.Consumer.Offsets.Initial, nil
 	}
-	return sarama.OffsetNewest
+	return sarama.OffsetNewest, nil
 }
 
 func (c *consumerShard) logErrorStream(consumer sarama.PartitionConsumer) {
@@ -75,13 +157,31 @@ func (c *consumerShard) logError(err error) {
 	}
 }
 
+// close Stop consuming partitions, waiting for workers to handle dispatched messages.
 func (c *consumerShard) close() error {
 	errs := new(multierror.Error)
-	if err := c.consumer.Close(); err != nil {
-		errs = multierror.Append(err, errs)
+	if c.cancel != nil {
+		c.cancel()
+	}
+	for _, partition := range c.partitions {
+		if err := partition.Close(); err != nil {
+			errs = multierror.Append(err, errs)
+		}
 	}
-	if err := c.partition.Close(); err != nil {
-		errs = multierror.Append(err, errs)
+	c.partitionsWg.Wait()
+	for _, worker := range c.workers {
+		close(worker)
+	}
+	c.workersWg.Wait()
+	if c.consumer != nil {
+		if err := c.consumer.Close(); err != nil {
+			errs = multierror.Append(err, errs)
+		}
+	}
+	if c.client != nil {
+		if err := c.client.Close(); err != nil {
+			errs = multierror.Append(err, errs)
+		}
 	}
 	return errs.ErrorOrNil()
 }
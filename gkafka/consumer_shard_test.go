@@ -0,0 +1,118 @@
+package gkafka
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumerShard_Start(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Config         ConsumerConfiguration
+		WantPartitions []int32
+	}{
+		{
+			Name:           "All partitions",
+			WantPartitions: []int32{0, 1, 2},
+		},
+		{
+			Name:           "Partition subset",
+			Config:         ConsumerConfiguration{Partitions: []int32{1, 2}},
+			WantPartitions: []int32{1, 2},
+		},
+		{
+			Name:           "Legacy partition",
+			Config:         ConsumerConfiguration{PartitionID: 2},
+			WantPartitions: []int32{2},
+		},
+		{
+			Name:           "Keyed workers",
+			Config:         ConsumerConfiguration{Concurrency: 4},
+			WantPartitions: []int32{0, 1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			const msgsPerPartition = 20
+			var (
+				mu   sync.Mutex
+				wg   sync.WaitGroup
+				got  = map[string][]int64{}
+				want = map[string][]int64{}
+			)
+			wg.Add(len(tt.WantPartitions) * msgsPerPartition)
+			d := newTestDriver(Configuration{})
+			d.SetInternalHandler(func(_ context.Context, _ *gluon.Subscriber, msg *gluon.TransportMessage) error {
+				defer wg.Done()
+				offset, err := strconv.ParseInt(msg.DriverHeaders[HeaderOffset], 10, 64)
+				require.NoError(t, err)
+				mu.Lock()
+				defer mu.Unlock()
+				key := msg.DriverHeaders[HeaderPartition] + "/" + msg.DriverHeaders[HeaderKey]
+				got[key] = append(got[key], offset)
+				return nil
+			})
+
+			consumer := mocks.NewConsumer(t, nil)
+			consumer.SetTopicMetadata(map[string][]int32{"foo.topic": {0, 1, 2}})
+			for _, partition := range tt.WantPartitions {
+				pc := consumer.ExpectConsumePartition("foo.topic", partition, sarama.OffsetNewest)
+				for i := 0; i < msgsPerPartition; i++ {
+					kMsg := newTestConsumerMessage(t, 0, `{}`)
+					kMsg.Key = []byte("key-" + strconv.Itoa(i%3))
+					pc.YieldMessage(kMsg) // assigns the partition offset
+					key := strconv.Itoa(int(partition)) + "/" + string(kMsg.Key)
+					want[key] = append(want[key], kMsg.Offset)
+				}
+			}
+
+			shard := &consumerShard{parentDriver: d, consumer: consumer}
+			sub := gluon.NewBus(DriverName).SubscribeTopic("foo.topic").DriverConfiguration(tt.Config)
+			require.NoError(t, shard.start(context.Background(), sub))
+			wg.Wait()
+			require.NoError(t, shard.close())
+
+			// messages are handled in order per partition and record key
+			assert.Len(t, got, len(tt.WantPartitions)*3)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestConsumerShard_CloseCancelsHandlers(t *testing.T) {
+	started := make(chan struct{})
+	d := newTestDriver(Configuration{})
+	d.SetInternalHandler(func(ctx context.Context, _ *gluon.Subscriber, _ *gluon.TransportMessage) error {
+		close(started)
+		// e.g. waiting for a retry backoff
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	consumer := mocks.NewConsumer(t, nil)
+	consumer.SetTopicMetadata(map[string][]int32{"foo.topic": {0}})
+	consumer.ExpectConsumePartition("foo.topic", 0, sarama.OffsetNewest).
+		YieldMessage(newTestConsumerMessage(t, 0, `{}`))
+
+	shard := &consumerShard{parentDriver: d, consumer: consumer}
+	require.NoError(t, shard.start(context.Background(), gluon.NewBus(DriverName).SubscribeTopic("foo.topic")))
+	<-started
+	closed := make(chan error, 1)
+	go func() {
+		closed <- shard.close()
+	}()
+	select {
+	case err := <-closed:
+		assert.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("consumer shard did not stop its handlers")
+	}
+}
@@ -10,39 +10,115 @@ import (
 	"github.com/neutrinocorp/gluon"
 )
 
+// DriverName Is the name used to register the Apache Kafka driver.
+const DriverName = "kafka"
+
 type driver struct {
 	parentBus      *gluon.Bus
 	messageHandler gluon.InternalMessageHandler
 	config         *sarama.Config
+	configErr      error
+	driverConfig   Configuration
+	addrs          []string
 
 	consumers []consumerStrategy
+
+	mu       sync.RWMutex
+	producer producer
 }
 
 var (
-	_               gluon.Driver = &driver{}
-	defaultDriver   *driver
-	driverSingleton = sync.Once{}
+	_ gluon.Driver         = &driver{}
+	_ gluon.BatchPublisher = &driver{}
+	_ gluon.Provisioner    = &driver{}
 )
 
 func init() {
-	driverSingleton.Do(func() {
-		defaultDriver = &driver{}
-		gluon.Register("kafka", defaultDriver)
+	gluon.Register(DriverName, func() gluon.Driver {
+		return &driver{}
 	})
 }
 
 func (d *driver) SetParentBus(b *gluon.Bus) {
 	d.parentBus = b
-	if cfg, ok := b.Configuration.Driver.(*sarama.Config); ok {
-		d.config = cfg
+	d.driverConfig, d.config, d.configErr = parseDriverConfiguration(b.Configuration.Driver)
+	d.addrs = d.driverConfig.getBrokers(b.Addresses)
+}
+
+// parseDriverConfiguration Retrieve the driver configuration and the sarama configuration set using
+// gluon.WithDriverConfiguration.
+func parseDriverConfiguration(v interface{}) (Configuration, *sarama.Config, error) {
+	var driverCfg Configuration
+	switch cfg := v.(type) {
+	case Configuration:
+		driverCfg = cfg
+	case Config:
+		driverCfg = Configuration{Client: &cfg}
+	case *sarama.Config:
+		driverCfg = Configuration{Config: cfg}
+	}
+	if driverCfg.Client == nil {
+		return driverCfg, driverCfg.Config, nil
 	}
+	cfg, err := driverCfg.Client.newSaramaConfig(driverCfg.Config)
+	return driverCfg, cfg, err
 }
 
 func (d *driver) SetInternalHandler(h gluon.InternalMessageHandler) {
 	d.messageHandler = h
 }
 
+// Provision Create the missing topics required by the Bus (gluon.Bus.Topics) if topic provisioning is enabled
+// (Configuration.Topics). Drifts of existing topics are logged.
+func (d *driver) Provision(ctx context.Context) error {
+	if d.configErr != nil || d.driverConfig.Topics == nil {
+		return d.configErr
+	}
+	a, err := newAdmin(d.addrs, d.config)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = a.Close()
+	}()
+	drifts, err := a.ProvisionTopics(ctx, *d.driverConfig.Topics, d.parentBus.Topics()...)
+	if err != nil {
+		return err
+	}
+	if d.isLoggingEnabled() {
+		for _, drift := range drifts {
+			d.parentBus.Logger.Print(gluon.NewError("TopicDrift", drift.String(), ErrTopicDrift))
+		}
+	}
+	if len(drifts) > 0 && d.driverConfig.Topics.FailOnDrift {
+		return ErrTopicDrift
+	}
+	return nil
+}
+
 func (d *driver) Start(_ context.Context) error {
+	if d.configErr != nil {
+		return d.configErr
+	}
+	cfg := d.driverConfig.newProducerConfig(d.config)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.driverConfig.Async {
+		prod, err := sarama.NewAsyncProducer(d.addrs, cfg)
+		if err != nil {
+			return err
+		}
+		d.producer = newAsyncProducer(d, prod)
+		return nil
+	}
+	prod, err := sarama.NewSyncProducer(d.addrs, cfg)
+	if err != nil {
+		return err
+	}
+	d.producer = &syncProducer{
+		parentDriver: d,
+		producer:     prod,
+	}
 	return nil
 }
 
@@ -54,20 +130,53 @@ func (d *driver) Shutdown(_ context.Context) error {
 		}
 	}
 
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.producer != nil {
+		if err := d.producer.close(); err != nil {
+			errs = multierror.Append(err, errs)
+		}
+		d.producer = nil
+	}
 	return errs.ErrorOrNil()
 }
 
-func (d *driver) Publish(_ context.Context, message *gluon.TransportMessage) error {
-	prod, err := sarama.NewSyncProducer(d.parentBus.Addresses, d.config)
-	if err != nil {
-		return err
+func (d *driver) Publish(ctx context.Context, message *gluon.TransportMessage) error {
+	return d.PublishBatch(ctx, []*gluon.TransportMessage{message})
+}
+
+// PublishBatch Propagate a batch of messages using a single producer operation.
+//
+// If ctx is a handler context of a transactional consumer group (IsTransactional), messages are produced within the
+// transaction of the message being handled.
+func (d *driver) PublishBatch(ctx context.Context, messages []*gluon.TransportMessage) error {
+	kMsgs := make([]*sarama.ProducerMessage, 0, len(messages))
+	for _, message := range messages {
+		kMsg, err := marshalKafkaMessage(message, d.parentBus.Configuration.ContentMode,
+			d.driverConfig.GetKeyStrategy())
+		if err != nil {
+			return err
+		}
+		kMsg.Metadata = message
+		kMsgs = append(kMsgs, kMsg)
 	}
 
-	_, _, err = prod.SendMessage(marshalKafkaMessage(message))
-	if err != nil {
-		return err
+	if txn := getTransaction(ctx); txn != nil && txn.parentDriver == d {
+		return txn.send(ctx, kMsgs...)
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.producer == nil {
+		return ErrProducerClosed
+	}
+	return d.producer.send(ctx, kMsgs...)
+}
+
+// reportDelivery Execute the OnDelivery callback, if any.
+func (d *driver) reportDelivery(report DeliveryReport) {
+	if d.driverConfig.OnDelivery != nil {
+		d.driverConfig.OnDelivery(report)
 	}
-	return prod.Close()
 }
 
 func (d *driver) Subscribe(ctx context.Context, subscriber *gluon.Subscriber) error {
@@ -75,11 +184,24 @@ func (d *driver) Subscribe(ctx context.Context, subscriber *gluon.Subscriber) er
 	if g := subscriber.GetGroup(); g != "" {
 		groupStr = g // specified consumer group over global consumer group
 	}
+	if groupStr != "" && d.driverConfig.TransactionalID != "" && getConsumerConfiguration(subscriber).ManualCommit {
+		return gluon.NewError("KafkaInvalidConfiguration",
+			"ManualCommit is not supported by transactional consumer groups", nil)
+	}
 	consumer := newConsumerStrategy(d, groupStr)
 	d.consumers = append(d.consumers, consumer)
 	return consumer.consume(ctx, subscriber)
 }
 
+// reportConsumerLag Report the number of messages the consumer is behind the latest message of a partition.
+func (d *driver) reportConsumerLag(kMsg *sarama.ConsumerMessage, group string, highWaterMark int64) {
+	lag := highWaterMark - kMsg.Offset - 1
+	if lag < 0 {
+		lag = 0
+	}
+	d.parentBus.Configuration.DriverHooks.ReportConsumerLag(DriverName, kMsg.Topic, group, kMsg.Partition, lag)
+}
+
 func (d *driver) isLoggingEnabled() bool {
 	return true
 }
@@ -4,17 +4,26 @@ package gkafka
 const (
 	HeaderOffset    = "kafka-offset"
 	HeaderPartition = "kafka-partition"
+	HeaderKey       = "kafka-key"
 
-	// Internal Kafka message headers
+	// Internal Kafka message headers, following the CloudEvents Kafka protocol binding
 
-	headerMessageID     = "ce_id"
-	headerSource        = "ce_source"
-	headerSpecVersion   = "ce_specversion"
-	headerMessageType   = "ce_type"
-	headerMessageTime   = "ce_time"
-	headerContentType   = "content_type"
-	headerSchema        = "schema"
-	headerSubject       = "subject"
-	headerCorrelationID = "gl_correlation_id"
-	headerCausationID   = "gl_causation_id"
+	headerMessageID   = "ce_id"
+	headerSource      = "ce_source"
+	headerSpecVersion = "ce_specversion"
+	headerMessageType = "ce_type"
+	headerMessageTime = "ce_time"
+	headerContentType = "content-type"
+	headerSchema      = "ce_dataschema"
+	headerSubject     = "ce_subject"
+	// headerExtensionPrefix is used to carry CloudEvents extension attributes (e.g. ce_tenantid, ce_correlationid)
+	headerExtensionPrefix = "ce_"
+
+	// Headers used by previous versions of `Gluon`, only read to interoperate with older producers
+
+	legacyHeaderContentType   = "content_type"
+	legacyHeaderSchema        = "schema"
+	legacyHeaderSubject       = "subject"
+	legacyHeaderCorrelationID = "gl_correlation_id"
+	legacyHeaderCausationID   = "gl_causation_id"
 )
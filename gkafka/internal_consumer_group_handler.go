@@ -2,42 +2,116 @@ package gkafka
 
 import (
 	"context"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/neutrinocorp/gluon"
 )
 
+// partitionsRevokedTimeout Maximum duration of ConsumerConfiguration.OnPartitionsRevoked calls.
+const partitionsRevokedTimeout = time.Second * 30
+
 type internalConsumerGroupHandler struct {
 	parentDriver *driver
 	sub          *gluon.Subscriber
+	group        string
+	config       ConsumerConfiguration
 }
 
 var _ sarama.ConsumerGroupHandler = &internalConsumerGroupHandler{}
 
-func newInternalConsumerGroup(d *driver, s *gluon.Subscriber) *internalConsumerGroupHandler {
+func newInternalConsumerGroup(d *driver, s *gluon.Subscriber, group string) *internalConsumerGroupHandler {
 	return &internalConsumerGroupHandler{
 		parentDriver: d,
 		sub:          s,
+		group:        group,
+		config:       getConsumerConfiguration(s),
 	}
 }
 
-func (i *internalConsumerGroupHandler) Setup(_ sarama.ConsumerGroupSession) error {
-	return nil
+func (i *internalConsumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	if i.config.OnPartitionsAssigned == nil {
+		return nil
+	}
+	return i.config.OnPartitionsAssigned(session.Context(), session.Claims())
 }
 
-func (i *internalConsumerGroupHandler) Cleanup(_ sarama.ConsumerGroupSession) error {
-	return nil
+func (i *internalConsumerGroupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	if i.config.OnPartitionsRevoked == nil {
+		return nil
+	}
+	// the session context is already cancelled once partitions are revoked
+	ctx, cancel := context.WithTimeout(context.Background(), partitionsRevokedTimeout)
+	defer cancel()
+	return i.config.OnPartitionsRevoked(ctx, session.Claims())
 }
 
 func (i *internalConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 	claim sarama.ConsumerGroupClaim) error {
+	if i.parentDriver.driverConfig.TransactionalID != "" {
+		txnProducer, err := newTransactionalProducer(i.parentDriver, i.group, claim.Topic(), claim.Partition())
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = txnProducer.close()
+		}()
+		return i.consumeClaimTransactional(session.Context(), claim, txnProducer)
+	}
+
 	for kMsg := range claim.Messages() {
-		scopedCtx := context.TODO()
+		i.parentDriver.reportConsumerLag(kMsg, i.group, claim.HighWaterMarkOffset())
+		msgAck := &messageAck{
+			session:    session,
+			kMsg:       kMsg,
+			syncCommit: i.config.CommitMode == CommitSync,
+		}
 		msg := new(gluon.TransportMessage)
-		unmarshalKafkaMessage(kMsg, msg)
+		if err := unmarshalKafkaMessage(kMsg, msg); err != nil {
+			// messages which cannot be decoded would block the partition forever
+			i.logError(err)
+			msgAck.ack()
+			continue
+		}
+		// retries (gluon.RetryPolicy) stop once the claim is revoked
+		scopedCtx := withMessageAck(session.Context(), msgAck)
 		err := i.parentDriver.messageHandler(scopedCtx, i.sub, msg)
-		if err == nil {
-			session.MarkMessage(kMsg, "")
+		if err == nil && !i.config.ManualCommit {
+			msgAck.ack()
+		}
+	}
+	return nil
+}
+
+// consumeClaimTransactional Handle every message of a claim within a transaction.
+//
+// Like non-transactional consumers, offsets of messages which failed to be handled are committed along the next
+// transaction (use gluon.RetryPolicy and gluon.DeadLetterPolicy to recover them). If a transaction cannot be
+// committed, the claim is released so the consumer group session ends and messages are consumed again from the last
+// committed offset.
+//
+// Each handler execution (gluon.RetryPolicy attempt) gets its own transaction, so messages published by failed
+// attempts are aborted before the handler is retried or the message is routed to a dead-letter topic.
+func (i *internalConsumerGroupHandler) consumeClaimTransactional(ctx context.Context, claim sarama.ConsumerGroupClaim,
+	txnProducer *transactionalProducer) error {
+	for kMsg := range claim.Messages() {
+		i.parentDriver.reportConsumerLag(kMsg, i.group, claim.HighWaterMarkOffset())
+		if err := txnProducer.begin(); err != nil {
+			return err
+		}
+		msg := new(gluon.TransportMessage)
+		if err := unmarshalKafkaMessage(kMsg, msg); err != nil {
+			// messages which cannot be decoded would block the partition forever
+			i.logError(err)
+		} else if err = i.parentDriver.messageHandler(newTransactionContext(ctx, txnProducer), i.sub,
+			msg); err != nil {
+			if err = txnProducer.abort(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := txnProducer.commit(kMsg, i.group); err != nil {
+			return err
 		}
 	}
 	return nil
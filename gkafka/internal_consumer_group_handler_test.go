@@ -0,0 +1,185 @@
+package gkafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConsumerGroupClaim struct {
+	messages chan *sarama.ConsumerMessage
+}
+
+var _ sarama.ConsumerGroupClaim = fakeConsumerGroupClaim{}
+
+func newFakeConsumerGroupClaim(msgs ...*sarama.ConsumerMessage) fakeConsumerGroupClaim {
+	claim := fakeConsumerGroupClaim{messages: make(chan *sarama.ConsumerMessage, len(msgs))}
+	for _, kMsg := range msgs {
+		claim.messages <- kMsg
+	}
+	close(claim.messages)
+	return claim
+}
+
+func (c fakeConsumerGroupClaim) Topic() string                            { return "foo.topic" }
+func (c fakeConsumerGroupClaim) Partition() int32                         { return 0 }
+func (c fakeConsumerGroupClaim) InitialOffset() int64                     { return 0 }
+func (c fakeConsumerGroupClaim) HighWaterMarkOffset() int64               { return int64(cap(c.messages)) }
+func (c fakeConsumerGroupClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+// fakeConsumerGroupSession Records marked offsets and commits.
+type fakeConsumerGroupSession struct {
+	sarama.ConsumerGroupSession
+	ctx     context.Context
+	marked  []int64
+	commits int
+}
+
+func (s *fakeConsumerGroupSession) Context() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+	return context.Background()
+}
+
+func (s *fakeConsumerGroupSession) Claims() map[string][]int32 {
+	return map[string][]int32{"foo.topic": {0, 1}}
+}
+
+func (s *fakeConsumerGroupSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+func (s *fakeConsumerGroupSession) Commit() {
+	s.commits++
+}
+
+func TestInternalConsumerGroupHandler_ConsumeClaim(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Config      ConsumerConfiguration
+		WantMarked  []int64
+		WantCommits int
+	}{
+		{
+			Name:       "Automatic commit",
+			WantMarked: []int64{0, 2},
+		},
+		{
+			Name:        "Synchronous commit",
+			Config:      ConsumerConfiguration{CommitMode: CommitSync},
+			WantMarked:  []int64{0, 2},
+			WantCommits: 2,
+		},
+		{
+			Name:       "Manual commit",
+			Config:     ConsumerConfiguration{ManualCommit: true},
+			WantMarked: []int64{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			d := newTestDriver(Configuration{})
+			d.SetInternalHandler(func(ctx context.Context, _ *gluon.Subscriber, msg *gluon.TransportMessage) error {
+				switch string(msg.Data) {
+				case "fail":
+					return errors.New("handler failed")
+				case "ack":
+					return Ack(ctx)
+				}
+				return nil
+			})
+			sub := gluon.NewBus(DriverName).SubscribeTopic("foo.topic").DriverConfiguration(tt.Config)
+			session := &fakeConsumerGroupSession{}
+			err := newInternalConsumerGroup(d, sub, "foo-service").ConsumeClaim(session, newFakeConsumerGroupClaim(
+				newTestConsumerMessage(t, 0, `{}`),
+				newTestConsumerMessage(t, 1, "fail"),
+				newTestConsumerMessage(t, 2, "ack"),
+			))
+			require.NoError(t, err)
+			assert.Equal(t, tt.WantMarked, session.marked)
+			assert.Equal(t, tt.WantCommits, session.commits)
+		})
+	}
+	assert.ErrorIs(t, Ack(context.Background()), ErrMissingAcknowledgement)
+}
+
+func TestInternalConsumerGroupHandler_SessionContext(t *testing.T) {
+	sessionCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var handled int
+	d := newTestDriver(Configuration{})
+	d.SetInternalHandler(func(ctx context.Context, _ *gluon.Subscriber, _ *gluon.TransportMessage) error {
+		// handler retries stop once the claim is revoked
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+		handled++
+		return nil
+	})
+	handler := newInternalConsumerGroup(d, gluon.NewBus(DriverName).SubscribeTopic("foo.topic"), "foo-service")
+	err := handler.ConsumeClaim(&fakeConsumerGroupSession{ctx: sessionCtx},
+		newFakeConsumerGroupClaim(newTestConsumerMessage(t, 0, `{}`)))
+	require.NoError(t, err)
+
+	mockProducer := mocks.NewAsyncProducer(t, d.driverConfig.newTransactionalConfig(nil, "ledger-0"))
+	txnProducer := &transactionalProducer{asyncProducer: newAsyncProducer(d, &txnRecorderProducer{
+		AsyncProducer: mockProducer,
+	})}
+	err = handler.consumeClaimTransactional(sessionCtx, newFakeConsumerGroupClaim(newTestConsumerMessage(t, 0, `{}`)),
+		txnProducer)
+	require.NoError(t, err)
+	require.NoError(t, txnProducer.close())
+	assert.Equal(t, 2, handled)
+}
+
+func TestInternalConsumerGroupHandler_RebalanceHooks(t *testing.T) {
+	var assigned, revoked map[string][]int32
+	errFlush := errors.New("flush failed")
+	sub := gluon.NewBus(DriverName).SubscribeTopic("foo.topic").DriverConfiguration(ConsumerConfiguration{
+		OnPartitionsAssigned: func(_ context.Context, partitions map[string][]int32) error {
+			assigned = partitions
+			return nil
+		},
+		OnPartitionsRevoked: func(ctx context.Context, partitions map[string][]int32) error {
+			// revoked partitions are notified once the session context is cancelled
+			assert.NoError(t, ctx.Err())
+			revoked = partitions
+			return errFlush
+		},
+	})
+	handler := newInternalConsumerGroup(newTestDriver(Configuration{}), sub, "foo-service")
+	sessionCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	session := &fakeConsumerGroupSession{ctx: sessionCtx}
+	require.NoError(t, handler.Setup(session))
+	assert.Equal(t, session.Claims(), assigned)
+	assert.ErrorIs(t, handler.Cleanup(session), errFlush)
+	assert.Equal(t, session.Claims(), revoked)
+
+	// hooks are optional
+	handler = newInternalConsumerGroup(newTestDriver(Configuration{}), gluon.NewBus(DriverName).
+		SubscribeTopic("foo.topic"), "foo-service")
+	assert.NoError(t, handler.Setup(session))
+	assert.NoError(t, handler.Cleanup(session))
+}
+
+func TestConfiguration_NewConsumerConfig(t *testing.T) {
+	base := sarama.NewConfig()
+	cfg := Configuration{}.newConsumerConfig(base, ConsumerConfiguration{CommitInterval: time.Minute})
+	assert.True(t, cfg.Consumer.Offsets.AutoCommit.Enable)
+	assert.Equal(t, time.Minute, cfg.Consumer.Offsets.AutoCommit.Interval)
+	assert.NotEqual(t, time.Minute, base.Consumer.Offsets.AutoCommit.Interval)
+
+	cfg = Configuration{}.newConsumerConfig(nil, ConsumerConfiguration{CommitMode: CommitSync})
+	assert.False(t, cfg.Consumer.Offsets.AutoCommit.Enable)
+
+	cfg = Configuration{TransactionalID: "ledger"}.newConsumerConfig(nil, ConsumerConfiguration{})
+	assert.Equal(t, sarama.ReadCommitted, cfg.Consumer.IsolationLevel)
+	assert.False(t, cfg.Consumer.Offsets.AutoCommit.Enable)
+}
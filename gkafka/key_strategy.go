@@ -0,0 +1,44 @@
+package gkafka
+
+import "github.com/neutrinocorp/gluon"
+
+// KeyStrategy Is a function used to generate the record key of a message. Records with the same key are routed to the
+// same partition, preserving their order. A nil key distributes records across partitions.
+//
+// Custom strategies may generate keys from the encoded message data (TransportMessage.Data). To generate keys from
+// message schemas, use gluon.WithSchemaPartitionKey along PartitionKeyStrategy.
+type KeyStrategy func(msg *gluon.TransportMessage) []byte
+
+// PartitionKeyStrategy Use the CloudEvents partitioning extension (gluon.ExtensionPartitionKey) as record key, falling
+// back to the message ID. This is the default strategy.
+//
+// The extension is set by gluon.WithPartitionKey or gluon.WithSchemaPartitionKey.
+func PartitionKeyStrategy(msg *gluon.TransportMessage) []byte {
+	if key := msg.Extensions[gluon.ExtensionPartitionKey]; key != "" {
+		return []byte(key)
+	}
+	return []byte(msg.ID)
+}
+
+// MessageIDStrategy Use the message ID as record key.
+func MessageIDStrategy(msg *gluon.TransportMessage) []byte {
+	return []byte(msg.ID)
+}
+
+// SubjectStrategy Use the CloudEvents subject attribute as record key. Messages without subject have no key.
+func SubjectStrategy(msg *gluon.TransportMessage) []byte {
+	if msg.Subject == "" {
+		return nil
+	}
+	return []byte(msg.Subject)
+}
+
+// ExtensionStrategy Use a CloudEvents extension attribute as record key. Messages without the extension have no key.
+func ExtensionStrategy(name string) KeyStrategy {
+	return func(msg *gluon.TransportMessage) []byte {
+		if v := msg.Extensions[name]; v != "" {
+			return []byte(v)
+		}
+		return nil
+	}
+}
@@ -2,6 +2,7 @@ package gkafka
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Shopify/sarama"
@@ -22,72 +23,109 @@ func (d dataEncoder) Length() int {
 
 var _ sarama.Encoder = dataEncoder{}
 
-func marshalKafkaMessage(msg *gluon.TransportMessage) *sarama.ProducerMessage {
+// marshalKafkaMessage Encode a message using the CloudEvents Kafka protocol binding and the given content mode.
+//
+// Messages without data are encoded as tombstones (records without value) using binary content mode. The record key
+// of a tombstone is the CloudEvents partitioning extension (gluon.ExtensionPartitionKey) or, if missing, the key
+// generated by the KeyStrategy.
+func marshalKafkaMessage(msg *gluon.TransportMessage, mode gluon.ContentMode,
+	keyStrategy KeyStrategy) (*sarama.ProducerMessage, error) {
 	ts, _ := time.Parse(time.RFC3339, msg.Time)
-	return &sarama.ProducerMessage{
+	kMsg := &sarama.ProducerMessage{
 		Topic:     msg.Topic,
-		Key:       sarama.StringEncoder(msg.ID),
-		Value:     dataEncoder{data: msg.Data},
-		Headers:   marshalKafkaHeaders(msg),
 		Timestamp: ts,
 	}
+	if msg.Data == nil {
+		key := []byte(msg.Extensions[gluon.ExtensionPartitionKey])
+		if len(key) == 0 {
+			key = keyStrategy(msg)
+		}
+		if len(key) == 0 {
+			return nil, ErrMissingTombstoneKey
+		}
+		kMsg.Key = sarama.ByteEncoder(key)
+		kMsg.Headers = marshalKafkaHeaders(msg)
+		return kMsg, nil
+	}
+	if key := keyStrategy(msg); key != nil {
+		kMsg.Key = sarama.ByteEncoder(key)
+	}
+	if mode == gluon.ContentModeStructured {
+		data, err := gluon.MarshalStructuredMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		kMsg.Value = dataEncoder{data: data}
+		kMsg.Headers = append(marshalKafkaTraceHeaders(msg), newRecordHeader(headerContentType,
+			gluon.CloudEventsJSONContentType))
+		return kMsg, nil
+	}
+	kMsg.Value = dataEncoder{data: msg.Data}
+	kMsg.Headers = marshalKafkaHeaders(msg)
+	return kMsg, nil
 }
 
-func unmarshalKafkaMessage(kMsg *sarama.ConsumerMessage, msg *gluon.TransportMessage) {
-	msg.Data = kMsg.Value
+func unmarshalKafkaMessage(kMsg *sarama.ConsumerMessage, msg *gluon.TransportMessage) error {
 	msg.Topic = kMsg.Topic
+	msg.DriverHeaders = map[string]string{}
+	msg.DriverHeaders[HeaderOffset] = strconv.Itoa(int(kMsg.Offset))
+	msg.DriverHeaders[HeaderPartition] = strconv.Itoa(int(kMsg.Partition))
+	if kMsg.Key != nil {
+		msg.DriverHeaders[HeaderKey] = string(kMsg.Key)
+	}
+	for _, v := range kMsg.Headers {
+		if string(v.Key) == headerContentType && gluon.IsStructuredContentType(string(v.Value)) {
+			return gluon.UnmarshalStructuredMessage(kMsg.Value, msg)
+		}
+	}
+	msg.Data = kMsg.Value
 	unmarshalKafkaHeaders(kMsg, msg)
+	return nil
+}
+
+func newRecordHeader(key, value string) sarama.RecordHeader {
+	return sarama.RecordHeader{
+		Key:   []byte(key),
+		Value: []byte(value),
+	}
 }
 
 func marshalKafkaHeaders(msg *gluon.TransportMessage) []sarama.RecordHeader {
-	return []sarama.RecordHeader{
-		{
-			Key:   []byte("ce_id"),
-			Value: []byte(msg.ID),
-		},
-		{
-			Key:   []byte("ce_source"),
-			Value: []byte(msg.Source),
-		},
-		{
-			Key:   []byte("ce_specversion"),
-			Value: []byte(msg.SpecVersion),
-		},
-		{
-			Key:   []byte("ce_type"),
-			Value: []byte(msg.Type),
-		},
-		{
-			Key:   []byte("ce_time"),
-			Value: []byte(msg.Time),
-		},
-		{
-			Key:   []byte("content_type"),
-			Value: []byte(msg.DataContentType),
-		},
-		{
-			Key:   []byte("schema"),
-			Value: []byte(msg.DataSchema),
-		},
-		{
-			Key:   []byte("subject"),
-			Value: []byte(msg.Subject),
-		},
-		{
-			Key:   []byte("gl_correlation_id"),
-			Value: []byte(msg.CorrelationID),
-		},
-		{
-			Key:   []byte("gl_causation_id"),
-			Value: []byte(msg.CausationID),
-		},
+	headers := []sarama.RecordHeader{
+		newRecordHeader(headerMessageID, msg.ID),
+		newRecordHeader(headerSource, msg.Source),
+		newRecordHeader(headerSpecVersion, msg.SpecVersion),
+		newRecordHeader(headerMessageType, msg.Type),
+	}
+	optionalHeaders := []sarama.RecordHeader{
+		newRecordHeader(headerMessageTime, msg.Time),
+		newRecordHeader(headerContentType, msg.DataContentType),
+		newRecordHeader(headerSchema, msg.DataSchema),
+		newRecordHeader(headerSubject, msg.Subject),
+		newRecordHeader(headerExtensionPrefix+gluon.ExtensionCorrelationID, msg.CorrelationID),
+		newRecordHeader(headerExtensionPrefix+gluon.ExtensionCausationID, msg.CausationID),
+	}
+	for _, h := range optionalHeaders {
+		if len(h.Value) > 0 {
+			headers = append(headers, h)
+		}
+	}
+	headers = append(headers, marshalKafkaTraceHeaders(msg)...)
+	for k, v := range msg.Extensions {
+		headers = append(headers, newRecordHeader(headerExtensionPrefix+k, v))
 	}
+	return headers
+}
+
+func marshalKafkaTraceHeaders(msg *gluon.TransportMessage) []sarama.RecordHeader {
+	headers := make([]sarama.RecordHeader, 0, len(msg.TraceContext))
+	for k, v := range msg.TraceContext {
+		headers = append(headers, newRecordHeader(k, v))
+	}
+	return headers
 }
 
 func unmarshalKafkaHeaders(kMsg *sarama.ConsumerMessage, msg *gluon.TransportMessage) {
-	msg.DriverHeaders = map[string]string{}
-	msg.DriverHeaders[HeaderOffset] = strconv.Itoa(int(kMsg.Offset))
-	msg.DriverHeaders[HeaderPartition] = strconv.Itoa(int(kMsg.Partition))
 	for _, v := range kMsg.Headers {
 		switch string(v.Key) {
 		case headerMessageID:
@@ -100,16 +138,28 @@ func unmarshalKafkaHeaders(kMsg *sarama.ConsumerMessage, msg *gluon.TransportMes
 			msg.Type = string(v.Value)
 		case headerMessageTime:
 			msg.Time = string(v.Value)
-		case headerContentType:
+		case headerContentType, legacyHeaderContentType:
 			msg.DataContentType = string(v.Value)
-		case headerSchema:
+		case headerSchema, legacyHeaderSchema:
 			msg.DataSchema = string(v.Value)
-		case headerSubject:
+		case headerSubject, legacyHeaderSubject:
 			msg.Subject = string(v.Value)
-		case headerCorrelationID:
+		case legacyHeaderCorrelationID:
 			msg.CorrelationID = string(v.Value)
-		case headerCausationID:
+		case legacyHeaderCausationID:
 			msg.CausationID = string(v.Value)
+		case gluon.ExtensionTraceParent, gluon.ExtensionTraceState:
+			msg.SetAttribute(string(v.Key), string(v.Value))
+		default:
+			unmarshalKafkaExtension(v, msg)
 		}
 	}
 }
+
+func unmarshalKafkaExtension(header *sarama.RecordHeader, msg *gluon.TransportMessage) {
+	key := string(header.Key)
+	if !strings.HasPrefix(key, headerExtensionPrefix) {
+		return
+	}
+	msg.SetAttribute(strings.TrimPrefix(key, headerExtensionPrefix), string(header.Value))
+}
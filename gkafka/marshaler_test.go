@@ -0,0 +1,223 @@
+package gkafka
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTransportMessage() *gluon.TransportMessage {
+	return &gluon.TransportMessage{
+		ID:              "123",
+		Source:          "https://api.neutrino.org/marketplace/items",
+		SpecVersion:     gluon.CloudEventsSpecVersion,
+		Type:            "org.neutrino.marketplace.item.paid",
+		Data:            []byte(`{"item_id":"abc"}`),
+		DataContentType: "application/json",
+		DataSchema:      "https://schemas.neutrino.org/item_paid.json",
+		Subject:         "abc",
+		Time:            "2021-01-01T00:00:00Z",
+		CorrelationID:   "456",
+		CausationID:     "789",
+		TraceContext: map[string]string{
+			"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		},
+		Extensions: map[string]string{
+			"tenantid": "neutrino",
+		},
+		Topic: "org.neutrino.marketplace.item.paid",
+	}
+}
+
+// toConsumerMessage Simulate the delivery of a produced message.
+func toConsumerMessage(t *testing.T, kMsg *sarama.ProducerMessage) *sarama.ConsumerMessage {
+	var value []byte
+	var err error
+	if kMsg.Value != nil {
+		value, err = kMsg.Value.Encode()
+		require.NoError(t, err)
+	}
+	headers := make([]*sarama.RecordHeader, 0, len(kMsg.Headers))
+	for i := range kMsg.Headers {
+		headers = append(headers, &kMsg.Headers[i])
+	}
+	var key []byte
+	if kMsg.Key != nil {
+		key, err = kMsg.Key.Encode()
+		require.NoError(t, err)
+	}
+	return &sarama.ConsumerMessage{
+		Headers:   headers,
+		Key:       key,
+		Topic:     kMsg.Topic,
+		Value:     value,
+		Offset:    10,
+		Partition: 2,
+	}
+}
+
+func getHeader(kMsg *sarama.ProducerMessage, key string) string {
+	for _, h := range kMsg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestMarshalKafkaMessage(t *testing.T) {
+	for _, mode := range []gluon.ContentMode{gluon.ContentModeBinary, gluon.ContentModeStructured} {
+		msg := newTestTransportMessage()
+		kMsg, err := marshalKafkaMessage(msg, mode, PartitionKeyStrategy)
+		require.NoError(t, err)
+		if mode == gluon.ContentModeBinary {
+			assert.Equal(t, "123", getHeader(kMsg, "ce_id"))
+			assert.Equal(t, "application/json", getHeader(kMsg, "content-type"))
+			assert.Equal(t, "456", getHeader(kMsg, "ce_correlationid"))
+			assert.Equal(t, "neutrino", getHeader(kMsg, "ce_tenantid"))
+		} else {
+			assert.Equal(t, gluon.CloudEventsJSONContentType, getHeader(kMsg, "content-type"))
+		}
+		assert.Equal(t, msg.TraceContext["traceparent"], getHeader(kMsg, "traceparent"))
+
+		got := new(gluon.TransportMessage)
+		require.NoError(t, unmarshalKafkaMessage(toConsumerMessage(t, kMsg), got))
+		assert.Equal(t, map[string]string{
+			HeaderOffset:    "10",
+			HeaderPartition: "2",
+			HeaderKey:       "123",
+		}, got.DriverHeaders)
+		got.DriverHeaders = nil
+		assert.Equal(t, msg, got)
+	}
+}
+
+func TestUnmarshalKafkaMessage_LegacyHeaders(t *testing.T) {
+	got := new(gluon.TransportMessage)
+	require.NoError(t, unmarshalKafkaMessage(&sarama.ConsumerMessage{
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("ce_id"), Value: []byte("123")},
+			{Key: []byte("ce_type"), Value: []byte("org.neutrino.marketplace.item.paid")},
+			{Key: []byte("content_type"), Value: []byte("application/json")},
+			{Key: []byte("schema"), Value: []byte("https://schemas.neutrino.org/item_paid.json")},
+			{Key: []byte("subject"), Value: []byte("abc")},
+			{Key: []byte("gl_correlation_id"), Value: []byte("456")},
+			{Key: []byte("gl_causation_id"), Value: []byte("789")},
+		},
+		Topic: "org.neutrino.marketplace.item.paid",
+		Value: []byte(`{"item_id":"abc"}`),
+	}, got))
+	assert.Equal(t, "application/json", got.DataContentType)
+	assert.Equal(t, "https://schemas.neutrino.org/item_paid.json", got.DataSchema)
+	assert.Equal(t, "abc", got.Subject)
+	assert.Equal(t, "456", got.CorrelationID)
+	assert.Equal(t, "789", got.CausationID)
+	assert.Nil(t, got.Extensions)
+}
+
+func TestUnmarshalKafkaMessage_InvalidStructured(t *testing.T) {
+	err := unmarshalKafkaMessage(&sarama.ConsumerMessage{
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("content-type"), Value: []byte(gluon.CloudEventsJSONContentType)},
+		},
+		Value: []byte(`{"id":`),
+	}, new(gluon.TransportMessage))
+	assert.ErrorIs(t, err, gluon.ErrInvalidCloudEvent)
+}
+
+func TestMarshalKafkaMessage_KeyStrategies(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Strategy KeyStrategy
+		Msg      func(msg *gluon.TransportMessage)
+		Want     sarama.Encoder
+	}{
+		{
+			Name:     "Partition key fallback",
+			Strategy: PartitionKeyStrategy,
+			Want:     sarama.ByteEncoder("123"),
+		},
+		{
+			Name:     "Partition key",
+			Strategy: PartitionKeyStrategy,
+			Msg: func(msg *gluon.TransportMessage) {
+				msg.Extensions[gluon.ExtensionPartitionKey] = "item-abc"
+			},
+			Want: sarama.ByteEncoder("item-abc"),
+		},
+		{
+			Name:     "Message ID",
+			Strategy: MessageIDStrategy,
+			Msg: func(msg *gluon.TransportMessage) {
+				msg.Extensions[gluon.ExtensionPartitionKey] = "item-abc"
+			},
+			Want: sarama.ByteEncoder("123"),
+		},
+		{
+			Name:     "Subject",
+			Strategy: SubjectStrategy,
+			Want:     sarama.ByteEncoder("abc"),
+		},
+		{
+			Name:     "Missing subject",
+			Strategy: SubjectStrategy,
+			Msg: func(msg *gluon.TransportMessage) {
+				msg.Subject = ""
+			},
+		},
+		{
+			Name:     "Extension",
+			Strategy: ExtensionStrategy("tenantid"),
+			Want:     sarama.ByteEncoder("neutrino"),
+		},
+		{
+			Name:     "Missing extension",
+			Strategy: ExtensionStrategy("region"),
+		},
+		{
+			Name: "Payload",
+			Strategy: func(msg *gluon.TransportMessage) []byte {
+				return msg.Data[1:10]
+			},
+			Want: sarama.ByteEncoder(`"item_id"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			msg := newTestTransportMessage()
+			if tt.Msg != nil {
+				tt.Msg(msg)
+			}
+			kMsg, err := marshalKafkaMessage(msg, gluon.ContentModeBinary, tt.Strategy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.Want, kMsg.Key)
+		})
+	}
+}
+
+func TestMarshalKafkaMessage_Tombstone(t *testing.T) {
+	msg := newTestTransportMessage()
+	msg.Data = nil
+	_, err := marshalKafkaMessage(msg, gluon.ContentModeStructured, SubjectStrategy)
+	require.NoError(t, err)
+
+	msg.Extensions[gluon.ExtensionPartitionKey] = "item-abc"
+	kMsg, err := marshalKafkaMessage(msg, gluon.ContentModeStructured, SubjectStrategy)
+	require.NoError(t, err)
+	assert.Equal(t, sarama.ByteEncoder("item-abc"), kMsg.Key)
+	assert.Nil(t, kMsg.Value)
+	assert.Equal(t, "123", getHeader(kMsg, "ce_id"))
+
+	got := new(gluon.TransportMessage)
+	require.NoError(t, unmarshalKafkaMessage(toConsumerMessage(t, kMsg), got))
+	assert.Nil(t, got.Data)
+	assert.Equal(t, "item-abc", got.DriverHeaders[HeaderKey])
+
+	msg.Subject = ""
+	delete(msg.Extensions, gluon.ExtensionPartitionKey)
+	_, err = marshalKafkaMessage(msg, gluon.ContentModeBinary, SubjectStrategy)
+	assert.ErrorIs(t, err, ErrMissingTombstoneKey)
+}
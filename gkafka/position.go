@@ -0,0 +1,63 @@
+package gkafka
+
+import (
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+type positionKind int
+
+const (
+	positionUnset positionKind = iota
+	positionEarliest
+	positionLatest
+	positionOffset
+	positionTime
+)
+
+// Position Is a location within the partitions of a topic, used to start or reposition consumers.
+type Position struct {
+	kind      positionKind
+	offset    int64
+	timestamp time.Time
+}
+
+var (
+	// PositionEarliest Is the oldest message available of every partition.
+	PositionEarliest = Position{kind: positionEarliest}
+	// PositionLatest Is the next message produced to every partition.
+	PositionLatest = Position{kind: positionLatest}
+)
+
+// PositionAt Is an explicit offset of every partition.
+func PositionAt(offset int64) Position {
+	return Position{kind: positionOffset, offset: offset}
+}
+
+// PositionAtTime Is the first message of every partition produced at or after t. Partitions without such message
+// are positioned at PositionLatest.
+func PositionAtTime(t time.Time) Position {
+	return Position{kind: positionTime, timestamp: t}
+}
+
+// IsZero Indicate if the position was not set.
+func (p Position) IsZero() bool {
+	return p.kind == positionUnset
+}
+
+// resolveOffset Retrieve the offset of a partition for the given position.
+func resolveOffset(client sarama.Client, topic string, partition int32, pos Position) (int64, error) {
+	switch pos.kind {
+	case positionEarliest:
+		return client.GetOffset(topic, partition, sarama.OffsetOldest)
+	case positionOffset:
+		return pos.offset, nil
+	case positionTime:
+		offset, err := client.GetOffset(topic, partition, pos.timestamp.UnixMilli())
+		if err != nil || offset >= 0 {
+			return offset, err
+		}
+	}
+	return client.GetOffset(topic, partition, sarama.OffsetNewest)
+}
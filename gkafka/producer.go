@@ -0,0 +1,146 @@
+package gkafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/gluon"
+)
+
+// ErrProducerClosed The Apache Kafka producer is not running (the Bus was not started or it was shut down).
+var ErrProducerClosed = errors.New("gkafka: Producer is not running")
+
+// DeliveryReport Is the result of producing a message to Apache Kafka.
+type DeliveryReport struct {
+	Message   *gluon.TransportMessage
+	Topic     string
+	Partition int32
+	Offset    int64
+	// Err Is the reason the message could not be produced, nil if the message was acknowledged by the broker.
+	Err error
+}
+
+// DeliveryReportFunc Is a callback used to receive delivery reports of produced messages.
+type DeliveryReportFunc func(DeliveryReport)
+
+// producer Is a long-lived Apache Kafka producer shared by every publishing operation of a driver.
+type producer interface {
+	send(ctx context.Context, msgs ...*sarama.ProducerMessage) error
+	close() error
+}
+
+func newDeliveryReport(kMsg *sarama.ProducerMessage, err error) DeliveryReport {
+	msg, _ := kMsg.Metadata.(*gluon.TransportMessage)
+	return DeliveryReport{
+		Message:   msg,
+		Topic:     kMsg.Topic,
+		Partition: kMsg.Partition,
+		Offset:    kMsg.Offset,
+		Err:       err,
+	}
+}
+
+// syncProducer Produces messages waiting for the broker acknowledgement.
+type syncProducer struct {
+	parentDriver *driver
+	producer     sarama.SyncProducer
+}
+
+var _ producer = &syncProducer{}
+
+func (p *syncProducer) send(_ context.Context, msgs ...*sarama.ProducerMessage) error {
+	if len(msgs) == 1 {
+		_, _, err := p.producer.SendMessage(msgs[0])
+		p.parentDriver.reportDelivery(newDeliveryReport(msgs[0], err))
+		return err
+	}
+
+	err := p.producer.SendMessages(msgs)
+	var prodErrs sarama.ProducerErrors
+	if err != nil && !errors.As(err, &prodErrs) {
+		// the whole batch failed
+		for _, kMsg := range msgs {
+			p.parentDriver.reportDelivery(newDeliveryReport(kMsg, err))
+		}
+		return err
+	}
+	failed := make(map[*sarama.ProducerMessage]error, len(prodErrs))
+	for _, prodErr := range prodErrs {
+		failed[prodErr.Msg] = prodErr.Err
+	}
+	for _, kMsg := range msgs {
+		p.parentDriver.reportDelivery(newDeliveryReport(kMsg, failed[kMsg]))
+	}
+	return err
+}
+
+func (p *syncProducer) close() error {
+	return p.producer.Close()
+}
+
+// asyncProducer Produces messages in background, reporting deliveries through the driver's DeliveryReportFunc.
+type asyncProducer struct {
+	parentDriver *driver
+	producer     sarama.AsyncProducer
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+var _ producer = &asyncProducer{}
+
+func newAsyncProducer(d *driver, prod sarama.AsyncProducer) *asyncProducer {
+	p := &asyncProducer{
+		parentDriver: d,
+		producer:     prod,
+		mu:           sync.RWMutex{},
+	}
+	p.wg.Add(2)
+	go func() {
+		defer p.wg.Done()
+		for kMsg := range prod.Successes() {
+			p.parentDriver.reportDelivery(newDeliveryReport(kMsg, nil))
+		}
+	}()
+	go func() {
+		defer p.wg.Done()
+		for prodErr := range prod.Errors() {
+			if d.driverConfig.OnDelivery == nil && d.isLoggingEnabled() {
+				// failures would be lost otherwise as publishing operations have already returned
+				d.parentBus.Logger.Print(prodErr)
+			}
+			p.parentDriver.reportDelivery(newDeliveryReport(prodErr.Msg, prodErr.Err))
+		}
+	}()
+	return p
+}
+
+func (p *asyncProducer) send(ctx context.Context, msgs ...*sarama.ProducerMessage) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrProducerClosed
+	}
+	for _, kMsg := range msgs {
+		select {
+		case p.producer.Input() <- kMsg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// close Flush buffered messages and wait for their delivery reports.
+func (p *asyncProducer) close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	// delivery reports are read until the producer closes both channels
+	p.producer.AsyncClose()
+	p.wg.Wait()
+	return nil
+}
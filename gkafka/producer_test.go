@@ -0,0 +1,110 @@
+package gkafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errProduceDummy = errors.New("produce failed")
+
+type deliveryRecorder struct {
+	mu      sync.Mutex
+	reports []DeliveryReport
+}
+
+func (r *deliveryRecorder) record(report DeliveryReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, report)
+}
+
+func newTestDriver(cfg Configuration) *driver {
+	d := &driver{}
+	d.SetParentBus(gluon.NewBus(DriverName, gluon.WithDriverConfiguration(cfg)))
+	return d
+}
+
+func newTestMessages(ids ...string) []*gluon.TransportMessage {
+	msgs := make([]*gluon.TransportMessage, 0, len(ids))
+	for _, id := range ids {
+		msg := newTestTransportMessage()
+		msg.ID = id
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func TestDriver_PublishBatch_Sync(t *testing.T) {
+	recorder := &deliveryRecorder{}
+	d := newTestDriver(Configuration{OnDelivery: recorder.record})
+	assert.ErrorIs(t, d.Publish(context.Background(), newTestTransportMessage()), ErrProducerClosed)
+
+	mockProducer := mocks.NewSyncProducer(t, nil)
+	d.producer = &syncProducer{parentDriver: d, producer: mockProducer}
+	mockProducer.ExpectSendMessageAndSucceed()
+	mockProducer.ExpectSendMessageAndFail(errProduceDummy)
+	mockProducer.ExpectSendMessageAndSucceed()
+	mockProducer.ExpectSendMessageAndSucceed()
+
+	msgs := newTestMessages("1", "2", "3", "4")
+	require.NoError(t, d.Publish(context.Background(), msgs[0]))
+	assert.ErrorIs(t, d.Publish(context.Background(), msgs[1]), errProduceDummy)
+	require.NoError(t, d.PublishBatch(context.Background(), msgs[2:]))
+	require.NoError(t, d.Shutdown(context.Background()))
+
+	require.Len(t, recorder.reports, 4)
+	for i, report := range recorder.reports {
+		assert.Same(t, msgs[i], report.Message)
+		assert.Equal(t, msgs[i].Topic, report.Topic)
+	}
+	assert.NoError(t, recorder.reports[0].Err)
+	assert.ErrorIs(t, recorder.reports[1].Err, errProduceDummy)
+	assert.Equal(t, int64(3), recorder.reports[3].Offset)
+	assert.ErrorIs(t, d.Publish(context.Background(), msgs[0]), ErrProducerClosed)
+}
+
+func TestDriver_PublishBatch_Async(t *testing.T) {
+	recorder := &deliveryRecorder{}
+	d := newTestDriver(Configuration{Async: true, OnDelivery: recorder.record})
+
+	mockProducer := mocks.NewAsyncProducer(t, d.driverConfig.newProducerConfig(nil))
+	d.producer = newAsyncProducer(d, mockProducer)
+	mockProducer.ExpectInputAndSucceed()
+	mockProducer.ExpectInputAndFail(errProduceDummy)
+	mockProducer.ExpectInputAndSucceed()
+
+	msgs := newTestMessages("1", "2", "3")
+	require.NoError(t, d.Publish(context.Background(), msgs[0]))
+	require.NoError(t, d.PublishBatch(context.Background(), msgs[1:]))
+	// close flushes pending messages and waits for their delivery reports
+	require.NoError(t, d.Shutdown(context.Background()))
+
+	require.Len(t, recorder.reports, 3)
+	failed := 0
+	for _, report := range recorder.reports {
+		if report.Err != nil {
+			failed++
+			assert.Same(t, msgs[1], report.Message)
+		}
+	}
+	assert.Equal(t, 1, failed)
+}
+
+func TestConfiguration_NewProducerConfig(t *testing.T) {
+	base := sarama.NewConfig()
+	cfg := Configuration{BatchSize: 100, Linger: 5}.newProducerConfig(base)
+	assert.True(t, cfg.Producer.Return.Successes)
+	assert.Equal(t, 100, cfg.Producer.Flush.Messages)
+	assert.EqualValues(t, 5, cfg.Producer.Flush.Frequency)
+	// the given configuration is never modified
+	assert.False(t, base.Producer.Return.Successes)
+	assert.Zero(t, base.Producer.Flush.Messages)
+}
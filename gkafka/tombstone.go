@@ -0,0 +1,41 @@
+package gkafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+)
+
+// ErrMissingTombstoneKey A tombstone cannot be produced without a record key.
+var ErrMissingTombstoneKey = errors.New("gkafka: Tombstone has no record key")
+
+// PublishTombstone Propagate a tombstone (a record without value) with the given key to a topic. Compacted topics
+// remove every previous record of the key.
+//
+// Tombstones are delivered to subscribers with nil data (gluon.Message.Data).
+func PublishTombstone(ctx context.Context, b *gluon.Bus, topic, key string) error {
+	if key == "" {
+		return ErrMissingTombstoneKey
+	}
+	msgID, err := b.Factories.IDFactory.NewID()
+	if err != nil {
+		return err
+	}
+	var source string
+	if meta := b.GetSchemaMetadataFromTopic(topic); meta != nil {
+		source = meta.Source
+	}
+	return b.PublishRaw(ctx, &gluon.TransportMessage{
+		ID:          msgID,
+		Source:      source,
+		SpecVersion: gluon.CloudEventsSpecVersion,
+		Type:        topic,
+		Time:        time.Now().UTC().Format(time.RFC3339),
+		Topic:       topic,
+		Extensions: map[string]string{
+			gluon.ExtensionPartitionKey: key,
+		},
+	})
+}
@@ -0,0 +1,138 @@
+package gkafka
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// ErrTopicDrift The settings of existing topics differ from the topic provisioning settings.
+var ErrTopicDrift = errors.New("gkafka: Topic settings drifted from the provisioning settings")
+
+const (
+	defaultTopicPartitions        int32 = 1
+	defaultTopicReplicationFactor int16 = 1
+)
+
+// TopicSpec Is the desired settings of a topic.
+type TopicSpec struct {
+	// NumPartitions Number of partitions of the topic (1 by default).
+	NumPartitions int32
+	// ReplicationFactor Number of replicas of every partition (1 by default).
+	ReplicationFactor int16
+	// Configs Topic configuration entries (e.g. retention.ms, cleanup.policy).
+	Configs map[string]string
+}
+
+// TopicConfiguration Is the topic provisioning configuration of the Apache Kafka driver.
+//
+// Once the Bus starts, every missing topic required by the Bus (gluon.Bus.Topics), including retry and dead-letter
+// topics, is created. Existing topics are validated against their settings and drifts are logged.
+type TopicConfiguration struct {
+	// Default Settings of every provisioned topic.
+	Default TopicSpec
+	// Topics Topic-specific settings by topic name. Unset fields fall back to Default settings and configuration
+	// entries are merged.
+	Topics map[string]TopicSpec
+	// FailOnDrift Abort the Bus start (ErrTopicDrift) if existing topics drifted from their settings.
+	FailOnDrift bool
+}
+
+// GetTopicSpec Retrieve the settings of a topic.
+func (c TopicConfiguration) GetTopicSpec(topic string) TopicSpec {
+	spec := TopicSpec{
+		NumPartitions:     c.Default.NumPartitions,
+		ReplicationFactor: c.Default.ReplicationFactor,
+		Configs:           make(map[string]string, len(c.Default.Configs)),
+	}
+	for k, v := range c.Default.Configs {
+		spec.Configs[k] = v
+	}
+	if override, ok := c.Topics[topic]; ok {
+		if override.NumPartitions > 0 {
+			spec.NumPartitions = override.NumPartitions
+		}
+		if override.ReplicationFactor > 0 {
+			spec.ReplicationFactor = override.ReplicationFactor
+		}
+		for k, v := range override.Configs {
+			spec.Configs[k] = v
+		}
+	}
+	if spec.NumPartitions <= 0 {
+		spec.NumPartitions = defaultTopicPartitions
+	}
+	if spec.ReplicationFactor <= 0 {
+		spec.ReplicationFactor = defaultTopicReplicationFactor
+	}
+	return spec
+}
+
+// TopicDrift Is a difference between an existing topic and its settings.
+type TopicDrift struct {
+	Topic string
+	// Setting Is the drifted setting (`partitions`, `replication.factor` or a configuration entry name).
+	Setting  string
+	Expected string
+	// Actual Is the current value of the setting, including broker defaults. Empty if a configuration entry is
+	// unknown by the broker.
+	Actual string
+	// Hint Is a suggested remediation of the drift (optional). Drifts are never fixed automatically.
+	Hint string
+}
+
+func (d TopicDrift) String() string {
+	str := "topic (" + d.Topic + ") setting (" + d.Setting + ") is (" + d.Actual + "), expected (" + d.Expected + ")"
+	if d.Hint != "" {
+		str += ", " + d.Hint
+	}
+	return str
+}
+
+// getTopicDrifts Compare the settings of an existing topic against the given settings.
+//
+// configs holds the current configuration entries of the topic, including broker defaults.
+func getTopicDrifts(topic string, spec TopicSpec, numPartitions int32, replicationFactor int16,
+	configs map[string]string) []TopicDrift {
+	var drifts []TopicDrift
+	if numPartitions != spec.NumPartitions {
+		drift := TopicDrift{
+			Topic:    topic,
+			Setting:  "partitions",
+			Expected: strconv.Itoa(int(spec.NumPartitions)),
+			Actual:   strconv.Itoa(int(numPartitions)),
+			Hint:     "partitions cannot be decreased, the topic must be re-created",
+		}
+		if numPartitions < spec.NumPartitions {
+			// keyed messages are re-distributed across partitions, breaking their ordering
+			drift.Hint = "partitions may be increased (sarama.ClusterAdmin.CreatePartitions), " +
+				"changing the partition of keyed messages"
+		}
+		drifts = append(drifts, drift)
+	}
+	if replicationFactor != spec.ReplicationFactor {
+		drifts = append(drifts, TopicDrift{
+			Topic:    topic,
+			Setting:  "replication.factor",
+			Expected: strconv.Itoa(int(spec.ReplicationFactor)),
+			Actual:   strconv.Itoa(int(replicationFactor)),
+		})
+	}
+	keys := make([]string, 0, len(spec.Configs))
+	for k := range spec.Configs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := spec.Configs[k]
+		if actual := configs[k]; actual != v {
+			drifts = append(drifts, TopicDrift{
+				Topic:    topic,
+				Setting:  k,
+				Expected: v,
+				Actual:   actual,
+			})
+		}
+	}
+	return drifts
+}
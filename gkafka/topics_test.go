@@ -0,0 +1,81 @@
+package gkafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicConfiguration_GetTopicSpec(t *testing.T) {
+	cfg := TopicConfiguration{
+		Default: TopicSpec{
+			ReplicationFactor: 3,
+			Configs:           map[string]string{"retention.ms": "86400000", "cleanup.policy": "delete"},
+		},
+		Topics: map[string]TopicSpec{
+			"foo.topic": {NumPartitions: 12, Configs: map[string]string{"cleanup.policy": "compact"}},
+		},
+	}
+	tests := []struct {
+		Name  string
+		Topic string
+		Cfg   TopicConfiguration
+		Want  TopicSpec
+	}{
+		{
+			Name:  "Defaults",
+			Topic: "foo.topic",
+			Want:  TopicSpec{NumPartitions: 1, ReplicationFactor: 1, Configs: map[string]string{}},
+		},
+		{
+			Name:  "Default settings",
+			Topic: "bar.topic",
+			Cfg:   cfg,
+			Want: TopicSpec{
+				NumPartitions:     1,
+				ReplicationFactor: 3,
+				Configs:           map[string]string{"retention.ms": "86400000", "cleanup.policy": "delete"},
+			},
+		},
+		{
+			Name:  "Topic settings",
+			Topic: "foo.topic",
+			Cfg:   cfg,
+			Want: TopicSpec{
+				NumPartitions:     12,
+				ReplicationFactor: 3,
+				Configs:           map[string]string{"retention.ms": "86400000", "cleanup.policy": "compact"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Want, tt.Cfg.GetTopicSpec(tt.Topic))
+		})
+	}
+	// default settings are not modified by topic settings
+	assert.Equal(t, "delete", cfg.Default.Configs["cleanup.policy"])
+}
+
+func TestGetTopicDrifts(t *testing.T) {
+	spec := TopicSpec{
+		NumPartitions:     3,
+		ReplicationFactor: 1,
+		Configs:           map[string]string{"retention.ms": "5000"},
+	}
+	assert.Empty(t, getTopicDrifts("foo.topic", spec, 3, 1, map[string]string{"retention.ms": "5000"}))
+
+	drifts := getTopicDrifts("foo.topic", spec, 2, 2, nil)
+	if assert.Len(t, drifts, 3) {
+		assert.Equal(t, "partitions", drifts[0].Setting)
+		assert.Contains(t, drifts[0].Hint, "CreatePartitions")
+		assert.Equal(t, "replication.factor", drifts[1].Setting)
+		assert.Equal(t, TopicDrift{Topic: "foo.topic", Setting: "retention.ms", Expected: "5000"}, drifts[2])
+	}
+
+	// partitions cannot be decreased
+	drifts = getTopicDrifts("foo.topic", spec, 6, 1, map[string]string{"retention.ms": "5000"})
+	if assert.Len(t, drifts, 1) {
+		assert.NotContains(t, drifts[0].Hint, "CreatePartitions")
+	}
+}
@@ -0,0 +1,92 @@
+package gkafka
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Shopify/sarama"
+	"github.com/neutrinocorp/gluon"
+)
+
+type transactionContextKey struct{}
+
+// transactionalProducer Is a transactional Apache Kafka producer bound to a claimed partition of a consumer group.
+//
+// Every consumed message is processed within a transaction, so messages published by the handler (using the handler
+// context) and the consumed offset are committed atomically.
+type transactionalProducer struct {
+	*asyncProducer
+}
+
+// newTransactionalProducer Allocate a transactional producer for a claimed partition.
+//
+// Transactional IDs are unique per consumer group partition, so a producer of a previous consumer group generation
+// (zombie) is fenced once the partition is claimed again.
+func newTransactionalProducer(d *driver, group, topic string, partition int32) (*transactionalProducer, error) {
+	cfg := d.driverConfig.newTransactionalConfig(d.config,
+		d.driverConfig.TransactionalID+"-"+group+"-"+topic+"-"+strconv.Itoa(int(partition)))
+	prod, err := sarama.NewAsyncProducer(d.addrs, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &transactionalProducer{
+		asyncProducer: newAsyncProducer(d, prod),
+	}, nil
+}
+
+func (p *transactionalProducer) begin() error {
+	return p.producer.BeginTxn()
+}
+
+// commit Add the consumed message offset to the transaction and commit it. Aborts the transaction if it cannot be
+// committed.
+func (p *transactionalProducer) commit(kMsg *sarama.ConsumerMessage, group string) error {
+	err := p.producer.AddMessageToTxn(kMsg, group, nil)
+	if err == nil {
+		err = p.producer.CommitTxn()
+	}
+	if err != nil {
+		return p.abortOnError(err)
+	}
+	return nil
+}
+
+func (p *transactionalProducer) abort() error {
+	return p.producer.AbortTxn()
+}
+
+func (p *transactionalProducer) abortOnError(err error) error {
+	if p.producer.TxnStatus()&sarama.ProducerTxnFlagAbortableError != 0 {
+		if errAbort := p.producer.AbortTxn(); errAbort != nil {
+			return errAbort
+		}
+	}
+	return err
+}
+
+// newTransactionContext Allocate the handler context of a transactional consumer from ctx (the consumer group session
+// context). The transaction is aborted and a new one begins after every failed handler execution.
+func newTransactionContext(ctx context.Context, p *transactionalProducer) context.Context {
+	return gluon.WithAttemptFailedFunc(withTransaction(ctx, p),
+		func(_ context.Context, _ int, _ error) error {
+			if err := p.abort(); err != nil {
+				return err
+			}
+			return p.begin()
+		})
+}
+
+func withTransaction(ctx context.Context, p *transactionalProducer) context.Context {
+	return context.WithValue(ctx, transactionContextKey{}, p)
+}
+
+func getTransaction(ctx context.Context) *transactionalProducer {
+	p, _ := ctx.Value(transactionContextKey{}).(*transactionalProducer)
+	return p
+}
+
+// IsTransactional Indicate if publishing operations using ctx are part of a Kafka transaction (a handler context of a
+// consumer group while Configuration.TransactionalID is set).
+func IsTransactional(ctx context.Context) bool {
+	return getTransaction(ctx) != nil
+}
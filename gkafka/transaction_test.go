@@ -0,0 +1,175 @@
+package gkafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// txnRecorderProducer Records the consumed offsets of committed transactions.
+//
+// The mock producer handles input messages in background, so it is kept in transaction to avoid rejecting messages
+// of committed transactions (sarama flushes messages before committing).
+type txnRecorderProducer struct {
+	*mocks.AsyncProducer
+	mu        sync.Mutex
+	pending   int64
+	committed []int64
+	aborted   int
+	commitErr error
+}
+
+func (p *txnRecorderProducer) AddMessageToTxn(msg *sarama.ConsumerMessage, _ string, _ *string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending = msg.Offset
+	return nil
+}
+
+func (p *txnRecorderProducer) CommitTxn() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.commitErr != nil {
+		return p.commitErr
+	}
+	p.committed = append(p.committed, p.pending)
+	return nil
+}
+
+func (p *txnRecorderProducer) AbortTxn() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.aborted++
+	return nil
+}
+
+func newTestConsumerMessage(t *testing.T, offset int64, data string) *sarama.ConsumerMessage {
+	msg := newTestTransportMessage()
+	msg.Data = []byte(data)
+	kMsg, err := marshalKafkaMessage(msg, gluon.ContentModeBinary, PartitionKeyStrategy)
+	require.NoError(t, err)
+	cMsg := toConsumerMessage(t, kMsg)
+	cMsg.Offset = offset
+	return cMsg
+}
+
+func TestInternalConsumerGroupHandler_ConsumeClaimTransactional(t *testing.T) {
+	d := newTestDriver(Configuration{TransactionalID: "ledger"})
+	d.SetInternalHandler(func(ctx context.Context, _ *gluon.Subscriber, msg *gluon.TransportMessage) error {
+		if !IsTransactional(ctx) {
+			return errors.New("missing transaction")
+		}
+		if string(msg.Data) == "fail" {
+			return errors.New("handler failed")
+		}
+		out := newTestTransportMessage()
+		out.Topic = "bar.topic"
+		return d.Publish(ctx, out)
+	})
+	mockProducer := mocks.NewAsyncProducer(t, d.driverConfig.newTransactionalConfig(nil, "ledger-0"))
+	mockProducer.ExpectInputAndSucceed().ExpectInputAndSucceed()
+	recorder := &txnRecorderProducer{AsyncProducer: mockProducer}
+	txnProducer := &transactionalProducer{asyncProducer: newAsyncProducer(d, recorder)}
+
+	handler := newInternalConsumerGroup(d, gluon.NewBus(DriverName).SubscribeTopic("foo.topic"), "ledger-service")
+	invalidMsg := &sarama.ConsumerMessage{
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("content-type"), Value: []byte(gluon.CloudEventsJSONContentType)},
+		},
+		Value:  []byte(`{"id":`),
+		Offset: 2,
+	}
+	err := handler.consumeClaimTransactional(context.Background(), newFakeConsumerGroupClaim(
+		newTestConsumerMessage(t, 0, `{}`),
+		newTestConsumerMessage(t, 1, "fail"),
+		invalidMsg,
+		newTestConsumerMessage(t, 3, `{}`),
+	), txnProducer)
+	require.NoError(t, err)
+	require.NoError(t, txnProducer.close())
+	assert.Equal(t, []int64{0, 2, 3}, recorder.committed)
+	assert.Equal(t, 1, recorder.aborted)
+
+	// failed commits release the claim
+	mockProducer = mocks.NewAsyncProducer(t, d.driverConfig.newTransactionalConfig(nil, "ledger-0"))
+	mockProducer.ExpectInputAndSucceed()
+	recorder = &txnRecorderProducer{AsyncProducer: mockProducer, commitErr: sarama.ErrOutOfOrderSequenceNumber}
+	txnProducer = &transactionalProducer{asyncProducer: newAsyncProducer(d, recorder)}
+	err = handler.consumeClaimTransactional(context.Background(), newFakeConsumerGroupClaim(
+		newTestConsumerMessage(t, 0, `{}`),
+		newTestConsumerMessage(t, 1, `{}`),
+	), txnProducer)
+	assert.ErrorIs(t, err, sarama.ErrOutOfOrderSequenceNumber)
+	require.NoError(t, txnProducer.close())
+	assert.Empty(t, recorder.committed)
+}
+
+// handlerCaptureDriver Registers the Bus internal handler into a test driver without connecting to the brokers.
+type handlerCaptureDriver struct {
+	*driver
+}
+
+func (d handlerCaptureDriver) Start(_ context.Context) error {
+	return nil
+}
+
+func (d handlerCaptureDriver) Subscribe(_ context.Context, _ *gluon.Subscriber) error {
+	return nil
+}
+
+func TestInternalConsumerGroupHandler_ConsumeClaimTransactionalRetries(t *testing.T) {
+	d := &driver{}
+	gluon.Register("kafka-handler-capture", func() gluon.Driver {
+		return handlerCaptureDriver{driver: d}
+	})
+	bus := gluon.NewBus("kafka-handler-capture",
+		gluon.WithDriverConfiguration(Configuration{TransactionalID: "ledger"}),
+		gluon.WithRetryPolicy(gluon.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+	bus.RegisterSchema(struct{}{}, gluon.WithTopic("org.neutrino.marketplace.item.paid"))
+
+	mockProducer := mocks.NewAsyncProducer(t, d.driverConfig.newTransactionalConfig(nil, "ledger-0"))
+	mockProducer.ExpectInputAndSucceed().ExpectInputAndSucceed()
+	recorder := &txnRecorderProducer{AsyncProducer: mockProducer}
+	txnProducer := &transactionalProducer{asyncProducer: newAsyncProducer(d, recorder)}
+	sub := bus.Subscribe(struct{}{}).HandlerFunc(func(ctx context.Context, msg *gluon.Message) error {
+		out := newTestTransportMessage()
+		out.Topic = "bar.topic"
+		if err := d.Publish(ctx, out); err != nil {
+			return err
+		}
+		if msg.GetAttempt() == 1 {
+			return errors.New("handler failed")
+		}
+		// the message published by the first attempt was discarded
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		assert.Equal(t, 1, recorder.aborted)
+		return nil
+	})
+	require.NoError(t, bus.ListenAndServe())
+
+	handler := newInternalConsumerGroup(d, sub, "ledger-service")
+	err := handler.consumeClaimTransactional(context.Background(),
+		newFakeConsumerGroupClaim(newTestConsumerMessage(t, 0, `{}`)), txnProducer)
+	require.NoError(t, err)
+	require.NoError(t, txnProducer.close())
+	assert.Equal(t, []int64{0}, recorder.committed)
+	assert.Equal(t, 1, recorder.aborted)
+}
+
+func TestDriver_SubscribeTransactionalManualCommit(t *testing.T) {
+	d := newTestDriver(Configuration{TransactionalID: "ledger"})
+	sub := gluon.NewBus(DriverName).SubscribeTopic("foo.topic").Group("ledger-service").
+		DriverConfiguration(ConsumerConfiguration{ManualCommit: true})
+	err := d.Subscribe(context.Background(), sub)
+	assert.Error(t, err)
+	assert.Empty(t, d.consumers)
+}
@@ -1,5 +1,82 @@
 package glocal
 
+import "time"
+
+const (
+	defaultBufferSize      = 1024
+	defaultRedeliveryDelay = time.Millisecond * 100
+	defaultMaxDeliveries   = 10
+	defaultDirectory       = "gluon-data"
+	defaultSegmentSize     = 16 << 20 // 16 MiB
+	defaultGroupRetention  = time.Minute
+)
+
+// Configuration Is the in-memory driver configuration, set using gluon.WithDriverConfiguration.
 type Configuration struct {
+	// IsDurable Persist messages and consumer group offsets on disk. Messages which were not acknowledged by a
+	// consumer group are delivered again after a restart.
 	IsDurable bool
+	// Directory Location of durable topic logs, one subdirectory per topic.
+	Directory string
+	// SegmentSize Maximum size in bytes of a durable topic log segment file. Segment files are removed once every
+	// consumer group acknowledges their messages.
+	SegmentSize int64
+	// BufferSize Maximum number of messages of a topic which were not acknowledged by every consumer group. Once
+	// reached, publishers block until consumers catch up (backpressure). It is also the number of messages retained
+	// for the first consumer group of a topic, if messages were published before subscribing.
+	BufferSize int
+	// RedeliveryDelay Waiting time before a message is delivered again after a handler failure.
+	RedeliveryDelay time.Duration
+	// MaxDeliveries Maximum number of deliveries of a message per consumer group. Once reached, the message is
+	// routed following the subscriber's gluon.DeadLetterPolicy (Bus.RouteDeadLetter) or discarded if it has none.
+	MaxDeliveries int
+	// SyncWrites Flush durable writes (messages and committed offsets) to stable storage before returning. Otherwise,
+	// durable topic logs survive process crashes but recent writes may be lost on operating system crashes or power
+	// failures.
+	SyncWrites bool
+	// InactiveGroupRetention Waiting time for a consumer group restored from disk to be subscribed again. Once
+	// elapsed, the consumer group and its offset are removed, so it no longer retains messages nor blocks publishers.
+	InactiveGroupRetention time.Duration
+}
+
+func (c Configuration) GetBufferSize() int {
+	if c.BufferSize <= 0 {
+		return defaultBufferSize
+	}
+	return c.BufferSize
+}
+
+func (c Configuration) GetRedeliveryDelay() time.Duration {
+	if c.RedeliveryDelay <= 0 {
+		return defaultRedeliveryDelay
+	}
+	return c.RedeliveryDelay
+}
+
+func (c Configuration) GetMaxDeliveries() int {
+	if c.MaxDeliveries <= 0 {
+		return defaultMaxDeliveries
+	}
+	return c.MaxDeliveries
+}
+
+func (c Configuration) GetDirectory() string {
+	if c.Directory == "" {
+		return defaultDirectory
+	}
+	return c.Directory
+}
+
+func (c Configuration) GetSegmentSize() int64 {
+	if c.SegmentSize <= 0 {
+		return defaultSegmentSize
+	}
+	return c.SegmentSize
+}
+
+func (c Configuration) GetInactiveGroupRetention() time.Duration {
+	if c.InactiveGroupRetention <= 0 {
+		return defaultGroupRetention
+	}
+	return c.InactiveGroupRetention
 }
@@ -0,0 +1,130 @@
+package glocal
+
+import (
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+)
+
+// consumerGroup Is a set of competing consumers of a topicLog sharing a committed offset.
+//
+// Every message is delivered to a single member of the group, while every group of a topic gets every message
+// (fan-out). Failed messages are delivered again up to a maximum number of deliveries.
+type consumerGroup struct {
+	name          string
+	log           *topicLog
+	maxDeliveries int
+	ephemeral     bool // offsets are not persisted
+
+	// inactive indicates the group was restored from disk and has no subscribers yet. Guarded by log.mu
+	inactive bool
+
+	// following fields are guarded by log.mu
+	committed    int64 // every offset below was acknowledged
+	next         int64 // next offset to deliver
+	acked        map[int64]struct{}
+	deliveries   map[int64]int
+	redeliveries []int64
+}
+
+func newConsumerGroup(name string, log *topicLog, offset int64, maxDeliveries int) *consumerGroup {
+	return &consumerGroup{
+		name:          name,
+		log:           log,
+		maxDeliveries: maxDeliveries,
+		committed:     offset,
+		next:          offset,
+		acked:         map[int64]struct{}{},
+		deliveries:    map[int64]int{},
+		redeliveries:  make([]int64, 0),
+	}
+}
+
+// delivery Is a message claimed by a member of a consumerGroup.
+type delivery struct {
+	offset  int64
+	count   int
+	lag     int64
+	message *gluon.TransportMessage
+}
+
+// claim Wait for the next message to deliver. Returns false if the log was closed.
+func (g *consumerGroup) claim() (delivery, bool) {
+	g.log.mu.Lock()
+	defer g.log.mu.Unlock()
+	for {
+		if g.log.closed {
+			return delivery{}, false
+		}
+		var offset int64
+		if len(g.redeliveries) > 0 {
+			offset = g.redeliveries[0]
+			g.redeliveries = g.redeliveries[1:]
+		} else if g.next < g.log.endOffsetLocked() {
+			offset = g.next
+			g.next++
+		} else {
+			g.log.cond.Wait()
+			continue
+		}
+		g.deliveries[offset]++
+		return delivery{
+			offset:  offset,
+			count:   g.deliveries[offset],
+			lag:     g.log.endOffsetLocked() - g.next,
+			message: g.log.messageLocked(offset),
+		}, true
+	}
+}
+
+// ack Acknowledge a message, moving the committed offset forward if possible.
+func (g *consumerGroup) ack(offset int64) error {
+	g.log.mu.Lock()
+	defer g.log.mu.Unlock()
+	return g.ackLocked(offset)
+}
+
+func (g *consumerGroup) ackLocked(offset int64) error {
+	delete(g.deliveries, offset)
+	g.acked[offset] = struct{}{}
+	committed := g.committed
+	for {
+		if _, ok := g.acked[g.committed]; !ok {
+			break
+		}
+		delete(g.acked, g.committed)
+		g.committed++
+	}
+	defer g.log.cond.Broadcast()
+	if g.committed == committed {
+		return nil
+	}
+	if g.log.offsets != nil && !g.ephemeral {
+		if err := g.log.offsets.commit(g.name, g.committed); err != nil {
+			return err
+		}
+	}
+	return g.log.trimLocked()
+}
+
+// nack Schedule a message redelivery after the given delay. Returns false if the message reached the maximum
+// number of deliveries; the message is kept pending so it is either acknowledged (discarded) or redelivered.
+func (g *consumerGroup) nack(offset int64, delay time.Duration) bool {
+	g.log.mu.Lock()
+	defer g.log.mu.Unlock()
+	if g.deliveries[offset] >= g.maxDeliveries {
+		return false
+	}
+	g.redeliver(offset, delay)
+	return true
+}
+
+// redeliver Schedule a message redelivery after the given delay, regardless of its number of deliveries.
+func (g *consumerGroup) redeliver(offset int64, delay time.Duration) {
+	time.AfterFunc(delay, func() {
+		g.log.mu.Lock()
+		defer g.log.mu.Unlock()
+		g.redeliveries = append(g.redeliveries, offset)
+		g.log.cond.Broadcast()
+	})
+}
@@ -2,40 +2,52 @@ package glocal
 
 import (
 	"context"
+	"net/url"
+	"path/filepath"
+	"strconv"
 	"sync"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/neutrinocorp/gluon"
 )
 
+// DriverName Is the name used to register the in-memory driver.
+const DriverName = "local"
+
+// driver Is an in-memory message broker.
+//
+// Every topic is an append-only log. Subscribers sharing a consumer group compete for messages of the topic while
+// every consumer group gets every message (fan-out). Subscribers without consumer group get their own anonymous
+// group.
+//
+// If Configuration.IsDurable is set, topic logs and consumer group offsets are persisted within
+// Configuration.Directory.
 type driver struct {
-	mu              sync.Mutex
-	parentBus       *gluon.Bus
-	topicPartitions map[string]*partition // Key: partition_index#topic_name
-	schedulerBuffer *schedulerBuffer
-	handler         gluon.InternalMessageHandler
-	cfg             Configuration
+	mu         sync.RWMutex
+	parentBus  *gluon.Bus
+	handler    gluon.InternalMessageHandler
+	cfg        Configuration
+	topics     map[string]*topicLog
+	anonymous  int
+	ctx        context.Context
+	cancel     context.CancelFunc
+	consumerWg sync.WaitGroup
 }
 
-var (
-	_               gluon.Driver = &driver{}
-	defaultDriver   *driver
-	driverSingleton = sync.Once{}
-)
+var _ gluon.Driver = &driver{}
 
 func init() {
-	driverSingleton.Do(func() {
-		defaultDriver = &driver{
-			mu:              sync.Mutex{},
-			topicPartitions: map[string]*partition{},
-			schedulerBuffer: newSchedulerBuffer(),
-		}
-		gluon.Register("local", defaultDriver)
+	gluon.Register(DriverName, func() gluon.Driver {
+		return newDriver()
 	})
 }
 
-func (d *driver) Shutdown(_ context.Context) error {
-	d.schedulerBuffer.close()
-	return nil
+func newDriver() *driver {
+	return &driver{
+		mu:     sync.RWMutex{},
+		topics: map[string]*topicLog{},
+		ctx:    context.Background(),
+	}
 }
 
 func (d *driver) SetParentBus(b *gluon.Bus) {
@@ -49,37 +61,143 @@ func (d *driver) SetInternalHandler(h gluon.InternalMessageHandler) {
 	d.handler = h
 }
 
-func (d *driver) Publish(_ context.Context, message *gluon.TransportMessage) error {
+func (d *driver) Start(ctx context.Context) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	topicPartition := d.topicPartitions[message.Topic]
-	if topicPartition == nil {
-		topicPartition = newPartition()
-		d.topicPartitions[message.Topic] = topicPartition
-	}
-	topicPartition.push(message)
-	d.schedulerBuffer.notify(message.Topic)
+	d.ctx, d.cancel = context.WithCancel(ctx)
 	return nil
 }
 
-func (d *driver) Subscribe(_ context.Context, _ *gluon.Subscriber) error {
-	return nil
+func (d *driver) Shutdown(_ context.Context) error {
+	d.mu.Lock()
+	if d.cancel != nil {
+		d.cancel()
+	}
+	errs := new(multierror.Error)
+	for _, l := range d.topics {
+		if err := l.close(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	// topics are reset so the driver may be started again
+	d.topics = map[string]*topicLog{}
+	d.mu.Unlock()
+	d.consumerWg.Wait()
+	return errs.ErrorOrNil()
+}
+
+func (d *driver) Publish(ctx context.Context, message *gluon.TransportMessage) error {
+	l, err := d.getTopicLog(message.Topic)
+	if err != nil {
+		return err
+	}
+	_, err = l.append(ctx, message)
+	return err
 }
 
-func (d *driver) Start(_ context.Context) error {
-	go d.startSubscriberTaskScheduler()
+func (d *driver) Subscribe(_ context.Context, sub *gluon.Subscriber) error {
+	l, err := d.getTopicLog(sub.GetTopic())
+	if err != nil {
+		return err
+	}
+	name, anonymous := d.getConsumerGroupName(sub)
+	// anonymous group names are not stable across restarts, so their offsets are never persisted
+	g, err := l.consumerGroup(name, d.cfg.GetMaxDeliveries(), anonymous)
+	if err != nil {
+		return err
+	}
+	d.startConsumer(g, sub)
 	return nil
 }
 
-func (d *driver) startSubscriberTaskScheduler() {
-	for topic := range d.schedulerBuffer.notificationStream {
-		go func(t string) {
-			if p := defaultDriver.topicPartitions[t]; p != nil {
-				subs := d.parentBus.ListSubscribersFromTopic(t)
-				for _, sub := range subs {
-					_ = d.handler(context.Background(), sub, &p.lastMessage)
-				}
+func (d *driver) getTopicLog(topic string) (*topicLog, error) {
+	d.mu.RLock()
+	l, ok := d.topics[topic]
+	d.mu.RUnlock()
+	if ok {
+		return l, nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if l, ok = d.topics[topic]; ok {
+		return l, nil
+	}
+	if !d.cfg.IsDurable {
+		l = newTopicLog(topic, d.cfg.GetBufferSize())
+	} else {
+		var err error
+		l, err = openDurableTopicLog(topic, filepath.Join(d.cfg.GetDirectory(), url.PathEscape(topic)), d.cfg)
+		if err != nil {
+			return nil, gluon.NewError("LocalFailedOpeningLog", "Failed to open log of topic ("+topic+")", err)
+		}
+		l.expireInactiveGroupsAfter(d.cfg.GetInactiveGroupRetention(), func(err error) {
+			if err != nil {
+				d.parentBus.LogError(gluon.NewError("LocalFailedExpiringGroups",
+					"Failed to remove inactive consumer groups of topic ("+topic+")", err))
 			}
-		}(topic)
+		})
+	}
+	d.topics[topic] = l
+	return l, nil
+}
+
+// getConsumerGroupName Retrieve the consumer group of a subscriber, generating an anonymous group if none was set.
+// Returns true if the group is anonymous.
+func (d *driver) getConsumerGroupName(sub *gluon.Subscriber) (string, bool) {
+	if g := sub.GetGroup(); g != "" {
+		return g, false
+	} else if g = d.parentBus.Configuration.ConsumerGroup; g != "" {
+		return g, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.anonymous++
+	return "anonymous-" + strconv.Itoa(d.anonymous), true
+}
+
+// startConsumer Start a worker which delivers messages of a consumer group to a subscriber.
+func (d *driver) startConsumer(g *consumerGroup, sub *gluon.Subscriber) {
+	d.mu.RLock()
+	ctx := context.WithValue(d.ctx, consumedTopicContextKey{}, g.log)
+	d.mu.RUnlock()
+	d.consumerWg.Add(1)
+	go func() {
+		defer d.consumerWg.Done()
+		for {
+			del, ok := g.claim()
+			if !ok {
+				return
+			}
+			d.parentBus.Configuration.DriverHooks.ReportConsumerLag(DriverName, g.log.name, g.name, 0, del.lag)
+			msg := *del.message
+			msg.DriverHeaders = map[string]string{
+				HeaderOffset:        strconv.FormatInt(del.offset, 10),
+				HeaderDeliveryCount: strconv.Itoa(del.count),
+			}
+			if err := d.handler(ctx, sub, &msg); err == nil {
+				d.parentBus.LogError(g.ack(del.offset))
+				continue
+			}
+			if !g.nack(del.offset, d.cfg.GetRedeliveryDelay()) {
+				d.deadLetter(ctx, g, sub, del)
+			}
+		}
+	}()
+}
+
+// deadLetter Route a message which reached the maximum number of deliveries following the subscriber dead-letter
+// policy, then acknowledge it so it is not delivered again. Messages are redelivered if routing failed.
+func (d *driver) deadLetter(ctx context.Context, g *consumerGroup, sub *gluon.Subscriber, del delivery) {
+	routed, err := d.parentBus.RouteDeadLetter(ctx, sub, del.message, del.count)
+	if err != nil {
+		d.parentBus.LogError(gluon.NewError("LocalFailedRoutingDeadLetter", "Failed to route message ("+
+			del.message.ID+") from topic ("+g.log.name+") to its dead-letter topic", err))
+		g.redeliver(del.offset, d.cfg.GetRedeliveryDelay())
+		return
+	} else if !routed {
+		d.parentBus.LogError(gluon.NewError("LocalMessageDiscarded", "Message ("+del.message.ID+
+			") from topic ("+g.log.name+") reached maximum number of deliveries", nil))
 	}
+	d.parentBus.LogError(g.ack(del.offset))
 }
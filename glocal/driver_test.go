@@ -0,0 +1,406 @@
+package glocal
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deliveryRecorder struct {
+	mu         sync.Mutex
+	deliveries map[string][]*gluon.TransportMessage // Key: consumer_group
+}
+
+func (r *deliveryRecorder) record(group string, msg *gluon.TransportMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[group] = append(r.deliveries[group], msg)
+}
+
+func (r *deliveryRecorder) count(group string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.deliveries[group])
+}
+
+func newTestDriver(t *testing.T, cfg Configuration, h gluon.InternalMessageHandler) *driver {
+	d := newDriver()
+	d.SetParentBus(gluon.NewBus(DriverName, gluon.WithDriverConfiguration(cfg)))
+	d.SetInternalHandler(h)
+	require.NoError(t, d.Start(context.Background()))
+	t.Cleanup(func() {
+		_ = d.Shutdown(context.Background())
+	})
+	return d
+}
+
+func newTestSubscriber(topic, group string) *gluon.Subscriber {
+	return gluon.NewBus(DriverName).SubscribeTopic(topic).Group(group)
+}
+
+func publishTestMessages(t *testing.T, d *driver, topic string, total int) {
+	for i := 0; i < total; i++ {
+		require.NoError(t, d.Publish(context.Background(), &gluon.TransportMessage{
+			ID:    strconv.Itoa(i),
+			Topic: topic,
+		}))
+	}
+}
+
+func TestDriver_ConsumerGroups(t *testing.T) {
+	recorder := &deliveryRecorder{deliveries: map[string][]*gluon.TransportMessage{}}
+	d := newTestDriver(t, Configuration{BufferSize: 8}, func(_ context.Context, sub *gluon.Subscriber,
+		msg *gluon.TransportMessage) error {
+		recorder.record(sub.GetGroup(), msg)
+		return nil
+	})
+	// two competing consumers on group-a and a single consumer on group-b
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-a")))
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-a")))
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-b")))
+
+	// burst larger than the buffer, publishers must wait for consumers instead of losing messages
+	publishTestMessages(t, d, "foo.topic", 100)
+	assert.Eventually(t, func() bool {
+		return recorder.count("group-a") == 100 && recorder.count("group-b") == 100
+	}, time.Second*5, time.Millisecond*10)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	for _, group := range []string{"group-a", "group-b"} {
+		offsets := map[string]struct{}{}
+		for _, msg := range recorder.deliveries[group] {
+			offsets[msg.DriverHeaders[HeaderOffset]] = struct{}{}
+			assert.Equal(t, "1", msg.DriverHeaders[HeaderDeliveryCount])
+		}
+		assert.Len(t, offsets, 100, "every message must be delivered once per consumer group")
+	}
+}
+
+func TestDriver_Redelivery(t *testing.T) {
+	var mu sync.Mutex
+	deliveries := make([]string, 0)
+	d := newTestDriver(t, Configuration{RedeliveryDelay: time.Millisecond, MaxDeliveries: 3},
+		func(_ context.Context, _ *gluon.Subscriber, msg *gluon.TransportMessage) error {
+			mu.Lock()
+			defer mu.Unlock()
+			deliveries = append(deliveries, msg.ID+"#"+msg.DriverHeaders[HeaderDeliveryCount])
+			if msg.ID == "0" {
+				return errors.New("handler error")
+			}
+			return nil
+		})
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-a")))
+	publishTestMessages(t, d, "foo.topic", 2)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(deliveries) == 4
+	}, time.Second*5, time.Millisecond*10)
+	mu.Lock()
+	assert.ElementsMatch(t, []string{"0#1", "0#2", "0#3", "1#1"}, deliveries)
+	mu.Unlock()
+
+	// discarded messages must be acknowledged
+	l, err := d.getTopicLog("foo.topic")
+	require.NoError(t, err)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	assert.Equal(t, 0, l.pendingLocked())
+}
+
+func TestDriver_RedeliveryDeadLetter(t *testing.T) {
+	var mu sync.Mutex
+	routed := make([]*gluon.TransportMessage, 0)
+	failRouting := true
+	d := newDriver()
+	d.SetParentBus(gluon.NewBus(DriverName,
+		gluon.WithDriverConfiguration(Configuration{RedeliveryDelay: time.Millisecond, MaxDeliveries: 2}),
+		gluon.WithDeadLetterPolicy(gluon.DeadLetterPolicy{}),
+		gluon.WithPublisherMiddleware(func(_ gluon.PublisherFunc) gluon.PublisherFunc {
+			return func(_ context.Context, msg *gluon.TransportMessage) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if failRouting {
+					// messages are redelivered if routing failed
+					failRouting = false
+					return errors.New("dead-letter topic unavailable")
+				}
+				routed = append(routed, msg)
+				return nil
+			}
+		})))
+	deliveries := make([]string, 0)
+	d.SetInternalHandler(func(_ context.Context, _ *gluon.Subscriber, msg *gluon.TransportMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		deliveries = append(deliveries, msg.ID+"#"+msg.DriverHeaders[HeaderDeliveryCount])
+		return errors.New("handler error")
+	})
+	require.NoError(t, d.Start(context.Background()))
+	t.Cleanup(func() {
+		_ = d.Shutdown(context.Background())
+	})
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-a")))
+	publishTestMessages(t, d, "foo.topic", 1)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(routed) == 1
+	}, time.Second*5, time.Millisecond*10)
+	mu.Lock()
+	assert.Equal(t, []string{"0#1", "0#2", "0#3"}, deliveries)
+	assert.Equal(t, "foo.topic.dlq", routed[0].Topic)
+	assert.Equal(t, "0", routed[0].ID)
+	assert.Equal(t, "3", routed[0].Extensions[gluon.ExtensionFailedAttempts])
+	assert.Equal(t, gluon.ErrMaxDeliveriesExceeded.Error(), routed[0].Extensions[gluon.ExtensionFailureReason])
+	mu.Unlock()
+
+	// routed messages must be acknowledged
+	l, err := d.getTopicLog("foo.topic")
+	require.NoError(t, err)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	assert.Equal(t, 0, l.pendingLocked())
+}
+
+func TestDriver_Backpressure(t *testing.T) {
+	release := make(chan struct{})
+	d := newTestDriver(t, Configuration{BufferSize: 2}, func(_ context.Context, _ *gluon.Subscriber,
+		_ *gluon.TransportMessage) error {
+		<-release
+		return nil
+	})
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-a")))
+	publishTestMessages(t, d, "foo.topic", 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	err := d.Publish(ctx, &gluon.TransportMessage{ID: "2", Topic: "foo.topic"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, d.Publish(context.Background(), &gluon.TransportMessage{ID: "3", Topic: "foo.topic"}))
+}
+
+func TestDriver_PublishWithoutConsumerGroups(t *testing.T) {
+	recorder := &deliveryRecorder{deliveries: map[string][]*gluon.TransportMessage{}}
+	d := newTestDriver(t, Configuration{BufferSize: 2}, func(_ context.Context, sub *gluon.Subscriber,
+		msg *gluon.TransportMessage) error {
+		recorder.record(sub.GetGroup(), msg)
+		return nil
+	})
+	// publishers must not block if nobody is listening, the latest messages are retained instead
+	publishTestMessages(t, d, "foo.topic", 10)
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-a")))
+	assert.Eventually(t, func() bool {
+		return recorder.count("group-a") == 2
+	}, time.Second*5, time.Millisecond*10)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	assert.Equal(t, "8", recorder.deliveries["group-a"][0].ID)
+	assert.Equal(t, "9", recorder.deliveries["group-a"][1].ID)
+}
+
+func TestDriver_PublishFromHandler(t *testing.T) {
+	errs := make(chan error, 1)
+	var d *driver
+	d = newTestDriver(t, Configuration{BufferSize: 1}, func(ctx context.Context, _ *gluon.Subscriber,
+		msg *gluon.TransportMessage) error {
+		if msg.ID == "0" {
+			// the handled message fills the log, waiting for its acknowledgement would deadlock
+			errs <- d.Publish(ctx, &gluon.TransportMessage{ID: "1", Topic: "foo.topic"})
+		}
+		return nil
+	})
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-a")))
+	publishTestMessages(t, d, "foo.topic", 1)
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrTopicLogFull)
+	case <-time.After(time.Second * 5):
+		t.Fatal("handler publishing to its own topic blocked")
+	}
+}
+
+func TestDriver_Shutdown(t *testing.T) {
+	d := newTestDriver(t, Configuration{}, func(_ context.Context, _ *gluon.Subscriber,
+		_ *gluon.TransportMessage) error {
+		return nil
+	})
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-a")))
+	l, err := d.getTopicLog("foo.topic")
+	require.NoError(t, err)
+	require.NoError(t, d.Shutdown(context.Background()))
+	_, err = l.append(context.Background(), &gluon.TransportMessage{ID: "0", Topic: "foo.topic"})
+	assert.ErrorIs(t, err, gluon.ErrBusClosed)
+}
+
+func TestDriver_Durable(t *testing.T) {
+	cfg := Configuration{
+		IsDurable:       true,
+		Directory:       t.TempDir(),
+		SegmentSize:     256,
+		RedeliveryDelay: time.Minute,
+	}
+	var mu sync.Mutex
+	handled := make([]string, 0)
+	d := newTestDriver(t, cfg, func(_ context.Context, _ *gluon.Subscriber, msg *gluon.TransportMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if msg.ID == "5" {
+			// simulate a crash while processing messages
+			return errors.New("handler error")
+		}
+		handled = append(handled, msg.ID)
+		return nil
+	})
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-a")))
+	publishTestMessages(t, d, "foo.topic", 10)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) == 9
+	}, time.Second*5, time.Millisecond*10)
+	require.NoError(t, d.Shutdown(context.Background()))
+
+	// messages from the first unacknowledged offset must be delivered again after a restart
+	replayed := make(chan string, 10)
+	d = newTestDriver(t, cfg, func(_ context.Context, _ *gluon.Subscriber, msg *gluon.TransportMessage) error {
+		assert.Equal(t, "foo.topic", msg.Topic)
+		replayed <- msg.ID
+		return nil
+	})
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-a")))
+	for _, id := range []string{"5", "6", "7", "8", "9"} {
+		select {
+		case got := <-replayed:
+			assert.Equal(t, id, got)
+		case <-time.After(time.Second * 5):
+			t.Fatal("message was not replayed")
+		}
+	}
+	publishTestMessages(t, d, "foo.topic", 1)
+	select {
+	case got := <-replayed:
+		assert.Equal(t, "0", got)
+	case <-time.After(time.Second * 5):
+		t.Fatal("message was not consumed")
+	}
+}
+
+func readTestOffsets(t *testing.T, dir string) map[string]int64 {
+	offsets, err := openOffsetStore(filepath.Join(dir, "foo.topic"), false)
+	require.NoError(t, err)
+	return offsets.offsets
+}
+
+func TestDriver_DurableInactiveGroups(t *testing.T) {
+	cfg := Configuration{
+		IsDurable:              true,
+		Directory:              t.TempDir(),
+		BufferSize:             2,
+		SyncWrites:             true,
+		InactiveGroupRetention: time.Millisecond * 100,
+	}
+	handled := make(chan string, 2)
+	d := newTestDriver(t, cfg, func(_ context.Context, _ *gluon.Subscriber, msg *gluon.TransportMessage) error {
+		handled <- msg.ID
+		return nil
+	})
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "group-a")))
+	require.NoError(t, d.Subscribe(context.Background(), newTestSubscriber("foo.topic", "")))
+	publishTestMessages(t, d, "foo.topic", 1)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-handled:
+		case <-time.After(time.Second * 5):
+			t.Fatal("message was not consumed")
+		}
+	}
+	require.NoError(t, d.Shutdown(context.Background()))
+	// anonymous groups are not stable across restarts
+	assert.Equal(t, map[string]int64{"group-a": 1}, readTestOffsets(t, cfg.Directory))
+
+	// group-a is not subscribed again, so it stops retaining messages once its retention elapses
+	d = newTestDriver(t, cfg, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, d.Publish(ctx, &gluon.TransportMessage{ID: strconv.Itoa(i), Topic: "foo.topic"}))
+	}
+	assert.Empty(t, readTestOffsets(t, cfg.Directory))
+}
+
+type itemPaid struct {
+	ItemID string `json:"item_id"`
+}
+
+func TestDriver_IndependentBuses(t *testing.T) {
+	newTestBus := func(received chan<- string) *gluon.Bus {
+		bus := gluon.NewBus(DriverName)
+		bus.RegisterSchema(itemPaid{}, gluon.WithTopic("foo.topic"))
+		bus.Subscribe(itemPaid{}).HandlerFunc(func(_ context.Context, msg *gluon.Message) error {
+			received <- msg.Data.(itemPaid).ItemID
+			return nil
+		})
+		require.NoError(t, bus.ListenAndServe())
+		t.Cleanup(func() {
+			_ = bus.Shutdown(context.Background())
+		})
+		return bus
+	}
+	receivedA, receivedB := make(chan string, 1), make(chan string, 1)
+	busA, busB := newTestBus(receivedA), newTestBus(receivedB)
+
+	require.NoError(t, busA.Publish(context.Background(), itemPaid{ItemID: "a"}))
+	require.NoError(t, busB.Publish(context.Background(), itemPaid{ItemID: "b"}))
+	for _, tt := range []struct {
+		received <-chan string
+		want     string
+	}{{receivedA, "a"}, {receivedB, "b"}} {
+		select {
+		case got := <-tt.received:
+			assert.Equal(t, tt.want, got)
+		case <-time.After(time.Second * 5):
+			t.Fatal("message was not consumed")
+		}
+	}
+	assert.Len(t, receivedA, 0)
+	assert.Len(t, receivedB, 0)
+}
+
+func TestDriver_Extensions(t *testing.T) {
+	bus := gluon.NewBus(DriverName)
+	bus.RegisterSchema(itemPaid{}, gluon.WithTopic("foo.topic"))
+	received := make(chan *gluon.Message, 1)
+	bus.Subscribe(itemPaid{}).HandlerFunc(func(_ context.Context, msg *gluon.Message) error {
+		received <- msg
+		return nil
+	})
+	require.NoError(t, bus.ListenAndServe())
+	t.Cleanup(func() {
+		_ = bus.Shutdown(context.Background())
+	})
+
+	require.NoError(t, bus.Publish(context.Background(), itemPaid{ItemID: "a"},
+		gluon.WithSubject("a"), gluon.WithExtension("tenantid", "neutrino")))
+	select {
+	case msg := <-received:
+		assert.Equal(t, "a", msg.GetSubject())
+		assert.Equal(t, "neutrino", msg.GetExtension("tenantid"))
+	case <-time.After(time.Second * 5):
+		t.Fatal("message was not consumed")
+	}
+}
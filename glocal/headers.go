@@ -0,0 +1,7 @@
+package glocal
+
+// In-memory driver custom gluon headers
+const (
+	HeaderOffset        = "local-offset"
+	HeaderDeliveryCount = "local-delivery-count"
+)
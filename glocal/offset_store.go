@@ -0,0 +1,95 @@
+package glocal
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const offsetFileName = "offsets.json"
+
+// offsetStore Persists the committed offset of every consumer group of a topic.
+//
+// Offsets are written to a temporary file which then replaces the previous one, so a crash never leaves a partially
+// written file behind. The whole file is rewritten every time a committed offset moves forward. Files are only
+// flushed to stable storage if sync is set.
+type offsetStore struct {
+	path    string
+	sync    bool
+	offsets map[string]int64 // Key: consumer_group
+}
+
+func openOffsetStore(dir string, sync bool) (*offsetStore, error) {
+	s := &offsetStore{
+		path:    filepath.Join(dir, offsetFileName),
+		sync:    sync,
+		offsets: map[string]int64{},
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &s.offsets); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// commit Persist the committed offset of a consumer group.
+func (s *offsetStore) commit(group string, offset int64) error {
+	s.offsets[group] = offset
+	return s.write()
+}
+
+// remove Delete the committed offsets of the given consumer groups.
+func (s *offsetStore) remove(groups ...string) error {
+	for _, group := range groups {
+		delete(s.offsets, group)
+	}
+	return s.write()
+}
+
+func (s *offsetStore) write() error {
+	data, err := json.Marshal(s.offsets)
+	if err != nil {
+		return err
+	}
+	tmpPath := s.path + ".tmp"
+	if err = writeFile(tmpPath, data, s.sync); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, s.path); err != nil {
+		return err
+	}
+	if s.sync {
+		return syncDir(filepath.Dir(s.path))
+	}
+	return nil
+}
+
+func writeFile(path string, data []byte, sync bool) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil && sync {
+		err = f.Sync()
+	}
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
+// syncDir Flush a directory entry changes (e.g. renamed or created files) to stable storage.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
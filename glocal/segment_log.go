@@ -0,0 +1,261 @@
+package glocal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/neutrinocorp/gluon"
+)
+
+const (
+	segmentFileExtension = ".log"
+	// recordHeaderSize Length (uint32) and CRC-32 checksum (uint32) of a record payload.
+	recordHeaderSize = 8
+)
+
+// ErrCorruptedSegment A segment file contains an invalid record which is not at the end of the log.
+var ErrCorruptedSegment = errors.New("gluon: The local log segment is corrupted")
+
+// segmentLog Is an append-only log persisted as a sequence of segment files within a directory.
+//
+// Each segment file is named after the offset of its first record. Records are stored as a header (payload length
+// and CRC-32 checksum) followed by the JSON-encoded gluon.TransportMessage. Incomplete or corrupted records at the
+// end of the last segment (e.g. a crash during a write) are discarded when the log is opened.
+//
+// Records are only flushed to stable storage before append returns if sync is set.
+type segmentLog struct {
+	dir         string
+	segmentSize int64
+	sync        bool
+
+	segments   []*segment
+	active     *os.File
+	nextOffset int64
+}
+
+type segment struct {
+	baseOffset int64
+	total      int64
+	size       int64
+	path       string
+}
+
+func openSegmentLog(dir string, segmentSize int64, sync bool) (*segmentLog, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	l := &segmentLog{
+		dir:         dir,
+		segmentSize: segmentSize,
+		sync:        sync,
+		segments:    make([]*segment, 0),
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), segmentFileExtension) {
+			continue
+		}
+		baseOffset, errParse := strconv.ParseInt(strings.TrimSuffix(entry.Name(), segmentFileExtension), 10, 64)
+		if errParse != nil {
+			continue
+		}
+		l.segments = append(l.segments, &segment{
+			baseOffset: baseOffset,
+			path:       filepath.Join(dir, entry.Name()),
+		})
+	}
+	sort.Slice(l.segments, func(i, j int) bool {
+		return l.segments[i].baseOffset < l.segments[j].baseOffset
+	})
+	if err = l.recover(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// recover Count records of every segment, truncating the tail of the last segment if it is incomplete.
+func (l *segmentLog) recover() error {
+	for i, seg := range l.segments {
+		isLast := i == len(l.segments)-1
+		total, validSize, err := scanSegment(seg.path, nil)
+		if err == ErrCorruptedSegment && isLast {
+			// discard incomplete records
+			if err = os.Truncate(seg.path, validSize); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+		seg.total, seg.size = total, validSize
+		l.nextOffset = seg.baseOffset + total
+	}
+	if len(l.segments) == 0 {
+		return l.roll()
+	}
+	last := l.segments[len(l.segments)-1]
+	f, err := os.OpenFile(last.path, os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	l.active = f
+	return nil
+}
+
+// roll Create a new active segment starting at the next offset.
+func (l *segmentLog) roll() error {
+	if l.active != nil {
+		if err := l.active.Close(); err != nil {
+			return err
+		}
+	}
+	path := filepath.Join(l.dir, fmt.Sprintf("%020d%s", l.nextOffset, segmentFileExtension))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	l.active = f
+	l.segments = append(l.segments, &segment{
+		baseOffset: l.nextOffset,
+		path:       path,
+	})
+	if l.sync {
+		return syncDir(l.dir)
+	}
+	return nil
+}
+
+// append Write a message at the end of the log. Returns the offset of the message.
+func (l *segmentLog) append(msg *gluon.TransportMessage) (int64, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return 0, err
+	}
+	active := l.segments[len(l.segments)-1]
+	if active.size > 0 && active.size+recordHeaderSize+int64(len(payload)) > l.segmentSize {
+		if err = l.roll(); err != nil {
+			return 0, err
+		}
+		active = l.segments[len(l.segments)-1]
+	}
+
+	record := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[recordHeaderSize:], payload)
+	if _, err = l.active.Write(record); err != nil {
+		return 0, err
+	}
+	if l.sync {
+		if err = l.active.Sync(); err != nil {
+			return 0, err
+		}
+	}
+	active.size += int64(len(record))
+	active.total++
+	offset := l.nextOffset
+	l.nextOffset++
+	return offset, nil
+}
+
+// readFrom Retrieve every message starting from the given offset.
+func (l *segmentLog) readFrom(offset int64) ([]*gluon.TransportMessage, error) {
+	msgs := make([]*gluon.TransportMessage, 0)
+	for _, seg := range l.segments {
+		if seg.baseOffset+seg.total <= offset {
+			continue
+		}
+		current := seg.baseOffset
+		_, _, err := scanSegment(seg.path, func(msg *gluon.TransportMessage) {
+			if current >= offset && current < seg.baseOffset+seg.total {
+				msgs = append(msgs, msg)
+			}
+			current++
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return msgs, nil
+}
+
+// truncateBefore Remove segments whose records are all below the given offset. The active segment is always kept.
+func (l *segmentLog) truncateBefore(offset int64) error {
+	for len(l.segments) > 1 {
+		seg := l.segments[0]
+		if seg.baseOffset+seg.total > offset {
+			return nil
+		}
+		if err := os.Remove(seg.path); err != nil {
+			return err
+		}
+		l.segments = l.segments[1:]
+	}
+	return nil
+}
+
+func (l *segmentLog) close() error {
+	if l.active == nil {
+		return nil
+	}
+	err := l.active.Close()
+	l.active = nil
+	return err
+}
+
+// scanSegment Read every valid record of a segment file. Returns the total of valid records and their size in bytes,
+// along with ErrCorruptedSegment if an invalid record was found.
+func scanSegment(path string, fn func(*gluon.TransportMessage)) (int64, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	reader := bufio.NewReader(f)
+	header := make([]byte, recordHeaderSize)
+	var total, size int64
+	for {
+		if _, err = io.ReadFull(reader, header); err == io.EOF {
+			return total, size, nil
+		} else if err != nil {
+			return total, size, ErrCorruptedSegment
+		}
+		length := int64(binary.BigEndian.Uint32(header[0:4]))
+		if length > info.Size()-size-recordHeaderSize {
+			return total, size, ErrCorruptedSegment
+		}
+		payload := make([]byte, length)
+		if _, err = io.ReadFull(reader, payload); err != nil {
+			return total, size, ErrCorruptedSegment
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+			return total, size, ErrCorruptedSegment
+		}
+		if fn != nil {
+			msg := new(gluon.TransportMessage)
+			if err = json.Unmarshal(payload, msg); err != nil {
+				return total, size, ErrCorruptedSegment
+			}
+			fn(msg)
+		}
+		total++
+		size += int64(len(payload)) + recordHeaderSize
+	}
+}
@@ -0,0 +1,89 @@
+package glocal
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func appendTestRecords(t *testing.T, l *segmentLog, from, to int) {
+	for i := from; i < to; i++ {
+		offset, err := l.append(&gluon.TransportMessage{ID: strconv.Itoa(i)})
+		require.NoError(t, err)
+		assert.Equal(t, int64(i), offset)
+	}
+}
+
+func TestSegmentLog(t *testing.T) {
+	dir := t.TempDir()
+	l, err := openSegmentLog(dir, 128, false)
+	require.NoError(t, err)
+	appendTestRecords(t, l, 0, 10)
+	assert.Greater(t, len(l.segments), 1)
+
+	msgs, err := l.readFrom(7)
+	require.NoError(t, err)
+	if assert.Len(t, msgs, 3) {
+		assert.Equal(t, "7", msgs[0].ID)
+		assert.Equal(t, "9", msgs[2].ID)
+	}
+
+	require.NoError(t, l.truncateBefore(7))
+	assert.LessOrEqual(t, l.segments[0].baseOffset, int64(7))
+	require.NoError(t, l.close())
+
+	// offsets must be restored after reopening the log
+	l, err = openSegmentLog(dir, 128, false)
+	require.NoError(t, err)
+	defer l.close()
+	assert.Equal(t, int64(10), l.nextOffset)
+	appendTestRecords(t, l, 10, 12)
+	msgs, err = l.readFrom(9)
+	require.NoError(t, err)
+	assert.Len(t, msgs, 3)
+}
+
+func TestSegmentLog_Recovery(t *testing.T) {
+	dir := t.TempDir()
+	l, err := openSegmentLog(dir, 1<<20, false)
+	require.NoError(t, err)
+	appendTestRecords(t, l, 0, 3)
+	require.NoError(t, l.close())
+
+	// simulate an incomplete write at the end of the log
+	f, err := os.OpenFile(l.segments[0].path, os.O_WRONLY|os.O_APPEND, 0o640)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0, 42, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	l, err = openSegmentLog(dir, 1<<20, false)
+	require.NoError(t, err)
+	defer l.close()
+	assert.Equal(t, int64(3), l.nextOffset)
+	appendTestRecords(t, l, 3, 4)
+	msgs, err := l.readFrom(0)
+	require.NoError(t, err)
+	assert.Len(t, msgs, 4)
+}
+
+func TestSegmentLog_CorruptedSegment(t *testing.T) {
+	dir := t.TempDir()
+	l, err := openSegmentLog(dir, 128, false)
+	require.NoError(t, err)
+	appendTestRecords(t, l, 0, 10)
+	require.NoError(t, l.close())
+
+	// flip a payload byte of a sealed segment
+	data, err := os.ReadFile(l.segments[0].path)
+	require.NoError(t, err)
+	data[recordHeaderSize] ^= 0xff
+	require.NoError(t, os.WriteFile(l.segments[0].path, data, 0o640))
+
+	_, err = openSegmentLog(dir, 128, false)
+	assert.ErrorIs(t, err, ErrCorruptedSegment)
+}
@@ -0,0 +1,283 @@
+package glocal
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+)
+
+// ErrTopicLogFull A handler published to the topic it consumes while the topic log was full. Waiting would deadlock
+// as the handled message is not acknowledged until the handler returns.
+var ErrTopicLogFull = errors.New("gluon: The local topic log is full")
+
+// consumedTopicContextKey Is the context key of the topicLog consumed by a handler.
+type consumedTopicContextKey struct{}
+
+// topicLog Is an append-only log of messages for a single topic.
+//
+// Messages are kept until every consumer group of the topic acknowledges them. The number of kept messages is
+// bounded, so publishers block until consumer groups catch up (backpressure). Handlers publishing to the topic they
+// consume get ErrTopicLogFull instead of blocking.
+//
+// While a topic has no consumer groups, the latest messages (up to capacity) are retained and delivered to the first
+// consumer group, so messages published before subscribers start are not lost. Further consumer groups start from
+// the end of the log.
+//
+// A durable topicLog also writes messages and consumer group offsets to disk, so messages which were not
+// acknowledged are delivered again after a restart.
+type topicLog struct {
+	name     string
+	capacity int
+	segments *segmentLog  // nil if not durable
+	offsets  *offsetStore // nil if not durable
+
+	mu         sync.Mutex
+	cond       *sync.Cond
+	closed     bool
+	expiry     *time.Timer // removes restored consumer groups without subscribers, nil if not durable
+	baseOffset int64       // offset of messages[0]
+	messages   []*gluon.TransportMessage
+	groups     map[string]*consumerGroup
+}
+
+func newTopicLog(name string, capacity int) *topicLog {
+	l := &topicLog{
+		name:     name,
+		capacity: capacity,
+		mu:       sync.Mutex{},
+		messages: make([]*gluon.TransportMessage, 0),
+		groups:   map[string]*consumerGroup{},
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// openDurableTopicLog Open a durable topicLog from a directory, restoring consumer groups and messages which were not
+// acknowledged by them.
+//
+// Restored consumer groups which are not subscribed again within Configuration.InactiveGroupRetention are removed
+// (see expireInactiveGroups), so they stop retaining messages.
+func openDurableTopicLog(name string, dir string, cfg Configuration) (*topicLog, error) {
+	segments, err := openSegmentLog(dir, cfg.GetSegmentSize(), cfg.SyncWrites)
+	if err != nil {
+		return nil, err
+	}
+	offsets, err := openOffsetStore(dir, cfg.SyncWrites)
+	if err != nil {
+		_ = segments.close()
+		return nil, err
+	}
+
+	capacity := cfg.GetBufferSize()
+	l := newTopicLog(name, capacity)
+	l.segments, l.offsets = segments, offsets
+	l.baseOffset = segments.nextOffset
+	for group, offset := range offsets.offsets {
+		// offsets are bounded to the records available on disk
+		if first := segments.segments[0].baseOffset; offset < first {
+			offset = first
+		} else if offset > segments.nextOffset {
+			offset = segments.nextOffset
+		}
+		g := newConsumerGroup(group, l, offset, cfg.GetMaxDeliveries())
+		g.inactive = true
+		l.groups[group] = g
+		if offset < l.baseOffset {
+			l.baseOffset = offset
+		}
+	}
+	if len(l.groups) == 0 {
+		// without consumer groups, the latest messages are retained (see topicLog)
+		l.baseOffset = segments.nextOffset - int64(capacity)
+		if first := segments.segments[0].baseOffset; l.baseOffset < first {
+			l.baseOffset = first
+		}
+	}
+	if l.messages, err = segments.readFrom(l.baseOffset); err != nil {
+		_ = segments.close()
+		return nil, err
+	}
+	for _, msg := range l.messages {
+		msg.Topic = name
+	}
+	return l, nil
+}
+
+// append Add a message at the end of the log, blocking while the log is full.
+func (l *topicLog) append(ctx context.Context, msg *gluon.TransportMessage) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for !l.closed && l.pendingLocked() >= l.capacity {
+		if consumed, _ := ctx.Value(consumedTopicContextKey{}).(*topicLog); consumed == l {
+			return 0, ErrTopicLogFull
+		}
+		if err := l.waitLocked(ctx); err != nil {
+			return 0, err
+		}
+	}
+	if l.closed {
+		return 0, gluon.ErrBusClosed
+	}
+
+	msgCopy := *msg
+	msgCopy.DriverHeaders = nil
+	offset := l.endOffsetLocked()
+	if l.segments != nil {
+		if _, err := l.segments.append(&msgCopy); err != nil {
+			return 0, err
+		}
+	}
+	l.messages = append(l.messages, &msgCopy)
+	if len(l.groups) == 0 {
+		// drops messages beyond the retained ones. The message was already written, so a trimming failure is not
+		// reported to the publisher, segments are removed by the next trimming operation instead
+		_ = l.trimLocked()
+	}
+	l.cond.Broadcast()
+	return offset, nil
+}
+
+// waitLocked Wait for a log state change or ctx cancellation. Mutex must be locked.
+func (l *topicLog) waitLocked(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			l.mu.Lock()
+			l.cond.Broadcast()
+			l.mu.Unlock()
+		case <-stop:
+		}
+	}()
+	l.cond.Wait()
+	return ctx.Err()
+}
+
+// consumerGroup Retrieve a consumer group of the topic, creating it if necessary. New consumer groups start from
+// the end of the log, except the first one which starts from the retained messages.
+//
+// Offsets of ephemeral consumer groups (e.g. anonymous groups) are never persisted.
+func (l *topicLog) consumerGroup(name string, maxDeliveries int, ephemeral bool) (*consumerGroup, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if g, ok := l.groups[name]; ok {
+		g.inactive = false
+		return g, nil
+	}
+	offset := l.endOffsetLocked()
+	if len(l.groups) == 0 {
+		offset = l.baseOffset
+	}
+	g := newConsumerGroup(name, l, offset, maxDeliveries)
+	g.ephemeral = ephemeral
+	if l.offsets != nil && !ephemeral {
+		// persist the starting offset, so messages published before a restart are not skipped
+		if err := l.offsets.commit(name, g.committed); err != nil {
+			return nil, err
+		}
+	}
+	l.groups[name] = g
+	return g, nil
+}
+
+// expireInactiveGroupsAfter Schedule the removal of restored consumer groups which were not subscribed again.
+func (l *topicLog) expireInactiveGroupsAfter(retention time.Duration, onErr func(error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expiry = time.AfterFunc(retention, func() {
+		onErr(l.expireInactiveGroups())
+	})
+}
+
+// expireInactiveGroups Remove restored consumer groups which were not subscribed again along their offsets, so they
+// stop retaining messages.
+func (l *topicLog) expireInactiveGroups() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	expired := make([]string, 0)
+	for name, g := range l.groups {
+		if g.inactive {
+			delete(l.groups, name)
+			expired = append(expired, name)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	defer l.cond.Broadcast()
+	if l.offsets != nil {
+		if err := l.offsets.remove(expired...); err != nil {
+			return err
+		}
+	}
+	return l.trimLocked()
+}
+
+func (l *topicLog) endOffsetLocked() int64 {
+	return l.baseOffset + int64(len(l.messages))
+}
+
+func (l *topicLog) messageLocked(offset int64) *gluon.TransportMessage {
+	return l.messages[offset-l.baseOffset]
+}
+
+// pendingLocked Number of messages which were not acknowledged by every consumer group.
+func (l *topicLog) pendingLocked() int {
+	return int(l.endOffsetLocked() - l.minCommittedLocked())
+}
+
+func (l *topicLog) minCommittedLocked() int64 {
+	minCommitted := l.endOffsetLocked()
+	for _, g := range l.groups {
+		if g.committed < minCommitted {
+			minCommitted = g.committed
+		}
+	}
+	return minCommitted
+}
+
+// trimLocked Remove messages acknowledged by every consumer group, or messages beyond capacity if the topic has no
+// consumer groups.
+func (l *topicLog) trimLocked() error {
+	minCommitted := l.minCommittedLocked()
+	if len(l.groups) == 0 {
+		minCommitted = l.endOffsetLocked() - int64(l.capacity)
+	}
+	if minCommitted <= l.baseOffset {
+		return nil
+	}
+	trimmed := int(minCommitted - l.baseOffset)
+	for i := 0; i < trimmed; i++ {
+		l.messages[i] = nil // release memory
+	}
+	l.messages = l.messages[trimmed:]
+	l.baseOffset = minCommitted
+	if l.segments != nil {
+		return l.segments.truncateBefore(minCommitted)
+	}
+	return nil
+}
+
+func (l *topicLog) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	l.cond.Broadcast()
+	if l.expiry != nil {
+		l.expiry.Stop()
+	}
+	if l.segments != nil {
+		return l.segments.close()
+	}
+	return nil
+}
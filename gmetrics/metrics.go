@@ -0,0 +1,186 @@
+package gmetrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/neutrinocorp/gluon"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	labelTopic         = "topic"
+	labelConsumerGroup = "consumer_group"
+	labelDriver        = "driver"
+	labelPartition     = "partition"
+)
+
+var (
+	publisherLabels = []string{labelTopic, labelDriver}
+	consumerLabels  = []string{labelTopic, labelConsumerGroup, labelDriver}
+)
+
+// Metrics Is a set of Prometheus collectors for `Gluon` publishers, consumers and drivers.
+//
+// Use PublisherMiddleware, ConsumerMiddleware and DriverHooks to attach Metrics to a Bus:
+//
+//	metrics, _ := gmetrics.New(gmetrics.WithRegisterer(registry))
+//	bus := gluon.NewBus("kafka",
+//		gluon.WithPublisherMiddleware(metrics.PublisherMiddleware),
+//		gluon.WithConsumerMiddleware(metrics.ConsumerMiddleware),
+//		gluon.WithDriverHooks(metrics.DriverHooks()))
+type Metrics struct {
+	published       *prometheus.CounterVec
+	publishFailed   *prometheus.CounterVec
+	consumed        *prometheus.CounterVec
+	failed          *prometheus.CounterVec
+	retried         *prometheus.CounterVec
+	handlerDuration *prometheus.HistogramVec
+	inFlight        *prometheus.GaugeVec
+	consumerLag     *prometheus.GaugeVec
+	polledMessages  *prometheus.GaugeVec
+}
+
+// New Allocate Metrics and register its collectors.
+func New(opts ...Option) (*Metrics, error) {
+	options := newDefaultOptions()
+	for _, o := range opts {
+		o.apply(&options)
+	}
+	m := &Metrics{
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.namespace,
+			Name:      "messages_published_total",
+			Help:      "Total number of messages published.",
+		}, publisherLabels),
+		publishFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.namespace,
+			Name:      "messages_publish_failed_total",
+			Help:      "Total number of messages which failed to be published.",
+		}, publisherLabels),
+		consumed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.namespace,
+			Name:      "messages_consumed_total",
+			Help:      "Total number of handler executions, including retries.",
+		}, consumerLabels),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.namespace,
+			Name:      "messages_failed_total",
+			Help:      "Total number of failed handler executions.",
+		}, consumerLabels),
+		retried: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.namespace,
+			Name:      "messages_retried_total",
+			Help:      "Total number of handler executions retried by a retry policy.",
+		}, consumerLabels),
+		handlerDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: options.namespace,
+			Name:      "handler_duration_seconds",
+			Help:      "Latency of handler executions.",
+			Buckets:   options.buckets,
+		}, consumerLabels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: options.namespace,
+			Name:      "messages_in_flight",
+			Help:      "Number of messages being handled.",
+		}, consumerLabels),
+		consumerLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: options.namespace,
+			Name:      "consumer_lag",
+			Help:      "Number of messages a consumer is behind the latest message of a topic partition.",
+		}, []string{labelTopic, labelConsumerGroup, labelDriver, labelPartition}),
+		polledMessages: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: options.namespace,
+			Name:      "messages_polled_per_batch",
+			Help:      "Number of messages received by the latest polling operation.",
+		}, consumerLabels),
+	}
+
+	errs := new(multierror.Error)
+	for _, c := range m.collectors() {
+		if err := options.registerer.Register(c); err != nil {
+			errs = multierror.Append(err, errs)
+		}
+	}
+	return m, errs.ErrorOrNil()
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.published,
+		m.publishFailed,
+		m.consumed,
+		m.failed,
+		m.retried,
+		m.handlerDuration,
+		m.inFlight,
+		m.consumerLag,
+		m.polledMessages,
+	}
+}
+
+// PublisherMiddleware Record published messages. Use it along gluon.WithPublisherMiddleware.
+func (m *Metrics) PublisherMiddleware(next gluon.PublisherFunc) gluon.PublisherFunc {
+	return func(ctx context.Context, msg *gluon.TransportMessage) error {
+		labels := prometheus.Labels{
+			labelTopic:  msg.Topic,
+			labelDriver: gluon.DriverFromContext(ctx),
+		}
+		if err := next(ctx, msg); err != nil {
+			m.publishFailed.With(labels).Inc()
+			return err
+		}
+		m.published.With(labels).Inc()
+		return nil
+	}
+}
+
+// ConsumerMiddleware Record handler executions. Use it along gluon.WithConsumerMiddleware.
+func (m *Metrics) ConsumerMiddleware(next gluon.HandlerFunc) gluon.HandlerFunc {
+	return func(ctx context.Context, msg *gluon.Message) error {
+		labels := prometheus.Labels{
+			labelTopic:         msg.GetTopic(),
+			labelConsumerGroup: msg.GetConsumerGroup(),
+			labelDriver:        gluon.DriverFromContext(ctx),
+		}
+		m.consumed.With(labels).Inc()
+		if msg.GetAttempt() > 1 {
+			m.retried.With(labels).Inc()
+		}
+
+		inFlight := m.inFlight.With(labels)
+		inFlight.Inc()
+		startTime := time.Now()
+		err := next(ctx, msg)
+		m.handlerDuration.With(labels).Observe(time.Since(startTime).Seconds())
+		inFlight.Dec()
+		if err != nil {
+			m.failed.With(labels).Inc()
+		}
+		return err
+	}
+}
+
+// DriverHooks Retrieve the driver callbacks which record driver-level metrics (e.g. consumer lag). Use it along
+// gluon.WithDriverHooks.
+func (m *Metrics) DriverHooks() gluon.DriverHooks {
+	return gluon.DriverHooks{
+		OnConsumerLag: func(driver, topic, group string, partition int32, lag int64) {
+			m.consumerLag.With(prometheus.Labels{
+				labelTopic:         topic,
+				labelConsumerGroup: group,
+				labelDriver:        driver,
+				labelPartition:     strconv.Itoa(int(partition)),
+			}).Set(float64(lag))
+		},
+		OnMessagesPolled: func(driver, topic, group string, total int) {
+			m.polledMessages.With(prometheus.Labels{
+				labelTopic:         topic,
+				labelConsumerGroup: group,
+				labelDriver:        driver,
+			}).Set(float64(total))
+		},
+	}
+}
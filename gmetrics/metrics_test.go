@@ -0,0 +1,94 @@
+package gmetrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+	"github.com/neutrinocorp/gluon/glocal"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type itemPaid struct {
+	ItemID string `json:"item_id"`
+}
+
+func TestMetrics_Middleware(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := New(WithRegisterer(registry))
+	require.NoError(t, err)
+
+	bus := gluon.NewBus(glocal.DriverName,
+		gluon.WithConsumerGroup("warehouse-service"),
+		gluon.WithRetryPolicy(gluon.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+		gluon.WithPublisherMiddleware(metrics.PublisherMiddleware),
+		gluon.WithConsumerMiddleware(metrics.ConsumerMiddleware),
+		gluon.WithDriverHooks(metrics.DriverHooks()))
+	bus.RegisterSchema(itemPaid{}, gluon.WithTopic("org.neutrino.marketplace.item.paid"))
+	handled := make(chan struct{})
+	bus.Subscribe(itemPaid{}).HandlerFunc(func(_ context.Context, msg *gluon.Message) error {
+		if msg.GetAttempt() == 1 {
+			return errors.New("dummy error")
+		}
+		close(handled)
+		return nil
+	})
+	require.NoError(t, bus.ListenAndServe())
+	defer func() {
+		_ = bus.Shutdown(context.Background())
+	}()
+
+	require.NoError(t, bus.Publish(context.Background(), itemPaid{ItemID: "123"}))
+	select {
+	case <-handled:
+	case <-time.After(time.Second * 5):
+		t.Fatal("message was not consumed")
+	}
+
+	labels := prometheus.Labels{
+		labelTopic:         "org.neutrino.marketplace.item.paid",
+		labelConsumerGroup: "warehouse-service",
+		labelDriver:        glocal.DriverName,
+	}
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.published.With(prometheus.Labels{
+		labelTopic:  "org.neutrino.marketplace.item.paid",
+		labelDriver: glocal.DriverName,
+	})))
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.consumed.With(labels)) == 2 &&
+			testutil.ToFloat64(metrics.inFlight.With(labels)) == 0
+	}, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.failed.With(labels)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.retried.With(labels)))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.handlerDuration))
+}
+
+func TestMetrics_DriverHooks(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := New(WithRegisterer(registry), WithNamespace("foo"))
+	require.NoError(t, err)
+
+	hooks := metrics.DriverHooks()
+	hooks.ReportConsumerLag("kafka", "foo.topic", "foo-service", 2, 10)
+	hooks.ReportMessagesPolled("aws_sns_sqs", "foo.topic", "foo-service", 7)
+
+	assert.Equal(t, float64(10), testutil.ToFloat64(metrics.consumerLag.With(prometheus.Labels{
+		labelTopic:         "foo.topic",
+		labelConsumerGroup: "foo-service",
+		labelDriver:        "kafka",
+		labelPartition:     "2",
+	})))
+	assert.Equal(t, float64(7), testutil.ToFloat64(metrics.polledMessages.With(prometheus.Labels{
+		labelTopic:         "foo.topic",
+		labelConsumerGroup: "foo-service",
+		labelDriver:        "aws_sns_sqs",
+	})))
+
+	_, err = New(WithRegisterer(registry), WithNamespace("foo"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package gmetrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type options struct {
+	registerer prometheus.Registerer
+	namespace  string
+	buckets    []float64
+}
+
+func newDefaultOptions() options {
+	return options{
+		registerer: prometheus.DefaultRegisterer,
+		namespace:  "gluon",
+		buckets:    prometheus.DefBuckets,
+	}
+}
+
+// Option set a specific configuration of Metrics.
+type Option interface {
+	apply(*options)
+}
+
+type registererOption struct {
+	registerer prometheus.Registerer
+}
+
+func (o registererOption) apply(opts *options) {
+	opts.registerer = o.registerer
+}
+
+// WithRegisterer Set the Prometheus registry where metrics are registered. Use this option to mount metrics on an
+// existing /metrics endpoint.
+//
+// The default registerer is prometheus.DefaultRegisterer.
+func WithRegisterer(r prometheus.Registerer) Option {
+	return registererOption{registerer: r}
+}
+
+type namespaceOption string
+
+func (o namespaceOption) apply(opts *options) {
+	opts.namespace = string(o)
+}
+
+// WithNamespace Set the prefix of every metric name.
+//
+// The default namespace is `gluon`.
+func WithNamespace(ns string) Option {
+	return namespaceOption(ns)
+}
+
+type bucketsOption []float64
+
+func (o bucketsOption) apply(opts *options) {
+	opts.buckets = o
+}
+
+// WithBuckets Set the buckets (in seconds) of the handler latency histogram.
+//
+// The default buckets are prometheus.DefBuckets.
+func WithBuckets(b ...float64) Option {
+	return bucketsOption(b)
+}
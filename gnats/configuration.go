@@ -0,0 +1,66 @@
+package gnats
+
+import (
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	defaultAckWait         = time.Second * 30
+	defaultMaxDeliver      = -1
+	defaultRedeliveryDelay = time.Second
+)
+
+// Configuration Is the NATS JetStream driver configuration, set using gluon.WithDriverConfiguration.
+type Configuration struct {
+	// Conn Established NATS connection. If nil, the driver connects to the Bus cluster addresses (gluon.WithCluster)
+	// or nats.DefaultURL using Options.
+	Conn *nats.Conn
+	// Options NATS connection options used when Conn is nil.
+	Options []nats.Option
+	// JetStreamOptions JetStream context options (e.g. nats.Domain).
+	JetStreamOptions []nats.JSOpt
+
+	// DisableStreamProvisioning Skip creation of missing streams. By default, a stream is created for every topic.
+	DisableStreamProvisioning bool
+	// StorageType Storage backend of provisioned streams (nats.FileStorage by default).
+	StorageType nats.StorageType
+	// Replicas Number of replicas of provisioned streams.
+	Replicas int
+
+	// AckWait Waiting time for a message acknowledgement before the server delivers it again.
+	AckWait time.Duration
+	// MaxDeliver Maximum number of deliveries of a message per consumer. Negative values mean unlimited deliveries.
+	MaxDeliver int
+	// RedeliveryDelay Waiting time before a message is delivered again after a handler failure.
+	RedeliveryDelay time.Duration
+}
+
+func (c Configuration) GetReplicas() int {
+	if c.Replicas <= 0 {
+		return 1
+	}
+	return c.Replicas
+}
+
+func (c Configuration) GetAckWait() time.Duration {
+	if c.AckWait <= 0 {
+		return defaultAckWait
+	}
+	return c.AckWait
+}
+
+func (c Configuration) GetMaxDeliver() int {
+	if c.MaxDeliver == 0 {
+		return defaultMaxDeliver
+	}
+	return c.MaxDeliver
+}
+
+func (c Configuration) GetRedeliveryDelay() time.Duration {
+	if c.RedeliveryDelay <= 0 {
+		return defaultRedeliveryDelay
+	}
+	return c.RedeliveryDelay
+}
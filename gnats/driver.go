@@ -0,0 +1,250 @@
+package gnats
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/nats-io/nats.go"
+	"github.com/neutrinocorp/gluon"
+)
+
+// DriverName Is the name used to register the NATS JetStream driver.
+const DriverName = "nats"
+
+// driver Is the NATS JetStream driver.
+//
+// Topics are mapped to subjects, each stored by a stream named after the topic. Subscribers with a consumer group
+// share a durable queue consumer named after the group (competing consumers), while subscribers without consumer
+// group get an ephemeral consumer.
+type driver struct {
+	parentBus      *gluon.Bus
+	messageHandler gluon.InternalMessageHandler
+	config         Configuration
+
+	mu            sync.Mutex
+	conn          *nats.Conn
+	ownsConn      bool
+	js            nats.JetStreamContext
+	streams       map[string]struct{}
+	subscriptions []*nats.Subscription
+	baseCtx       context.Context
+}
+
+var _ gluon.Driver = &driver{}
+
+func init() {
+	gluon.Register(DriverName, func() gluon.Driver {
+		return &driver{
+			streams: map[string]struct{}{},
+			baseCtx: context.Background(),
+		}
+	})
+}
+
+func (d *driver) SetParentBus(b *gluon.Bus) {
+	d.parentBus = b
+	if cfg, ok := b.Configuration.Driver.(Configuration); ok {
+		d.config = cfg
+	}
+}
+
+func (d *driver) SetInternalHandler(h gluon.InternalMessageHandler) {
+	d.messageHandler = h
+}
+
+func (d *driver) Start(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.baseCtx = ctx
+	return d.connect()
+}
+
+func (d *driver) Shutdown(_ context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	errs := new(multierror.Error)
+	for _, sub := range d.subscriptions {
+		// durable consumers are not created by the subscription, so they are kept on the server
+		if err := sub.Unsubscribe(); err != nil && !errors.Is(err, nats.ErrConnectionClosed) {
+			errs = multierror.Append(err, errs)
+		}
+	}
+	d.subscriptions = nil
+	if d.ownsConn && d.conn != nil {
+		d.conn.Close()
+		d.conn, d.js = nil, nil
+		d.streams = map[string]struct{}{}
+	}
+	return errs.ErrorOrNil()
+}
+
+func (d *driver) Publish(ctx context.Context, message *gluon.TransportMessage) error {
+	d.mu.Lock()
+	err := d.connect()
+	if err == nil {
+		err = d.ensureStream(message.Topic)
+	}
+	js := d.js
+	d.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if _, err = js.PublishMsg(marshalNatsMessage(message), nats.Context(ctx)); err != nil {
+		return gluon.NewError("NatsFailedPublishing", "Failed to publish to subject ("+message.Topic+")", err)
+	}
+	return nil
+}
+
+func (d *driver) Subscribe(_ context.Context, subscriber *gluon.Subscriber) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.connect(); err != nil {
+		return err
+	}
+	if err := d.ensureStream(subscriber.GetTopic()); err != nil {
+		return err
+	}
+
+	var (
+		sub *nats.Subscription
+		err error
+	)
+	group := subscriber.GetGroup()
+	if group == "" {
+		group = d.parentBus.Configuration.ConsumerGroup
+	}
+	handler := d.newMessageHandler(subscriber, group)
+	if group == "" {
+		sub, err = d.js.Subscribe(subscriber.GetTopic(), handler, d.consumerOptions()...)
+	} else {
+		var durable string
+		if durable, err = d.ensureDurableConsumer(subscriber.GetTopic(), group); err != nil {
+			return err
+		}
+		sub, err = d.js.QueueSubscribe(subscriber.GetTopic(), durable, handler,
+			nats.Bind(generateStreamName(subscriber.GetTopic()), durable), nats.ManualAck())
+	}
+	if err != nil {
+		return gluon.NewError("NatsFailedSubscribing", "Failed to subscribe to subject ("+
+			subscriber.GetTopic()+")", err)
+	}
+	d.subscriptions = append(d.subscriptions, sub)
+	return nil
+}
+
+// connect Establish the NATS connection and the JetStream context if necessary. Mutex must be locked.
+func (d *driver) connect() error {
+	if d.js != nil {
+		return nil
+	}
+	conn := d.config.Conn
+	if conn == nil {
+		url := nats.DefaultURL
+		if d.parentBus != nil && len(d.parentBus.Addresses) > 0 {
+			url = strings.Join(d.parentBus.Addresses, ",")
+		}
+		var err error
+		if conn, err = nats.Connect(url, d.config.Options...); err != nil {
+			return gluon.NewError("NatsFailedConnecting", "Failed to connect to server ("+url+")", err)
+		}
+		d.ownsConn = true
+	}
+	js, err := conn.JetStream(d.config.JetStreamOptions...)
+	if err != nil {
+		if d.ownsConn {
+			conn.Close()
+		}
+		return err
+	}
+	d.conn, d.js = conn, js
+	return nil
+}
+
+// ensureStream Create the stream of a topic if it does not exist. Mutex must be locked.
+func (d *driver) ensureStream(topic string) error {
+	if _, ok := d.streams[topic]; ok || d.config.DisableStreamProvisioning {
+		return nil
+	}
+	name := generateStreamName(topic)
+	_, err := d.js.StreamInfo(name)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = d.js.AddStream(&nats.StreamConfig{
+			Name:     name,
+			Subjects: []string{topic},
+			Storage:  d.config.StorageType,
+			Replicas: d.config.GetReplicas(),
+		})
+	}
+	if err != nil {
+		return gluon.NewError("NatsFailedProvisioning", "Failed to provision stream ("+name+")", err)
+	}
+	d.streams[topic] = struct{}{}
+	return nil
+}
+
+// ensureDurableConsumer Create the durable queue consumer of a consumer group if it does not exist. Returns the
+// consumer name. Mutex must be locked.
+func (d *driver) ensureDurableConsumer(topic, group string) (string, error) {
+	stream, durable := generateStreamName(topic), generateDurableName(group)
+	_, err := d.js.ConsumerInfo(stream, durable)
+	if errors.Is(err, nats.ErrConsumerNotFound) {
+		_, err = d.js.AddConsumer(stream, &nats.ConsumerConfig{
+			Durable:        durable,
+			DeliverSubject: nats.NewInbox(),
+			DeliverGroup:   durable,
+			DeliverPolicy:  nats.DeliverNewPolicy,
+			AckPolicy:      nats.AckExplicitPolicy,
+			AckWait:        d.config.GetAckWait(),
+			MaxDeliver:     d.config.GetMaxDeliver(),
+			FilterSubject:  topic,
+		})
+		if errors.Is(err, nats.ErrConsumerNameAlreadyInUse) {
+			// created by another instance of the consumer group
+			err = nil
+		}
+	}
+	if err != nil {
+		return "", gluon.NewError("NatsFailedProvisioning", "Failed to provision consumer ("+durable+")", err)
+	}
+	return durable, nil
+}
+
+func (d *driver) consumerOptions() []nats.SubOpt {
+	return []nats.SubOpt{
+		nats.DeliverNew(),
+		nats.ManualAck(),
+		nats.AckWait(d.config.GetAckWait()),
+		nats.MaxDeliver(d.config.GetMaxDeliver()),
+	}
+}
+
+func (d *driver) newMessageHandler(sub *gluon.Subscriber, group string) nats.MsgHandler {
+	return func(nMsg *nats.Msg) {
+		d.reportConsumerLag(nMsg, group)
+		msg := unmarshalNatsMessage(nMsg)
+		if err := d.messageHandler(d.baseCtx, sub, msg); err != nil {
+			d.parentBus.LogError(gluon.NewError("NatsHandlerFailed",
+				"Failed to handle the message from subject ("+nMsg.Subject+")", err))
+			d.parentBus.LogError(nMsg.NakWithDelay(d.config.GetRedeliveryDelay()))
+			return
+		}
+		if err := nMsg.Ack(); err != nil {
+			d.parentBus.LogError(gluon.NewError("NatsFailedToAcknowledge",
+				"Failed to acknowledge message from subject ("+nMsg.Subject+")", err))
+		}
+	}
+}
+
+// reportConsumerLag Report the number of messages pending to be delivered to the consumer.
+func (d *driver) reportConsumerLag(nMsg *nats.Msg, group string) {
+	meta, err := nMsg.Metadata()
+	if err != nil {
+		return
+	}
+	d.parentBus.Configuration.DriverHooks.ReportConsumerLag(DriverName, nMsg.Subject, group, 0,
+		int64(meta.NumPending))
+}
@@ -0,0 +1,140 @@
+package gnats
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type itemPaid struct {
+	ItemID string `json:"item_id"`
+}
+
+func newTestServer(t *testing.T) *server.Server {
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+	go srv.Start()
+	require.True(t, srv.ReadyForConnections(time.Second*5), "nats server is not ready")
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func newTestBus(t *testing.T, srv *server.Server, group string, h gluon.HandlerFunc) *gluon.Bus {
+	bus := gluon.NewBus(DriverName,
+		gluon.WithCluster(srv.ClientURL()),
+		gluon.WithConsumerGroup(group),
+		gluon.WithDriverConfiguration(Configuration{
+			RedeliveryDelay: time.Millisecond * 10,
+		}))
+	bus.RegisterSchema(itemPaid{}, gluon.WithTopic("org.neutrino.marketplace.item.paid"))
+	if h != nil {
+		bus.Subscribe(itemPaid{}).HandlerFunc(h)
+	}
+	require.NoError(t, bus.ListenAndServe())
+	t.Cleanup(func() {
+		_ = bus.Shutdown(context.Background())
+	})
+	return bus
+}
+
+func TestDriver_PublishSubscribe(t *testing.T) {
+	srv := newTestServer(t)
+	received := make(chan *gluon.Message, 1)
+	bus := newTestBus(t, srv, "warehouse-service", func(_ context.Context, msg *gluon.Message) error {
+		received <- msg
+		return nil
+	})
+
+	require.NoError(t, bus.Publish(context.Background(), itemPaid{ItemID: "123"}))
+	select {
+	case msg := <-received:
+		assert.Equal(t, itemPaid{ItemID: "123"}, msg.Data)
+		assert.Equal(t, "org.neutrino.marketplace.item.paid", msg.GetMessageType())
+		assert.NotEmpty(t, msg.GetCorrelationID())
+	case <-time.After(time.Second * 5):
+		t.Fatal("message was not consumed")
+	}
+}
+
+func TestDriver_Redelivery(t *testing.T) {
+	srv := newTestServer(t)
+	var deliveries int32
+	received := make(chan *gluon.Message, 1)
+	bus := newTestBus(t, srv, "warehouse-service", func(_ context.Context, msg *gluon.Message) error {
+		if atomic.AddInt32(&deliveries, 1) == 1 {
+			return errors.New("handler error")
+		}
+		received <- msg
+		return nil
+	})
+
+	require.NoError(t, bus.Publish(context.Background(), itemPaid{ItemID: "123"}))
+	select {
+	case <-received:
+		assert.Equal(t, int32(2), atomic.LoadInt32(&deliveries))
+	case <-time.After(time.Second * 5):
+		t.Fatal("message was not delivered again")
+	}
+}
+
+func TestDriver_ConsumerGroups(t *testing.T) {
+	srv := newTestServer(t)
+	var groupA, groupB int32
+	countA := func(_ context.Context, _ *gluon.Message) error {
+		atomic.AddInt32(&groupA, 1)
+		return nil
+	}
+	// competing consumers within group-a
+	newTestBus(t, srv, "group-a", countA)
+	newTestBus(t, srv, "group-a", countA)
+	newTestBus(t, srv, "group-b", func(_ context.Context, _ *gluon.Message) error {
+		atomic.AddInt32(&groupB, 1)
+		return nil
+	})
+
+	publisher := newTestBus(t, srv, "", nil)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, publisher.Publish(context.Background(), itemPaid{ItemID: "123"}))
+	}
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&groupA) == 10 && atomic.LoadInt32(&groupB) == 10
+	}, time.Second*5, time.Millisecond*10)
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(10), atomic.LoadInt32(&groupA), "every message must be delivered once per group")
+}
+
+func TestDriver_DurableConsumer(t *testing.T) {
+	srv := newTestServer(t)
+	received := make(chan *gluon.Message, 1)
+	handler := func(_ context.Context, msg *gluon.Message) error {
+		received <- msg
+		return nil
+	}
+	consumer := newTestBus(t, srv, "warehouse-service", handler)
+	require.NoError(t, consumer.Shutdown(context.Background()))
+
+	// messages published while the consumer group is offline must be delivered once it is back
+	publisher := newTestBus(t, srv, "", nil)
+	require.NoError(t, publisher.Publish(context.Background(), itemPaid{ItemID: "123"}))
+	newTestBus(t, srv, "warehouse-service", handler)
+	select {
+	case msg := <-received:
+		assert.Equal(t, itemPaid{ItemID: "123"}, msg.Data)
+	case <-time.After(time.Second * 5):
+		t.Fatal("message was not consumed")
+	}
+}
@@ -0,0 +1,20 @@
+package gnats
+
+// NATS JetStream custom gluon headers
+const (
+	HeaderSequence      = "nats-sequence"
+	HeaderDeliveryCount = "nats-delivery-count"
+
+	// Internal NATS message headers, following the CloudEvents NATS protocol binding
+
+	headerMessageID   = "ce-id"
+	headerSource      = "ce-source"
+	headerSpecVersion = "ce-specversion"
+	headerMessageType = "ce-type"
+	headerMessageTime = "ce-time"
+	headerContentType = "content-type"
+	headerSchema      = "ce-dataschema"
+	headerSubject     = "ce-subject"
+	// headerExtensionPrefix is used to carry CloudEvents extension attributes (e.g. ce-tenantid, ce-correlationid)
+	headerExtensionPrefix = "ce-"
+)
@@ -0,0 +1,89 @@
+package gnats
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"github.com/neutrinocorp/gluon"
+)
+
+func marshalNatsMessage(msg *gluon.TransportMessage) *nats.Msg {
+	nMsg := nats.NewMsg(msg.Topic)
+	nMsg.Data = msg.Data
+	setHeader(nMsg.Header, headerMessageID, msg.ID)
+	setHeader(nMsg.Header, headerSource, msg.Source)
+	setHeader(nMsg.Header, headerSpecVersion, msg.SpecVersion)
+	setHeader(nMsg.Header, headerMessageType, msg.Type)
+	setHeader(nMsg.Header, headerMessageTime, msg.Time)
+	setHeader(nMsg.Header, headerContentType, msg.DataContentType)
+	setHeader(nMsg.Header, headerSchema, msg.DataSchema)
+	setHeader(nMsg.Header, headerSubject, msg.Subject)
+	setHeader(nMsg.Header, headerExtensionPrefix+gluon.ExtensionCorrelationID, msg.CorrelationID)
+	setHeader(nMsg.Header, headerExtensionPrefix+gluon.ExtensionCausationID, msg.CausationID)
+	for k, v := range msg.TraceContext {
+		setHeader(nMsg.Header, k, v)
+	}
+	for k, v := range msg.Extensions {
+		setHeader(nMsg.Header, headerExtensionPrefix+k, v)
+	}
+	// enables JetStream duplicate detection
+	setHeader(nMsg.Header, nats.MsgIdHdr, msg.ID)
+	return nMsg
+}
+
+// setHeader Set a header value, skipping empty values. Header names are kept as-is (no canonical form) as the
+// CloudEvents NATS binding uses lower-case names.
+func setHeader(h nats.Header, key, value string) {
+	if value == "" {
+		return
+	}
+	h[key] = []string{value}
+}
+
+func unmarshalNatsMessage(nMsg *nats.Msg) *gluon.TransportMessage {
+	msg := &gluon.TransportMessage{
+		Data:          nMsg.Data,
+		Topic:         nMsg.Subject,
+		DriverHeaders: map[string]string{},
+	}
+	if meta, err := nMsg.Metadata(); err == nil {
+		msg.DriverHeaders[HeaderSequence] = strconv.FormatUint(meta.Sequence.Stream, 10)
+		msg.DriverHeaders[HeaderDeliveryCount] = strconv.FormatUint(meta.NumDelivered, 10)
+	}
+	for k, v := range nMsg.Header {
+		if len(v) == 0 {
+			continue
+		}
+		switch k {
+		case headerMessageID:
+			msg.ID = v[0]
+		case headerSource:
+			msg.Source = v[0]
+		case headerSpecVersion:
+			msg.SpecVersion = v[0]
+		case headerMessageType:
+			msg.Type = v[0]
+		case headerMessageTime:
+			msg.Time = v[0]
+		case headerContentType:
+			msg.DataContentType = v[0]
+		case headerSchema:
+			msg.DataSchema = v[0]
+		case headerSubject:
+			msg.Subject = v[0]
+		case gluon.ExtensionTraceParent, gluon.ExtensionTraceState:
+			msg.SetAttribute(k, v[0])
+		default:
+			unmarshalNatsExtension(k, v[0], msg)
+		}
+	}
+	return msg
+}
+
+func unmarshalNatsExtension(key, value string, msg *gluon.TransportMessage) {
+	if !strings.HasPrefix(key, headerExtensionPrefix) {
+		return
+	}
+	msg.SetAttribute(strings.TrimPrefix(key, headerExtensionPrefix), value)
+}
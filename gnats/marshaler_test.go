@@ -0,0 +1,38 @@
+package gnats
+
+import (
+	"testing"
+
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalNatsMessage(t *testing.T) {
+	msg := &gluon.TransportMessage{
+		ID:              "123",
+		Source:          "https://api.neutrino.org/marketplace/items",
+		SpecVersion:     gluon.CloudEventsSpecVersion,
+		Type:            "org.neutrino.marketplace.item.paid",
+		Data:            []byte(`{"item_id":"abc"}`),
+		DataContentType: "application/json",
+		DataSchema:      "https://schemas.neutrino.org/item_paid.json",
+		Subject:         "abc",
+		Time:            "2021-01-01T00:00:00Z",
+		CorrelationID:   "456",
+		CausationID:     "789",
+		TraceContext: map[string]string{
+			"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		},
+		Extensions: map[string]string{
+			"tenantid": "neutrino",
+		},
+		Topic: "org.neutrino.marketplace.item.paid",
+	}
+	nMsg := marshalNatsMessage(msg)
+	assert.Equal(t, msg.Topic, nMsg.Subject)
+	assert.Equal(t, "123", nMsg.Header.Get("Nats-Msg-Id"))
+
+	got := unmarshalNatsMessage(nMsg)
+	got.DriverHeaders = nil
+	assert.Equal(t, msg, got)
+}
@@ -0,0 +1,20 @@
+package gnats
+
+import "strings"
+
+// generateStreamName Generate the JetStream stream name of a topic.
+//
+// Note: Gluon constructs topics using '.' character. JetStream stream and consumer names do not accept this
+// character.
+func generateStreamName(topic string) string {
+	return sanitizeName(topic)
+}
+
+// generateDurableName Generate the JetStream durable consumer name of a consumer group.
+func generateDurableName(group string) string {
+	return sanitizeName(group)
+}
+
+func sanitizeName(name string) string {
+	return strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_").Replace(name)
+}
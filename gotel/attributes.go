@@ -0,0 +1,25 @@
+package gotel
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// messagingConsumerGroupKey Is the consumer group attribute from the messaging semantic conventions.
+//
+// Not defined on semconv v1.24.0 as it only exposes driver-specific keys (e.g. messaging.kafka.consumer.group).
+const messagingConsumerGroupKey = attribute.Key("messaging.consumer.group.name")
+
+const (
+	operationPublish = "publish"
+	operationProcess = "process"
+)
+
+func newMessagingAttributes(driver, topic, messageID, operation string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(driver),
+		semconv.MessagingDestinationName(topic),
+		semconv.MessagingMessageID(messageID),
+		semconv.MessagingOperationKey.String(operation),
+	}
+}
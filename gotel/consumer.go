@@ -0,0 +1,44 @@
+package gotel
+
+import (
+	"context"
+
+	"github.com/neutrinocorp/gluon"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewConsumerMiddleware Allocate a consumer middleware which extracts the producer span context from
+// Message.GetTraceCarrier and starts a consumer span linked to it.
+//
+// The consumer span is also a child of the producer span, so both operations share the same trace.
+func NewConsumerMiddleware(opts ...Option) gluon.MiddlewareHandlerFunc {
+	options := newDefaultOptions()
+	for _, o := range opts {
+		o.apply(&options)
+	}
+	tracer := options.tracerProvider.Tracer(instrumentationName)
+	return func(next gluon.HandlerFunc) gluon.HandlerFunc {
+		return func(ctx context.Context, msg *gluon.Message) error {
+			producerCtx := options.propagator.Extract(ctx, propagation.MapCarrier(msg.GetTraceCarrier()))
+			attrs := newMessagingAttributes(gluon.DriverFromContext(ctx), msg.GetTopic(), msg.GetMessageID(),
+				operationProcess)
+			if group := msg.GetConsumerGroup(); group != "" {
+				attrs = append(attrs, messagingConsumerGroupKey.String(group))
+			}
+			scopedCtx, span := tracer.Start(producerCtx, msg.GetTopic()+" "+operationProcess,
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithLinks(trace.LinkFromContext(producerCtx)),
+				trace.WithAttributes(attrs...))
+			defer span.End()
+
+			err := next(scopedCtx, msg)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
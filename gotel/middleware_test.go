@@ -0,0 +1,71 @@
+package gotel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+	_ "github.com/neutrinocorp/gluon/glocal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type itemPaid struct {
+	ItemID string `json:"item_id"`
+}
+
+func TestMiddleware_Propagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	bus := gluon.NewBus("local",
+		gluon.WithConsumerGroup("warehouse-service"),
+		gluon.WithPublisherMiddleware(NewPublisherMiddleware(WithTracerProvider(provider))),
+		gluon.WithConsumerMiddleware(NewConsumerMiddleware(WithTracerProvider(provider))))
+	bus.RegisterSchema(itemPaid{}, gluon.WithTopic("org.neutrino.marketplace.item.paid"))
+	handled := make(chan trace.SpanContext, 1)
+	bus.Subscribe(itemPaid{}).HandlerFunc(func(ctx context.Context, msg *gluon.Message) error {
+		assert.NotEmpty(t, msg.GetTraceCarrier()["traceparent"])
+		handled <- trace.SpanContextFromContext(ctx)
+		return nil
+	})
+	require.NoError(t, bus.ListenAndServe())
+	defer func() {
+		_ = bus.Shutdown(context.Background())
+	}()
+
+	require.NoError(t, bus.Publish(context.Background(), itemPaid{ItemID: "123"}))
+	var consumerSpanCtx trace.SpanContext
+	select {
+	case consumerSpanCtx = <-handled:
+	case <-time.After(time.Second * 5):
+		t.Fatal("message was not consumed")
+	}
+	assert.Eventually(t, func() bool {
+		return len(recorder.Ended()) == 2
+	}, time.Second*5, time.Millisecond*10)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	producerSpan, consumerSpan := spans[0], spans[1]
+	if producerSpan.SpanKind() != trace.SpanKindProducer {
+		producerSpan, consumerSpan = consumerSpan, producerSpan
+	}
+	assert.Equal(t, trace.SpanKindProducer, producerSpan.SpanKind())
+	assert.Equal(t, trace.SpanKindConsumer, consumerSpan.SpanKind())
+	assert.Equal(t, consumerSpanCtx.SpanID(), consumerSpan.SpanContext().SpanID())
+	assert.Equal(t, producerSpan.SpanContext().TraceID(), consumerSpan.SpanContext().TraceID())
+	assert.Equal(t, producerSpan.SpanContext().SpanID(), consumerSpan.Parent().SpanID())
+	if assert.Len(t, consumerSpan.Links(), 1) {
+		assert.Equal(t, producerSpan.SpanContext().SpanID(), consumerSpan.Links()[0].SpanContext.SpanID())
+	}
+	assert.Contains(t, consumerSpan.Attributes(), semconv.MessagingSystemKey.String("local"))
+	assert.Contains(t, consumerSpan.Attributes(),
+		semconv.MessagingDestinationName("org.neutrino.marketplace.item.paid"))
+	assert.Contains(t, consumerSpan.Attributes(), messagingConsumerGroupKey.String("warehouse-service"))
+}
@@ -0,0 +1,57 @@
+package gotel
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName Is the OpenTelemetry instrumentation scope used by this package.
+const instrumentationName = "github.com/neutrinocorp/gluon/gotel"
+
+type options struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+func newDefaultOptions() options {
+	return options{
+		tracerProvider: otel.GetTracerProvider(),
+		propagator:     propagation.TraceContext{},
+	}
+}
+
+// Option set a specific configuration of a tracing middleware.
+type Option interface {
+	apply(*options)
+}
+
+type tracerProviderOption struct {
+	provider trace.TracerProvider
+}
+
+func (o tracerProviderOption) apply(opts *options) {
+	opts.tracerProvider = o.provider
+}
+
+// WithTracerProvider Set the TracerProvider used to create spans.
+//
+// The default TracerProvider is the OpenTelemetry global one.
+func WithTracerProvider(p trace.TracerProvider) Option {
+	return tracerProviderOption{provider: p}
+}
+
+type propagatorOption struct {
+	propagator propagation.TextMapPropagator
+}
+
+func (o propagatorOption) apply(opts *options) {
+	opts.propagator = o.propagator
+}
+
+// WithPropagator Set the propagator used to inject and extract span contexts from in-transit messages.
+//
+// The default propagator is W3C Trace Context (traceparent and tracestate).
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return propagatorOption{propagator: p}
+}
@@ -0,0 +1,43 @@
+package gotel
+
+import (
+	"context"
+
+	"github.com/neutrinocorp/gluon"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewPublisherMiddleware Allocate a publisher middleware which starts a producer span for every message and injects
+// its context (W3C traceparent and tracestate by default) into TransportMessage.TraceContext.
+//
+// Every driver propagates TransportMessage.TraceContext, so consumers using NewConsumerMiddleware may continue the
+// trace.
+func NewPublisherMiddleware(opts ...Option) gluon.MiddlewarePublisherFunc {
+	options := newDefaultOptions()
+	for _, o := range opts {
+		o.apply(&options)
+	}
+	tracer := options.tracerProvider.Tracer(instrumentationName)
+	return func(next gluon.PublisherFunc) gluon.PublisherFunc {
+		return func(ctx context.Context, msg *gluon.TransportMessage) error {
+			scopedCtx, span := tracer.Start(ctx, msg.Topic+" "+operationPublish,
+				trace.WithSpanKind(trace.SpanKindProducer),
+				trace.WithAttributes(newMessagingAttributes(gluon.DriverFromContext(ctx), msg.Topic, msg.ID,
+					operationPublish)...))
+			defer span.End()
+
+			if msg.TraceContext == nil {
+				msg.TraceContext = map[string]string{}
+			}
+			options.propagator.Inject(scopedCtx, propagation.MapCarrier(msg.TraceContext))
+			err := next(scopedCtx, msg)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package gredis
+
+import (
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultAddress       = "localhost:6379"
+	defaultBlockTimeout  = time.Second
+	defaultBatchSize     = 10
+	defaultClaimMinIdle  = time.Second * 30
+	defaultClaimInterval = time.Second * 10
+	defaultMaxDeliveries = 10
+)
+
+// Configuration Is the Redis Streams driver configuration, set using gluon.WithDriverConfiguration.
+type Configuration struct {
+	// Client Established Redis client. If nil, the driver creates a client using ClientOptions or the Bus cluster
+	// addresses (gluon.WithCluster).
+	Client redis.UniversalClient
+	// ClientOptions Options used to create a Redis client when Client is nil.
+	ClientOptions *redis.UniversalOptions
+
+	// MaxLen Maximum length of streams, trimmed on every publication (XADD MAXLEN). Zero means no trimming.
+	MaxLen int64
+	// ApproximateMaxLen Use approximate trimming (MAXLEN ~), which is much more efficient than exact trimming.
+	ApproximateMaxLen bool
+
+	// ConsumerName Name of the consumer within its consumer group. If empty, a unique name is generated per
+	// subscriber.
+	ConsumerName string
+	// BlockTimeout Maximum waiting time of a polling operation (XREADGROUP BLOCK).
+	BlockTimeout time.Duration
+	// BatchSize Maximum number of messages fetched per polling operation (XREADGROUP COUNT).
+	BatchSize int64
+	// ClaimMinIdle Minimum idle time of a pending message before it is claimed by another consumer of the group
+	// (XAUTOCLAIM), which is how failed or abandoned messages are delivered again.
+	ClaimMinIdle time.Duration
+	// ClaimInterval Waiting time between XAUTOCLAIM operations.
+	ClaimInterval time.Duration
+	// MaxDeliveries Maximum number of deliveries of a message per consumer group (XPENDING delivery counter). Once
+	// exceeded, a claimed message is routed following the subscriber gluon.DeadLetterPolicy (or discarded if none)
+	// and acknowledged.
+	MaxDeliveries int64
+}
+
+func (c Configuration) GetBlockTimeout() time.Duration {
+	if c.BlockTimeout <= 0 {
+		return defaultBlockTimeout
+	}
+	return c.BlockTimeout
+}
+
+func (c Configuration) GetBatchSize() int64 {
+	if c.BatchSize <= 0 {
+		return defaultBatchSize
+	}
+	return c.BatchSize
+}
+
+func (c Configuration) GetClaimMinIdle() time.Duration {
+	if c.ClaimMinIdle <= 0 {
+		return defaultClaimMinIdle
+	}
+	return c.ClaimMinIdle
+}
+
+func (c Configuration) GetClaimInterval() time.Duration {
+	if c.ClaimInterval <= 0 {
+		return defaultClaimInterval
+	}
+	return c.ClaimInterval
+}
+
+func (c Configuration) GetMaxDeliveries() int64 {
+	if c.MaxDeliveries <= 0 {
+		return defaultMaxDeliveries
+	}
+	return c.MaxDeliveries
+}
@@ -0,0 +1,247 @@
+package gredis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+	"github.com/redis/go-redis/v9"
+)
+
+// streamConsumer Is a worker which delivers messages of a stream to a subscriber.
+type streamConsumer struct {
+	parentDriver *driver
+	client       redis.UniversalClient
+	sub          *gluon.Subscriber
+	stream       string
+	group        string
+	name         string
+	lastID       string // used by consumers without group only
+}
+
+func (c *streamConsumer) start(ctx context.Context) error {
+	if c.group == "" {
+		if err := c.initLastID(ctx); err != nil {
+			return err
+		}
+		c.parentDriver.consumerWg.Add(1)
+		go c.pollingLoop(ctx, c.read)
+		return nil
+	}
+
+	if err := c.ensureGroup(ctx); err != nil {
+		return err
+	}
+	c.name = c.parentDriver.config.ConsumerName
+	if c.name == "" {
+		id, err := c.parentDriver.parentBus.Factories.IDFactory.NewID()
+		if err != nil {
+			return err
+		}
+		c.name = c.group + "-" + id
+	}
+	c.parentDriver.consumerWg.Add(2)
+	go c.pollingLoop(ctx, c.readGroup)
+	go c.claimLoop(ctx)
+	return nil
+}
+
+// initLastID Set the last entry of the stream as starting point, so only new messages are delivered.
+func (c *streamConsumer) initLastID(ctx context.Context) error {
+	msgs, err := c.client.XRevRangeN(ctx, c.stream, "+", "-", 1).Result()
+	if err != nil {
+		return gluon.NewError("RedisFailedSubscribing", "Failed to read stream ("+c.stream+")", err)
+	}
+	c.lastID = "0-0"
+	if len(msgs) > 0 {
+		c.lastID = msgs[0].ID
+	}
+	return nil
+}
+
+// ensureGroup Create the consumer group (and the stream) if it does not exist. New consumer groups start from new
+// messages.
+func (c *streamConsumer) ensureGroup(ctx context.Context) error {
+	err := c.client.XGroupCreateMkStream(ctx, c.stream, c.group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return gluon.NewError("RedisFailedProvisioning", "Failed to create consumer group ("+c.group+
+			") of stream ("+c.stream+")", err)
+	}
+	return nil
+}
+
+func (c *streamConsumer) pollingLoop(ctx context.Context, poll func(context.Context) error) {
+	defer c.parentDriver.consumerWg.Done()
+	for ctx.Err() == nil {
+		err := poll(ctx)
+		if err == nil || errors.Is(err, redis.Nil) || ctx.Err() != nil {
+			continue
+		}
+		c.parentDriver.parentBus.LogError(gluon.NewError("RedisFailedPolling", "Failed to read from stream ("+
+			c.stream+")", err))
+		if strings.HasPrefix(err.Error(), "NOGROUP") {
+			// stream or group was removed
+			c.parentDriver.parentBus.LogError(c.ensureGroup(ctx))
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(c.parentDriver.config.GetBlockTimeout()):
+		}
+	}
+}
+
+func (c *streamConsumer) read(ctx context.Context) error {
+	streams, err := c.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{c.stream, c.lastID},
+		Count:   c.parentDriver.config.GetBatchSize(),
+		Block:   c.parentDriver.config.GetBlockTimeout(),
+	}).Result()
+	if err != nil {
+		return err
+	}
+	for _, stream := range streams {
+		c.reportPolled(len(stream.Messages))
+		for _, rMsg := range stream.Messages {
+			c.lastID = rMsg.ID
+			c.handle(ctx, rMsg)
+		}
+	}
+	return nil
+}
+
+func (c *streamConsumer) readGroup(ctx context.Context) error {
+	streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    c.group,
+		Consumer: c.name,
+		Streams:  []string{c.stream, ">"},
+		Count:    c.parentDriver.config.GetBatchSize(),
+		Block:    c.parentDriver.config.GetBlockTimeout(),
+	}).Result()
+	if err != nil {
+		return err
+	}
+	for _, stream := range streams {
+		c.reportPolled(len(stream.Messages))
+		for _, rMsg := range stream.Messages {
+			c.handle(ctx, rMsg)
+		}
+	}
+	return nil
+}
+
+// claimLoop Periodically claim messages pending for too long (e.g. failed handlers, crashed consumers) and deliver
+// them again.
+func (c *streamConsumer) claimLoop(ctx context.Context) {
+	defer c.parentDriver.consumerWg.Done()
+	ticker := time.NewTicker(c.parentDriver.config.GetClaimInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := c.claim(ctx); err != nil && ctx.Err() == nil {
+			c.parentDriver.parentBus.LogError(gluon.NewError("RedisFailedClaiming", "Failed to claim pending messages "+
+				"from stream ("+c.stream+")", err))
+		}
+	}
+}
+
+func (c *streamConsumer) claim(ctx context.Context) error {
+	start := "0-0"
+	for ctx.Err() == nil {
+		msgs, next, err := c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   c.stream,
+			Group:    c.group,
+			Consumer: c.name,
+			MinIdle:  c.parentDriver.config.GetClaimMinIdle(),
+			Start:    start,
+			Count:    c.parentDriver.config.GetBatchSize(),
+		}).Result()
+		if err != nil {
+			return err
+		}
+		deliveries, err := c.getDeliveryCounts(ctx, msgs)
+		if err != nil {
+			return err
+		}
+		for _, rMsg := range msgs {
+			if count := deliveries[rMsg.ID]; count > c.parentDriver.config.GetMaxDeliveries() {
+				c.deadLetter(ctx, rMsg, count)
+				continue
+			}
+			c.handle(ctx, rMsg)
+		}
+		if next == "0-0" || len(msgs) == 0 {
+			return nil
+		}
+		start = next
+	}
+	return nil
+}
+
+// getDeliveryCounts Retrieve the number of deliveries of claimed messages (XPENDING), including the current one.
+func (c *streamConsumer) getDeliveryCounts(ctx context.Context, msgs []redis.XMessage) (map[string]int64, error) {
+	deliveries := make(map[string]int64, len(msgs))
+	if len(msgs) == 0 {
+		return deliveries, nil
+	}
+	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream:   c.stream,
+		Group:    c.group,
+		Start:    msgs[0].ID,
+		End:      msgs[len(msgs)-1].ID,
+		Count:    int64(len(msgs)),
+		Consumer: c.name,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pending {
+		deliveries[p.ID] = p.RetryCount
+	}
+	return deliveries, nil
+}
+
+// deadLetter Route a message which exceeded the maximum number of deliveries following the subscriber dead-letter
+// policy, then acknowledge it so it is not claimed again. Messages are kept pending if routing failed.
+func (c *streamConsumer) deadLetter(ctx context.Context, rMsg redis.XMessage, deliveries int64) {
+	routed, err := c.parentDriver.parentBus.RouteDeadLetter(ctx, c.sub, unmarshalRedisMessage(c.stream, rMsg),
+		int(deliveries))
+	if err != nil {
+		c.parentDriver.parentBus.LogError(gluon.NewError("RedisFailedRoutingDeadLetter", "Failed to route message ("+
+			rMsg.ID+") from stream ("+c.stream+") to its dead-letter topic", err))
+		return
+	} else if !routed {
+		c.parentDriver.parentBus.LogError(gluon.NewError("RedisMessageDiscarded", "Message ("+rMsg.ID+
+			") from stream ("+c.stream+") reached maximum number of deliveries", nil))
+	}
+	if err = c.client.XAck(context.Background(), c.stream, c.group, rMsg.ID).Err(); err != nil {
+		c.parentDriver.parentBus.LogError(gluon.NewError("RedisFailedToAcknowledge", "Failed to acknowledge message ("+
+			rMsg.ID+") from stream ("+c.stream+")", err))
+	}
+}
+
+// handle Execute the subscriber handler. Messages of consumer groups are acknowledged (XACK) only if the handler
+// succeeded, otherwise they remain pending until they are claimed again.
+func (c *streamConsumer) handle(ctx context.Context, rMsg redis.XMessage) {
+	if err := c.parentDriver.messageHandler(ctx, c.sub, unmarshalRedisMessage(c.stream, rMsg)); err != nil {
+		c.parentDriver.parentBus.LogError(gluon.NewError("RedisHandlerFailed", "Failed to handle the message ("+rMsg.ID+
+			") from stream ("+c.stream+")", err))
+		return
+	} else if c.group == "" {
+		return
+	}
+	// acknowledge even if the driver is shutting down as the message was already processed
+	if err := c.client.XAck(context.Background(), c.stream, c.group, rMsg.ID).Err(); err != nil {
+		c.parentDriver.parentBus.LogError(gluon.NewError("RedisFailedToAcknowledge", "Failed to acknowledge message ("+
+			rMsg.ID+") from stream ("+c.stream+")", err))
+	}
+}
+
+func (c *streamConsumer) reportPolled(total int) {
+	c.parentDriver.parentBus.Configuration.DriverHooks.ReportMessagesPolled(DriverName, c.stream, c.group, total)
+}
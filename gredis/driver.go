@@ -0,0 +1,129 @@
+package gredis
+
+import (
+	"context"
+	"sync"
+
+	"github.com/neutrinocorp/gluon"
+	"github.com/redis/go-redis/v9"
+)
+
+// DriverName Is the name used to register the Redis Streams driver.
+const DriverName = "redis"
+
+// driver Is the Redis Streams driver.
+//
+// Topics are mapped to streams. Subscribers with a consumer group read messages using XREADGROUP, so subscribers
+// sharing a consumer group compete for messages while every consumer group gets every message (fan-out). Subscribers
+// without consumer group read every new message of the stream using XREAD.
+type driver struct {
+	parentBus      *gluon.Bus
+	messageHandler gluon.InternalMessageHandler
+	config         Configuration
+
+	mu         sync.Mutex
+	client     redis.UniversalClient
+	ownsClient bool
+	ctx        context.Context
+	cancel     context.CancelFunc
+	consumerWg sync.WaitGroup
+}
+
+var _ gluon.Driver = &driver{}
+
+func init() {
+	gluon.Register(DriverName, func() gluon.Driver {
+		d := &driver{}
+		d.ctx, d.cancel = context.WithCancel(context.Background())
+		return d
+	})
+}
+
+func (d *driver) SetParentBus(b *gluon.Bus) {
+	d.parentBus = b
+	if cfg, ok := b.Configuration.Driver.(Configuration); ok {
+		d.config = cfg
+	}
+}
+
+func (d *driver) SetInternalHandler(h gluon.InternalMessageHandler) {
+	d.messageHandler = h
+}
+
+func (d *driver) Start(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ctx, d.cancel = context.WithCancel(ctx)
+	return nil
+}
+
+func (d *driver) Shutdown(_ context.Context) error {
+	d.mu.Lock()
+	d.cancel()
+	d.mu.Unlock()
+	// consumers stop after their current polling operation
+	d.consumerWg.Wait()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ownsClient && d.client != nil {
+		err := d.client.Close()
+		d.client = nil
+		return err
+	}
+	return nil
+}
+
+func (d *driver) Publish(ctx context.Context, message *gluon.TransportMessage) error {
+	err := d.getClient().XAdd(ctx, &redis.XAddArgs{
+		Stream: message.Topic,
+		MaxLen: d.config.MaxLen,
+		Approx: d.config.ApproximateMaxLen,
+		Values: marshalRedisValues(message),
+	}).Err()
+	if err != nil {
+		return gluon.NewError("RedisFailedPublishing", "Failed to publish to stream ("+message.Topic+")", err)
+	}
+	return nil
+}
+
+func (d *driver) Subscribe(_ context.Context, subscriber *gluon.Subscriber) error {
+	group := subscriber.GetGroup()
+	if group == "" {
+		group = d.parentBus.Configuration.ConsumerGroup
+	}
+	c := &streamConsumer{
+		parentDriver: d,
+		client:       d.getClient(),
+		sub:          subscriber,
+		stream:       subscriber.GetTopic(),
+		group:        group,
+	}
+
+	d.mu.Lock()
+	ctx := d.ctx
+	d.mu.Unlock()
+	return c.start(ctx)
+}
+
+func (d *driver) getClient() redis.UniversalClient {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.client != nil {
+		return d.client
+	} else if d.config.Client != nil {
+		d.client = d.config.Client
+		return d.client
+	}
+
+	opts := d.config.ClientOptions
+	if opts == nil {
+		opts = &redis.UniversalOptions{Addrs: []string{defaultAddress}}
+		if d.parentBus != nil && len(d.parentBus.Addresses) > 0 {
+			opts.Addrs = d.parentBus.Addresses
+		}
+	}
+	d.client = redis.NewUniversalClient(opts)
+	d.ownsClient = true
+	return d.client
+}
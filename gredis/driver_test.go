@@ -0,0 +1,129 @@
+package gredis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/neutrinocorp/gluon"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type itemPaid struct {
+	ItemID string `json:"item_id"`
+}
+
+func newTestBus(t *testing.T, srv *miniredis.Miniredis, group string, cfg Configuration,
+	h gluon.HandlerFunc) *gluon.Bus {
+	cfg.BlockTimeout = time.Millisecond * 50
+	bus := gluon.NewBus(DriverName,
+		gluon.WithCluster(srv.Addr()),
+		gluon.WithConsumerGroup(group),
+		gluon.WithDriverConfiguration(cfg))
+	bus.RegisterSchema(itemPaid{}, gluon.WithTopic("org.neutrino.marketplace.item.paid"))
+	if h != nil {
+		bus.Subscribe(itemPaid{}).HandlerFunc(h)
+	}
+	require.NoError(t, bus.ListenAndServe())
+	t.Cleanup(func() {
+		_ = bus.Shutdown(context.Background())
+	})
+	return bus
+}
+
+func TestDriver_ConsumerGroups(t *testing.T) {
+	srv := miniredis.RunT(t)
+	var groupA, groupB, noGroup int32
+	counter := func(total *int32) gluon.HandlerFunc {
+		return func(_ context.Context, _ *gluon.Message) error {
+			atomic.AddInt32(total, 1)
+			return nil
+		}
+	}
+	// competing consumers within group-a
+	newTestBus(t, srv, "group-a", Configuration{}, counter(&groupA))
+	newTestBus(t, srv, "group-a", Configuration{}, counter(&groupA))
+	newTestBus(t, srv, "group-b", Configuration{}, counter(&groupB))
+	newTestBus(t, srv, "", Configuration{}, counter(&noGroup))
+
+	publisher := newTestBus(t, srv, "", Configuration{}, nil)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, publisher.Publish(context.Background(), itemPaid{ItemID: "123"}))
+	}
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&groupA) == 10 && atomic.LoadInt32(&groupB) == 10 &&
+			atomic.LoadInt32(&noGroup) == 10
+	}, time.Second*5, time.Millisecond*10)
+}
+
+func TestDriver_ClaimPendingMessages(t *testing.T) {
+	srv := miniredis.RunT(t)
+	var deliveries int32
+	received := make(chan *gluon.Message, 1)
+	bus := newTestBus(t, srv, "warehouse-service", Configuration{
+		ClaimMinIdle:  time.Millisecond,
+		ClaimInterval: time.Millisecond * 20,
+	}, func(_ context.Context, msg *gluon.Message) error {
+		if atomic.AddInt32(&deliveries, 1) == 1 {
+			return errors.New("handler error")
+		}
+		received <- msg
+		return nil
+	})
+
+	require.NoError(t, bus.Publish(context.Background(), itemPaid{ItemID: "123"}))
+	select {
+	case msg := <-received:
+		assert.Equal(t, itemPaid{ItemID: "123"}, msg.Data)
+	case <-time.After(time.Second * 5):
+		t.Fatal("failed message was not claimed")
+	}
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer client.Close()
+	assert.Eventually(t, func() bool {
+		pending, err := client.XPending(context.Background(), "org.neutrino.marketplace.item.paid",
+			"warehouse-service").Result()
+		return err == nil && pending.Count == 0
+	}, time.Second*5, time.Millisecond*10)
+}
+
+func TestDriver_MaxDeliveries(t *testing.T) {
+	srv := miniredis.RunT(t)
+	var deliveries int32
+	bus := newTestBus(t, srv, "warehouse-service", Configuration{
+		ClaimMinIdle:  time.Millisecond,
+		ClaimInterval: time.Millisecond * 20,
+		MaxDeliveries: 3,
+	}, func(_ context.Context, _ *gluon.Message) error {
+		atomic.AddInt32(&deliveries, 1)
+		return errors.New("handler error")
+	})
+
+	require.NoError(t, bus.Publish(context.Background(), itemPaid{ItemID: "123"}))
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer client.Close()
+	// poison messages are discarded (no dead-letter policy) and acknowledged once they exceed MaxDeliveries
+	assert.Eventually(t, func() bool {
+		pending, err := client.XPending(context.Background(), "org.neutrino.marketplace.item.paid",
+			"warehouse-service").Result()
+		return err == nil && pending.Count == 0
+	}, time.Second*5, time.Millisecond*10)
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&deliveries))
+}
+
+func TestDriver_MaxLen(t *testing.T) {
+	srv := miniredis.RunT(t)
+	bus := newTestBus(t, srv, "", Configuration{MaxLen: 5}, nil)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, bus.Publish(context.Background(), itemPaid{ItemID: "123"}))
+	}
+	entries, err := srv.Stream("org.neutrino.marketplace.item.paid")
+	require.NoError(t, err)
+	assert.Len(t, entries, 5)
+}
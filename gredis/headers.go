@@ -0,0 +1,20 @@
+package gredis
+
+// Redis Streams custom gluon headers
+const (
+	HeaderStreamID = "redis-stream-id"
+
+	// Internal Redis Streams entry fields
+
+	fieldData        = "data"
+	fieldMessageID   = "ce_id"
+	fieldSource      = "ce_source"
+	fieldSpecVersion = "ce_specversion"
+	fieldMessageType = "ce_type"
+	fieldMessageTime = "ce_time"
+	fieldContentType = "content_type"
+	fieldSchema      = "schema"
+	fieldSubject     = "subject"
+	// fieldExtensionPrefix is used to carry CloudEvents extension attributes (e.g. ce_tenantid, ce_correlationid)
+	fieldExtensionPrefix = "ce_"
+)
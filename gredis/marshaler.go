@@ -0,0 +1,78 @@
+package gredis
+
+import (
+	"strings"
+
+	"github.com/neutrinocorp/gluon"
+	"github.com/redis/go-redis/v9"
+)
+
+func marshalRedisValues(msg *gluon.TransportMessage) map[string]interface{} {
+	values := map[string]interface{}{
+		fieldData:        msg.Data,
+		fieldMessageID:   msg.ID,
+		fieldSource:      msg.Source,
+		fieldSpecVersion: msg.SpecVersion,
+		fieldMessageType: msg.Type,
+		fieldMessageTime: msg.Time,
+		fieldContentType: msg.DataContentType,
+		fieldSchema:      msg.DataSchema,
+		fieldSubject:     msg.Subject,
+		fieldExtensionPrefix + gluon.ExtensionCorrelationID: msg.CorrelationID,
+		fieldExtensionPrefix + gluon.ExtensionCausationID:   msg.CausationID,
+	}
+	for k, v := range msg.TraceContext {
+		values[k] = v
+	}
+	for k, v := range msg.Extensions {
+		values[fieldExtensionPrefix+k] = v
+	}
+	return values
+}
+
+func unmarshalRedisMessage(stream string, rMsg redis.XMessage) *gluon.TransportMessage {
+	msg := &gluon.TransportMessage{
+		Topic: stream,
+		DriverHeaders: map[string]string{
+			HeaderStreamID: rMsg.ID,
+		},
+	}
+	for k, raw := range rMsg.Values {
+		v, ok := raw.(string)
+		if !ok {
+			continue
+		}
+		switch k {
+		case fieldData:
+			msg.Data = []byte(v)
+		case fieldMessageID:
+			msg.ID = v
+		case fieldSource:
+			msg.Source = v
+		case fieldSpecVersion:
+			msg.SpecVersion = v
+		case fieldMessageType:
+			msg.Type = v
+		case fieldMessageTime:
+			msg.Time = v
+		case fieldContentType:
+			msg.DataContentType = v
+		case fieldSchema:
+			msg.DataSchema = v
+		case fieldSubject:
+			msg.Subject = v
+		case gluon.ExtensionTraceParent, gluon.ExtensionTraceState:
+			msg.SetAttribute(k, v)
+		default:
+			unmarshalRedisExtension(k, v, msg)
+		}
+	}
+	return msg
+}
+
+func unmarshalRedisExtension(key, value string, msg *gluon.TransportMessage) {
+	if !strings.HasPrefix(key, fieldExtensionPrefix) {
+		return
+	}
+	msg.SetAttribute(strings.TrimPrefix(key, fieldExtensionPrefix), value)
+}
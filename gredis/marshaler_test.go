@@ -0,0 +1,45 @@
+package gredis
+
+import (
+	"testing"
+
+	"github.com/neutrinocorp/gluon"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalRedisValues(t *testing.T) {
+	msg := &gluon.TransportMessage{
+		ID:              "123",
+		Source:          "https://api.neutrino.org/marketplace/items",
+		SpecVersion:     gluon.CloudEventsSpecVersion,
+		Type:            "org.neutrino.marketplace.item.paid",
+		Data:            []byte(`{"item_id":"abc"}`),
+		DataContentType: "application/json",
+		DataSchema:      "https://schemas.neutrino.org/item_paid.json",
+		Subject:         "abc",
+		Time:            "2021-01-01T00:00:00Z",
+		CorrelationID:   "456",
+		CausationID:     "789",
+		TraceContext: map[string]string{
+			"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		},
+		Extensions: map[string]string{
+			"tenantid": "neutrino",
+		},
+		Topic: "org.neutrino.marketplace.item.paid",
+	}
+	// Redis returns every field value as string
+	values := map[string]interface{}{}
+	for k, v := range marshalRedisValues(msg) {
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		values[k] = v
+	}
+
+	got := unmarshalRedisMessage(msg.Topic, redis.XMessage{ID: "1-0", Values: values})
+	assert.Equal(t, "1-0", got.DriverHeaders[HeaderStreamID])
+	got.DriverHeaders = nil
+	assert.Equal(t, msg, got)
+}
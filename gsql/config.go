@@ -0,0 +1,58 @@
+package gsql
+
+import "time"
+
+const (
+	defaultOutboxTableName     = "gluon_outbox"
+	defaultOutboxLeaseDuration = time.Second * 30
+)
+
+// OutboxConfig Is the configuration of an OutboxStore.
+type OutboxConfig struct {
+	// TableName Name of the outbox table. Default is `gluon_outbox`.
+	TableName string
+	// Placeholder Bind parameter format of the database driver. Default is PlaceholderQuestion.
+	Placeholder Placeholder
+	// Dialect SQL dialect of the database, used to create tables. Default is DialectSQLite.
+	Dialect Dialect
+	// LeaseDuration Time a relay keeps exclusive access to the outbox after fetching records (it is renewed on
+	// every fetch). MUST be greater than the relay polling interval, the time to dispatch a batch and the clock skew
+	// between instances. Default is 30 seconds.
+	LeaseDuration time.Duration
+}
+
+func (c OutboxConfig) GetTableName() string {
+	if c.TableName == "" {
+		return defaultOutboxTableName
+	}
+	return c.TableName
+}
+
+// GetLeaseTableName Retrieve the name of the table holding the relay lease (`<TableName>_lease`).
+func (c OutboxConfig) GetLeaseTableName() string {
+	return c.GetTableName() + "_lease"
+}
+
+func (c OutboxConfig) GetLeaseDuration() time.Duration {
+	if c.LeaseDuration <= 0 {
+		return defaultOutboxLeaseDuration
+	}
+	return c.LeaseDuration
+}
+
+const defaultInboxTableName = "gluon_inbox"
+
+// InboxConfig Is the configuration of an InboxStore.
+type InboxConfig struct {
+	// TableName Name of the inbox table. Default is `gluon_inbox`.
+	TableName string
+	// Placeholder Bind parameter format of the database driver. Default is PlaceholderQuestion.
+	Placeholder Placeholder
+}
+
+func (c InboxConfig) GetTableName() string {
+	if c.TableName == "" {
+		return defaultInboxTableName
+	}
+	return c.TableName
+}
@@ -0,0 +1,26 @@
+package gsql
+
+// Dialect Is the SQL dialect of a database, used to generate statements which are not portable (e.g. auto
+// incremented columns).
+type Dialect int
+
+const (
+	// DialectSQLite Is the SQLite dialect.
+	DialectSQLite Dialect = iota
+	// DialectPostgreSQL Is the PostgreSQL dialect.
+	DialectPostgreSQL
+	// DialectMySQL Is the MySQL (or MariaDB) dialect.
+	DialectMySQL
+)
+
+// autoIncrementColumn Generate the definition of an auto incremented primary key column.
+func (d Dialect) autoIncrementColumn(name string) string {
+	switch d {
+	case DialectPostgreSQL:
+		return name + " BIGSERIAL PRIMARY KEY"
+	case DialectMySQL:
+		return name + " BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY"
+	default:
+		return name + " INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+}
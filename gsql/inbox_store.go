@@ -0,0 +1,92 @@
+package gsql
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+)
+
+// InboxStore Is a gluon.InboxStore backed by a database/sql database.
+//
+// Keys are recorded within a transaction attached to the handler context (WithTx). Handlers may use the same
+// transaction (TxFromContext) to commit their own changes along the processed message key atomically.
+type InboxStore struct {
+	db  *sql.DB
+	cfg InboxConfig
+}
+
+var _ gluon.InboxStore = &InboxStore{}
+
+// NewInboxStore Allocate a new InboxStore.
+func NewInboxStore(db *sql.DB, cfg InboxConfig) *InboxStore {
+	return &InboxStore{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// CreateTable Create the inbox table if it does not exist.
+func (s *InboxStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+s.cfg.GetTableName()+` (
+	id VARCHAR(255) NOT NULL PRIMARY KEY,
+	expires_at BIGINT NOT NULL
+)`)
+	return err
+}
+
+func (s *InboxStore) Process(ctx context.Context, key string, ttl time.Duration,
+	fn func(ctx context.Context) error) (err error) {
+	tx, isParentTx := TxFromContext(ctx)
+	if !isParentTx {
+		if tx, err = s.db.BeginTx(ctx, nil); err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				_ = tx.Rollback()
+				return
+			}
+			err = tx.Commit()
+		}()
+	}
+
+	now := time.Now()
+	if err = s.acquire(ctx, tx, key, now, ttl); err != nil {
+		return err
+	}
+	return fn(WithTx(ctx, tx))
+}
+
+func (s *InboxStore) acquire(ctx context.Context, tx *sql.Tx, key string, now time.Time, ttl time.Duration) error {
+	if _, err := tx.ExecContext(ctx, s.cfg.Placeholder.rebind(`DELETE FROM `+s.cfg.GetTableName()+
+		` WHERE id = ? AND expires_at <= ?`), key, now.UnixNano()); err != nil {
+		return err
+	}
+
+	var total int
+	if err := tx.QueryRowContext(ctx, s.cfg.Placeholder.rebind(`SELECT COUNT(*) FROM `+s.cfg.GetTableName()+
+		` WHERE id = ?`), key).Scan(&total); err != nil {
+		return err
+	} else if total > 0 {
+		return gluon.ErrMessageAlreadyProcessed
+	}
+
+	expiresAt := int64(math.MaxInt64)
+	if ttl > 0 {
+		expiresAt = now.Add(ttl).UnixNano()
+	}
+	// concurrent deliveries of the same key fail here (primary key), so they will be redelivered by the driver
+	_, err := tx.ExecContext(ctx, s.cfg.Placeholder.rebind(`INSERT INTO `+s.cfg.GetTableName()+
+		` (id, expires_at) VALUES (?, ?)`), key, expiresAt)
+	return err
+}
+
+// PurgeExpired Remove expired keys.
+func (s *InboxStore) PurgeExpired(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, s.cfg.Placeholder.rebind(`DELETE FROM `+s.cfg.GetTableName()+
+		` WHERE expires_at <= ?`), time.Now().UnixNano())
+	return err
+}
@@ -0,0 +1,51 @@
+package gsql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboxStore_Process(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	store := NewInboxStore(db, InboxConfig{})
+	require.NoError(t, store.CreateTable(ctx))
+	_, err := db.ExecContext(ctx, `CREATE TABLE projection (id VARCHAR(255) NOT NULL PRIMARY KEY)`)
+	require.NoError(t, err)
+
+	project := func(id string, fail bool) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			tx, ok := TxFromContext(ctx)
+			require.True(t, ok)
+			if _, err := tx.ExecContext(ctx, `INSERT INTO projection (id) VALUES (?)`, id); err != nil {
+				return err
+			}
+			if fail {
+				return errors.New("handler error")
+			}
+			return nil
+		}
+	}
+
+	// failed handlers roll back both the key and the handler changes
+	assert.Error(t, store.Process(ctx, "group#1", time.Minute, project("1", true)))
+	assert.NoError(t, store.Process(ctx, "group#1", time.Minute, project("1", false)))
+	assert.ErrorIs(t, store.Process(ctx, "group#1", time.Minute, project("1", false)),
+		gluon.ErrMessageAlreadyProcessed)
+
+	var total int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM projection`).Scan(&total))
+	assert.Equal(t, 1, total)
+
+	// expired keys may be processed again
+	assert.NoError(t, store.Process(ctx, "group#2", time.Millisecond, project("2", false)))
+	time.Sleep(time.Millisecond * 5)
+	require.NoError(t, store.PurgeExpired(ctx))
+	assert.NoError(t, store.Process(ctx, "group#2", time.Millisecond, project("3", false)))
+}
@@ -0,0 +1,164 @@
+package gsql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	json "github.com/json-iterator/go"
+	"github.com/neutrinocorp/gluon"
+)
+
+// OutboxStore Is a gluon.OutboxStore backed by a database/sql database.
+//
+// If a transaction is attached to the context (WithTx), messages are saved within that transaction.
+//
+// Records are fetched following the order of an auto incremented column. To preserve ordering per topic when
+// many instances share the outbox, only the holder of a lease (OutboxConfig.LeaseDuration) fetches records; other
+// instances fetch no records until the lease expires.
+type OutboxStore struct {
+	db      *sql.DB
+	cfg     OutboxConfig
+	ownerID string
+}
+
+var _ gluon.OutboxStore = &OutboxStore{}
+
+// outboxLeaseName Is the name of the single lease row of the relay.
+const outboxLeaseName = "relay"
+
+// NewOutboxStore Allocate a new OutboxStore.
+func NewOutboxStore(db *sql.DB, cfg OutboxConfig) *OutboxStore {
+	return &OutboxStore{
+		db:      db,
+		cfg:     cfg,
+		ownerID: uuid.NewString(),
+	}
+}
+
+// CreateTable Create the outbox and lease tables if they do not exist.
+func (s *OutboxStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+s.cfg.GetTableName()+` (
+	`+s.cfg.Dialect.autoIncrementColumn("sequence")+`,
+	id VARCHAR(255) NOT NULL UNIQUE,
+	topic VARCHAR(255) NOT NULL,
+	message TEXT NOT NULL
+)`)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+s.cfg.GetLeaseTableName()+` (
+	name VARCHAR(255) NOT NULL PRIMARY KEY,
+	owner VARCHAR(255) NOT NULL,
+	expires_at BIGINT NOT NULL
+)`)
+	return err
+}
+
+func (s *OutboxStore) Save(ctx context.Context, msgs ...*gluon.TransportMessage) error {
+	exec := getExecer(ctx, s.db)
+	query := s.cfg.Placeholder.rebind(`INSERT INTO ` + s.cfg.GetTableName() +
+		` (id, topic, message) VALUES (?, ?, ?)`)
+	for _, msg := range msgs {
+		encoded, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if _, err = exec.ExecContext(ctx, query, msg.ID, msg.Topic, string(encoded)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// acquireLease Acquire (or renew) the relay lease. Returns false if another instance holds the lease.
+func (s *OutboxStore) acquireLease(ctx context.Context) (bool, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.cfg.GetLeaseDuration()).UnixNano()
+	res, err := s.db.ExecContext(ctx, s.cfg.Placeholder.rebind(`UPDATE `+s.cfg.GetLeaseTableName()+
+		` SET owner = ?, expires_at = ? WHERE name = ? AND (owner = ? OR expires_at <= ?)`),
+		s.ownerID, expiresAt, outboxLeaseName, s.ownerID, now.UnixNano())
+	if err != nil {
+		return false, err
+	}
+	if affected, errRows := res.RowsAffected(); errRows != nil {
+		return false, errRows
+	} else if affected > 0 {
+		return true, nil
+	}
+
+	var total int
+	if err = s.db.QueryRowContext(ctx, s.cfg.Placeholder.rebind(`SELECT COUNT(*) FROM `+
+		s.cfg.GetLeaseTableName()+` WHERE name = ?`), outboxLeaseName).Scan(&total); err != nil {
+		return false, err
+	} else if total > 0 {
+		return false, nil
+	}
+	// first relay to start, concurrent inserts fail here (primary key) so only one instance acquires the lease
+	_, err = s.db.ExecContext(ctx, s.cfg.Placeholder.rebind(`INSERT INTO `+s.cfg.GetLeaseTableName()+
+		` (name, owner, expires_at) VALUES (?, ?, ?)`), outboxLeaseName, s.ownerID, expiresAt)
+	if err == nil {
+		return true, nil
+	}
+	// database/sql does not expose constraint violations, the conflict is confirmed if the lease row exists now
+	if errCount := s.db.QueryRowContext(ctx, s.cfg.Placeholder.rebind(`SELECT COUNT(*) FROM `+
+		s.cfg.GetLeaseTableName()+` WHERE name = ?`), outboxLeaseName).Scan(&total); errCount == nil && total > 0 {
+		return false, nil
+	}
+	return false, err
+}
+
+// Fetch Retrieve up to `limit` pending records ordered by insertion, skipping records of `excludedTopics`.
+// Returns no records if another instance holds the relay lease.
+func (s *OutboxStore) Fetch(ctx context.Context, limit int, excludedTopics ...string) ([]gluon.OutboxRecord, error) {
+	if ok, err := s.acquireLease(ctx); err != nil || !ok {
+		return nil, err
+	}
+	query := `SELECT id, topic, message FROM ` + s.cfg.GetTableName()
+	args := make([]interface{}, 0, len(excludedTopics)+1)
+	if len(excludedTopics) > 0 {
+		query += ` WHERE topic NOT IN (` + list(len(excludedTopics)) + `)`
+		for _, topic := range excludedTopics {
+			args = append(args, topic)
+		}
+	}
+	args = append(args, limit)
+	rows, err := getExecer(ctx, s.db).QueryContext(ctx, s.cfg.Placeholder.rebind(query+
+		` ORDER BY sequence ASC LIMIT ?`), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]gluon.OutboxRecord, 0, limit)
+	for rows.Next() {
+		var id, topic, encoded string
+		if err = rows.Scan(&id, &topic, &encoded); err != nil {
+			return nil, err
+		}
+		msg := new(gluon.TransportMessage)
+		if err = json.Unmarshal([]byte(encoded), msg); err != nil {
+			return nil, err
+		}
+		msg.Topic = topic
+		records = append(records, gluon.OutboxRecord{
+			ID:      id,
+			Message: msg,
+		})
+	}
+	return records, rows.Err()
+}
+
+func (s *OutboxStore) MarkDispatched(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	_, err := getExecer(ctx, s.db).ExecContext(ctx, s.cfg.Placeholder.rebind(`DELETE FROM `+s.cfg.GetTableName()+
+		` WHERE id IN (`+list(len(ids))+`)`), args...)
+	return err
+}
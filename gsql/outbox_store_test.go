@@ -0,0 +1,119 @@
+package gsql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/gluon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", "file::memory:")
+	require.NoError(t, err)
+	// in-memory databases are bound to a single connection
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db
+}
+
+func TestOutboxStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewOutboxStore(newTestDB(t), OutboxConfig{})
+	require.NoError(t, store.CreateTable(ctx))
+
+	// rolled back transactions do not store messages
+	tx, err := store.db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(WithTx(ctx, tx), &gluon.TransportMessage{ID: "1", Topic: "foo.topic"}))
+	require.NoError(t, tx.Rollback())
+	records, err := store.Fetch(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, records, 0)
+
+	tx, err = store.db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(WithTx(ctx, tx),
+		&gluon.TransportMessage{ID: "2", Topic: "foo.topic", Data: []byte(`{"foo":"bar"}`)},
+		&gluon.TransportMessage{ID: "3", Topic: "bar.topic"},
+		&gluon.TransportMessage{ID: "4", Topic: "foo.topic"}))
+	require.NoError(t, tx.Commit())
+
+	records, err = store.Fetch(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "2", records[0].ID)
+	assert.Equal(t, "foo.topic", records[0].Message.Topic)
+	assert.Equal(t, []byte(`{"foo":"bar"}`), records[0].Message.Data)
+	assert.Equal(t, "3", records[1].ID)
+
+	records, err = store.Fetch(ctx, 10, "foo.topic")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "3", records[0].ID)
+
+	require.NoError(t, store.MarkDispatched(ctx, "2", "3"))
+	records, err = store.Fetch(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "4", records[0].ID)
+}
+
+func TestPlaceholder_Rebind(t *testing.T) {
+	query := "DELETE FROM foo WHERE id IN (" + list(3) + ")"
+	assert.Equal(t, "DELETE FROM foo WHERE id IN (?,?,?)", PlaceholderQuestion.rebind(query))
+	assert.Equal(t, "DELETE FROM foo WHERE id IN ($1,$2,$3)", PlaceholderDollar.rebind(query))
+}
+
+func TestOutboxStore_Lease(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	cfg := OutboxConfig{LeaseDuration: time.Millisecond * 50}
+	relayA, relayB := NewOutboxStore(db, cfg), NewOutboxStore(db, cfg)
+	require.NoError(t, relayA.CreateTable(ctx))
+	require.NoError(t, relayA.Save(ctx, &gluon.TransportMessage{ID: "1", Topic: "foo.topic"}))
+
+	records, err := relayA.Fetch(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+	// only the lease holder fetches records, preserving ordering per topic
+	records, err = relayB.Fetch(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, records, 0)
+	records, err = relayA.Fetch(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	// another instance takes over once the lease expires
+	time.Sleep(cfg.LeaseDuration)
+	records, err = relayB.Fetch(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+	records, err = relayA.Fetch(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, records, 0)
+}
+
+func TestOutboxStore_LeaseError(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	store := NewOutboxStore(db, OutboxConfig{})
+	// lease rows are rejected by the database, no other instance holds the lease
+	_, err := db.ExecContext(ctx, `CREATE TABLE `+store.cfg.GetLeaseTableName()+` (
+	name VARCHAR(255) NOT NULL PRIMARY KEY,
+	owner VARCHAR(255) NOT NULL,
+	expires_at BIGINT NOT NULL CHECK (expires_at < 0)
+)`)
+	require.NoError(t, err)
+	require.NoError(t, store.CreateTable(ctx))
+
+	records, err := store.Fetch(ctx, 10)
+	assert.Error(t, err)
+	assert.Len(t, records, 0)
+}
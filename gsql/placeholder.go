@@ -0,0 +1,42 @@
+package gsql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Placeholder Is the bind parameter format used by a database driver.
+type Placeholder int
+
+const (
+	// PlaceholderQuestion Is the `?` bind parameter format (e.g. SQLite, MySQL).
+	PlaceholderQuestion Placeholder = iota
+	// PlaceholderDollar Is the `$N` bind parameter format (e.g. PostgreSQL).
+	PlaceholderDollar
+)
+
+// rebind Replace `?` bind parameters from a query with the given Placeholder format.
+func (p Placeholder) rebind(query string) string {
+	if p != PlaceholderDollar {
+		return query
+	}
+	builder := strings.Builder{}
+	param := 0
+	for _, r := range query {
+		if r != '?' {
+			builder.WriteRune(r)
+			continue
+		}
+		param++
+		builder.WriteString("$" + strconv.Itoa(param))
+	}
+	return builder.String()
+}
+
+// list Generate a comma-separated list of `?` bind parameters.
+func list(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?,", n-1) + "?"
+}
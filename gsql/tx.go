@@ -0,0 +1,42 @@
+package gsql
+
+import (
+	"context"
+	"database/sql"
+)
+
+type contextKey string
+
+const contextTx contextKey = "gluon-sql-tx"
+
+// WithTx Attach a database transaction to the context. Stores from this package will execute their statements
+// within the transaction.
+//
+// For example, to publish a message only if a database transaction commits:
+//
+//	tx, _ := db.BeginTx(ctx, nil)
+//	ctx = gsql.WithTx(ctx, tx)
+//	_ = bus.Publish(ctx, event) // stored on the outbox using tx
+//	_ = tx.Commit()
+func WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, contextTx, tx)
+}
+
+// TxFromContext Retrieve the database transaction attached to the context, if any.
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(contextTx).(*sql.Tx)
+	return tx, ok && tx != nil
+}
+
+// execer Is a common interface for sql.DB and sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func getExecer(ctx context.Context, db *sql.DB) execer {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
@@ -15,6 +15,11 @@ type TopicGeneratorArgs struct {
 }
 
 const (
+	// RetryTopicSuffix Is the suffix appended to a topic name to generate its retry topic.
+	RetryTopicSuffix = ".retry"
+	// DLQTopicSuffix Is the suffix appended to a topic name to generate its dead-letter queue (DLQ) topic.
+	DLQTopicSuffix = ".dlq"
+
 	// MessageTypeCommand Is a concrete action that is requested to be processed.
 	MessageTypeCommand = "command"
 	// MessageTypeEvent Is a fact that happened within the system.
@@ -36,10 +41,23 @@ func GenerateTopicName(args TopicGeneratorArgs) string {
 	builder.WriteString(args.Action)
 
 	if args.IsRetry {
-		builder.WriteString(".retry")
+		builder.WriteString(RetryTopicSuffix)
 	} else if args.IsDLQ {
-		builder.WriteString(".dlq")
+		builder.WriteString(DLQTopicSuffix)
 	}
 
 	return builder.String()
 }
+
+// GenerateRetryTopicName Construct the retry topic name of an existing topic (e.g. org.neutrino.iam.event.user.signed_up.retry).
+func GenerateRetryTopicName(topic string) string {
+	return strings.TrimSuffix(topic, RetryTopicSuffix) + RetryTopicSuffix
+}
+
+// GenerateDLQTopicName Construct the dead-letter queue (DLQ) topic name of an existing topic
+// (e.g. org.neutrino.iam.event.user.signed_up.dlq).
+//
+// If the given topic is a retry topic, the retry suffix is replaced.
+func GenerateDLQTopicName(topic string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(topic, DLQTopicSuffix), RetryTopicSuffix) + DLQTopicSuffix
+}
@@ -68,3 +68,34 @@ func TestGenerateTopicName(t *testing.T) {
 		})
 	}
 }
+
+var derivedTopicTestCases = []struct {
+	In        string
+	WantRetry string
+	WantDLQ   string
+}{
+	{
+		In:        "org.neutrino.iam.event.user.signed_up",
+		WantRetry: "org.neutrino.iam.event.user.signed_up.retry",
+		WantDLQ:   "org.neutrino.iam.event.user.signed_up.dlq",
+	},
+	{
+		In:        "org.neutrino.iam.event.user.signed_up.retry",
+		WantRetry: "org.neutrino.iam.event.user.signed_up.retry",
+		WantDLQ:   "org.neutrino.iam.event.user.signed_up.dlq",
+	},
+	{
+		In:        "org.neutrino.iam.event.user.signed_up.dlq",
+		WantRetry: "org.neutrino.iam.event.user.signed_up.dlq.retry",
+		WantDLQ:   "org.neutrino.iam.event.user.signed_up.dlq",
+	},
+}
+
+func TestGenerateDerivedTopicNames(t *testing.T) {
+	for _, tt := range derivedTopicTestCases {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, tt.WantRetry, GenerateRetryTopicName(tt.In))
+			assert.Equal(t, tt.WantDLQ, GenerateDLQTopicName(tt.In))
+		})
+	}
+}
@@ -13,9 +13,11 @@ const (
 	headerCausationID   = "causation_id"
 	headerTraceContext  = "trace_context"
 	headerConsumerGroup = "consumer_group"
+	headerAttempt       = "attempt"
+	headerTopic         = "topic"
 )
 
-func generateHeaders(msg *TransportMessage, sub *Subscriber) map[string]interface{} {
+func generateHeaders(msg *TransportMessage, sub *Subscriber, group string, attempt int) map[string]interface{} {
 	headers := map[string]interface{}{
 		headerMessageID:     msg.ID,
 		headerSource:        msg.Source,
@@ -28,7 +30,14 @@ func generateHeaders(msg *TransportMessage, sub *Subscriber) map[string]interfac
 		headerCausationID:   msg.CausationID,
 		headerTraceContext:  msg.TraceContext,
 		headerTime:          msg.Time,
-		headerConsumerGroup: sub.group,
+		headerConsumerGroup: group,
+		headerAttempt:       attempt,
+		headerTopic:         sub.GetTopic(),
+	}
+	for k, v := range msg.Extensions {
+		if _, ok := headers[k]; !ok {
+			headers[k] = v
+		}
 	}
 	for k, v := range msg.DriverHeaders {
 		headers[k] = v
@@ -0,0 +1,46 @@
+package gluon
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrMessageAlreadyProcessed The message was already processed by the consumer group.
+	ErrMessageAlreadyProcessed = errors.New("gluon: The message was already processed")
+	// ErrMessageBeingProcessed The message is being processed by another delivery of the same consumer group.
+	ErrMessageBeingProcessed = errors.New("gluon: The message is being processed")
+)
+
+// InboxStore Is a database used by the idempotent consumer (inbox) to record processed messages.
+type InboxStore interface {
+	// Process Execute fn only if key was not processed before. The key is recorded along fn atomically, so it is
+	// only stored if fn succeeded. Recorded keys expire after ttl (no expiration if ttl <= 0).
+	//
+	// Returns ErrMessageAlreadyProcessed if key was already recorded.
+	Process(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error) error
+}
+
+// NewInboxMiddleware Allocate a consumer middleware which skips duplicate deliveries of a message.
+//
+// Messages are identified by their ID and consumer group, so every consumer group processes a message once. Use it
+// along WithConsumerMiddleware; handlers may then assume messages are processed exactly once while their key
+// remains on the InboxStore (ttl).
+func NewInboxMiddleware(store InboxStore, ttl time.Duration) MiddlewareHandlerFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg *Message) error {
+			err := store.Process(ctx, newInboxKey(msg), ttl, func(scopedCtx context.Context) error {
+				return next(scopedCtx, msg)
+			})
+			if errors.Is(err, ErrMessageAlreadyProcessed) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func newInboxKey(msg *Message) string {
+	return msg.GetConsumerGroup() + "#" + msg.GetMessageID()
+}
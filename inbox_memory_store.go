@@ -0,0 +1,84 @@
+package gluon
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// inMemoryInboxPurgeFrequency Number of processed messages between expired keys purges.
+const inMemoryInboxPurgeFrequency = 1024
+
+// InMemoryInboxStore Is a concurrent-safe InboxStore which keeps processed message keys in memory.
+//
+// As keys are lost if the process restarts, it is intended for single-node deployments and testing.
+type InMemoryInboxStore struct {
+	mu         sync.Mutex
+	processed  map[string]time.Time // Key: message key, Val: expiration time (zero if none)
+	processing map[string]struct{}
+	totalOps   uint
+}
+
+var _ InboxStore = &InMemoryInboxStore{}
+
+// NewInMemoryInboxStore Allocate a new InMemoryInboxStore.
+func NewInMemoryInboxStore() *InMemoryInboxStore {
+	return &InMemoryInboxStore{
+		mu:         sync.Mutex{},
+		processed:  map[string]time.Time{},
+		processing: map[string]struct{}{},
+	}
+}
+
+func (s *InMemoryInboxStore) Process(ctx context.Context, key string, ttl time.Duration,
+	fn func(ctx context.Context) error) error {
+	if err := s.acquire(key); err != nil {
+		return err
+	}
+	err := fn(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.processing, key)
+	if err != nil {
+		return err
+	}
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	s.processed[key] = expiresAt
+	return nil
+}
+
+func (s *InMemoryInboxStore) acquire(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.totalOps++
+	if s.totalOps%inMemoryInboxPurgeFrequency == 0 {
+		s.purgeExpired(time.Now())
+	}
+
+	if expiresAt, ok := s.processed[key]; ok && (expiresAt.IsZero() || expiresAt.After(time.Now())) {
+		return ErrMessageAlreadyProcessed
+	} else if _, ok = s.processing[key]; ok {
+		return ErrMessageBeingProcessed
+	}
+	s.processing[key] = struct{}{}
+	return nil
+}
+
+// PurgeExpired Remove expired keys.
+func (s *InMemoryInboxStore) PurgeExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeExpired(time.Now())
+}
+
+func (s *InMemoryInboxStore) purgeExpired(now time.Time) {
+	for key, expiresAt := range s.processed {
+		if !expiresAt.IsZero() && !expiresAt.After(now) {
+			delete(s.processed, key)
+		}
+	}
+}
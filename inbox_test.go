@@ -0,0 +1,63 @@
+package gluon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboxMiddleware(t *testing.T) {
+	store := NewInMemoryInboxStore()
+	bus := NewBus("local",
+		WithConsumerGroup("foo-service"),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+		WithConsumerMiddleware(NewInboxMiddleware(store, time.Minute)))
+	bus.RegisterSchema(dummySchema{}, WithTopic("foo.topic"))
+	executions := 0
+	sub := bus.Subscribe(dummySchema{}).HandlerFunc(func(_ context.Context, msg *Message) error {
+		executions++
+		if msg.GetAttempt() == 1 {
+			return errRetryDummy
+		}
+		return nil
+	})
+
+	handler := getInternalHandler(bus)
+	msg := &TransportMessage{
+		ID:   "123",
+		Data: []byte(`{"Foo":"bar"}`),
+	}
+	// failed attempts are not recorded, so retries are executed
+	require.NoError(t, handler(context.Background(), sub, msg))
+	assert.Equal(t, 2, executions)
+
+	// duplicate delivery
+	require.NoError(t, handler(context.Background(), sub, msg))
+	assert.Equal(t, 2, executions)
+
+	// the same message on another consumer group
+	require.NoError(t, handler(context.Background(), sub.Group("bar-service"), msg))
+	assert.Equal(t, 4, executions)
+}
+
+func TestInMemoryInboxStore_Process(t *testing.T) {
+	store := NewInMemoryInboxStore()
+	ctx := context.Background()
+	noop := func(context.Context) error {
+		return nil
+	}
+
+	assert.NoError(t, store.Process(ctx, "foo", time.Millisecond*10, noop))
+	assert.ErrorIs(t, store.Process(ctx, "foo", time.Millisecond*10, noop), ErrMessageAlreadyProcessed)
+	err := store.Process(ctx, "bar", 0, func(ctx context.Context) error {
+		return store.Process(ctx, "bar", 0, noop)
+	})
+	assert.ErrorIs(t, err, ErrMessageBeingProcessed)
+
+	time.Sleep(time.Millisecond * 20)
+	store.PurgeExpired()
+	assert.NoError(t, store.Process(ctx, "foo", 0, noop))
+}
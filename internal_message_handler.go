@@ -10,23 +10,54 @@ type InternalMessageHandler func(ctx context.Context, subscriber *Subscriber, me
 
 func getInternalHandler(b *Bus) InternalMessageHandler {
 	return func(ctx context.Context, sub *Subscriber, msg *TransportMessage) error {
-		msgMeta := b.internalSchemaRegistry.getByTopic(sub.key)
-		data := reflect.New(msgMeta.SchemaInternalType)
-		var schemaDef string
-		var err error
-		if b.SchemaRegistry != nil {
-			schemaDef, err = b.SchemaRegistry.GetSchemaDefinition(msgMeta.SchemaName, msgMeta.SchemaVersion)
-			if err != nil {
-				return err
-			}
+		msgMeta := getMessageMetadata(b, sub, msg)
+		if msgMeta == nil {
+			logInternalConsumerError(b, ErrMessageNotRegistered)
+			return routeDeadLetter(ctx, b, sub, msg, 0, false, ErrMessageNotRegistered)
 		}
-		err = b.Marshaler.Unmarshal(schemaDef, msg.Data, data.Interface())
+		data, err := decodeMessageData(b, msgMeta, msg)
 		logInternalConsumerError(b, err)
-		if err != nil && b.isLoggerEnabled() {
-			return err
+		if err != nil {
+			return routeDeadLetter(ctx, b, sub, msg, 0, false, err)
+		}
+		attempts, err := execConsumerWithRetries(ctx, b, sub, msg, data)
+		if err != nil {
+			return routeDeadLetter(ctx, b, sub, msg, attempts, isRetryableError(b, sub, err), err)
+		}
+		return nil
+	}
+}
+
+// decodeMessageData Decode the data of an in-transit message into a new value of the message schema.
+//
+// Messages without data (e.g. Apache Kafka tombstones) are not decoded, returning an invalid reflect.Value.
+func decodeMessageData(b *Bus, msgMeta *MessageMetadata, msg *TransportMessage) (reflect.Value, error) {
+	if msg.Data == nil {
+		return reflect.Value{}, nil
+	}
+	data := reflect.New(msgMeta.SchemaInternalType)
+	var schemaDef string
+	var err error
+	if b.SchemaRegistry != nil {
+		schemaDef, err = b.SchemaRegistry.GetSchemaDefinition(msgMeta.SchemaName, msgMeta.SchemaVersion)
+		if err != nil {
+			return reflect.Value{}, err
 		}
-		return execConsumer(ctx, b, sub, msg, data)
 	}
+	if err = b.Marshaler.Unmarshal(schemaDef, msg.Data, data.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return data, nil
+}
+
+// getMessageMetadata Retrieve the metadata of an in-transit message using the Subscriber's topic.
+//
+// If the topic has no schema (e.g. dead-letter topics), the CloudEvents type of the message is used instead.
+func getMessageMetadata(b *Bus, sub *Subscriber, msg *TransportMessage) *MessageMetadata {
+	if meta := b.internalSchemaRegistry.getByTopic(sub.key); meta != nil {
+		return meta
+	}
+	return b.internalSchemaRegistry.getByTopic(msg.Type)
 }
 
 func logInternalConsumerError(b *Bus, err error) {
@@ -35,17 +66,23 @@ func logInternalConsumerError(b *Bus, err error) {
 	}
 }
 
-func execConsumer(ctx context.Context, b *Bus, sub *Subscriber, msg *TransportMessage, data reflect.Value) error {
+func execConsumer(ctx context.Context, b *Bus, sub *Subscriber, msg *TransportMessage, data reflect.Value,
+	attempt int) error {
 	scopedCtx := injectCorrelationContext(ctx, msg)
+	scopedCtx = context.WithValue(scopedCtx, contextDriver, gluonContextKey(b.driverName))
 	handlerFunc := sub.GetDefaultHandler()
 	for _, mw := range b.consumerMiddleware {
 		if mw != nil {
 			handlerFunc = mw(handlerFunc)
 		}
 	}
+	var msgData interface{}
+	if data.IsValid() {
+		msgData = data.Elem().Interface()
+	}
 	return handlerFunc(scopedCtx, &Message{
-		Headers: generateHeaders(msg, sub),
-		Data:    data.Elem().Interface(),
+		Headers: generateHeaders(msg, sub, getConsumerGroup(b, sub), attempt),
+		Data:    msgData,
 	})
 }
 
@@ -54,5 +91,8 @@ func injectCorrelationContext(ctx context.Context, msg *TransportMessage) contex
 		ctx = context.WithValue(ctx, contextCorrelationID, gluonContextKey(msg.CorrelationID))
 	}
 	ctx = context.WithValue(ctx, contextMessageID, gluonContextKey(msg.ID))
+	if replyTo := msg.Extensions[ExtensionReplyTo]; replyTo != "" {
+		ctx = context.WithValue(ctx, contextReplyTo, gluonContextKey(replyTo))
+	}
 	return ctx
 }
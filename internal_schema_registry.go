@@ -10,6 +10,8 @@ import (
 var (
 	// ErrMessageNotRegistered The message type was not found on the message registry
 	ErrMessageNotRegistered = errors.New("gluon: The specified message type is not present on the message registry")
+	// ErrMessageAlreadyRegistered The message type was already linked to metadata on the message registry
+	ErrMessageAlreadyRegistered = errors.New("gluon: The specified message type is already present on the message registry")
 )
 
 // MessageMetadata Is a set of definitions to describe a specific message schema.
@@ -18,6 +20,8 @@ type MessageMetadata struct {
 	Source        string
 	SchemaName    string
 	SchemaVersion int
+	// PartitionKey Generate the partition key of a message from its data (optional).
+	PartitionKey PartitionKeyFunc
 
 	SchemaInternalType reflect.Type
 }
@@ -44,14 +48,18 @@ func newInternalSchemaRegistry() *internalSchemaRegistry {
 }
 
 func (r *internalSchemaRegistry) register(schema interface{}, meta MessageMetadata) {
+	_ = r.registerType(reflect.TypeOf(schema), meta)
+}
+
+func (r *internalSchemaRegistry) registerType(schemaType reflect.Type, meta MessageMetadata) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	schemaType := reflect.TypeOf(schema)
 	if _, ok := r.registry[schemaType.String()]; ok {
-		return
+		return ErrMessageAlreadyRegistered
 	}
 	meta.SchemaInternalType = schemaType
 	r.registry[schemaType.String()] = &meta
+	return nil
 }
 
 func (r *internalSchemaRegistry) get(schema interface{}) (*MessageMetadata, error) {
@@ -86,3 +94,14 @@ func (r *internalSchemaRegistry) getByTopic(t string) *MessageMetadata {
 	}
 	return nil
 }
+
+// topics Retrieve the topics of every registered schema.
+func (r *internalSchemaRegistry) topics() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	topics := make([]string, 0, len(r.registry))
+	for _, v := range r.registry {
+		topics = append(topics, v.Topic)
+	}
+	return topics
+}
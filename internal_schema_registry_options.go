@@ -5,6 +5,7 @@ type internalSchemaRegistryOptions struct {
 	source     string
 	schemaName string
 	version    int
+	keyFunc    PartitionKeyFunc
 }
 
 // SchemaRegistryOption set a specific configuration for internal schema registry.
@@ -55,3 +56,20 @@ func (o schemaVersionOption) apply(opts *internalSchemaRegistryOptions) {
 func WithSchemaVersion(v int) SchemaRegistryOption {
 	return schemaVersionOption(v)
 }
+
+// PartitionKeyFunc Is a function used to generate the partition key of a message from its data.
+type PartitionKeyFunc func(data interface{}) string
+
+type schemaPartitionKeyOption PartitionKeyFunc
+
+func (o schemaPartitionKeyOption) apply(opts *internalSchemaRegistryOptions) {
+	opts.keyFunc = PartitionKeyFunc(o)
+}
+
+// WithSchemaPartitionKey Set a function to generate the partition key of every message of a schema.
+//
+// The key is carried as the CloudEvents partitioning extension (ExtensionPartitionKey), so drivers supporting
+// partitions route messages with the same key to the same partition. WithPartitionKey overrides it.
+func WithSchemaPartitionKey(f PartitionKeyFunc) SchemaRegistryOption {
+	return schemaPartitionKeyOption(f)
+}
@@ -2,6 +2,9 @@ package gluon
 
 import "time"
 
+// Message Is an in-transit message received by a Subscriber's handler.
+//
+// Data is nil if the message carries no data (e.g. Apache Kafka tombstones).
 type Message struct {
 	Headers map[string]interface{}
 	Data    interface{}
@@ -43,10 +46,19 @@ func (m Message) GetCausationID() string {
 	return m.Headers[headerCausationID].(string)
 }
 
+// GetTraceContext Retrieve the raw distributed tracing header.
+//
+// Deprecated: Use GetTraceCarrier instead.
 func (m Message) GetTraceContext() interface{} {
 	return m.Headers[headerTraceContext]
 }
 
+// GetTraceCarrier Retrieve the distributed tracing carrier (e.g. W3C traceparent and tracestate entries).
+func (m Message) GetTraceCarrier() map[string]string {
+	traceCtx, _ := m.Headers[headerTraceContext].(map[string]string)
+	return traceCtx
+}
+
 func (m Message) GetMessageTime() time.Time {
 	timeStr := m.Headers[headerTime].(string)
 	t, _ := time.Parse(time.RFC3339, timeStr)
@@ -56,3 +68,25 @@ func (m Message) GetMessageTime() time.Time {
 func (m Message) GetConsumerGroup() string {
 	return m.Headers[headerConsumerGroup].(string)
 }
+
+// GetTopic Retrieve the topic the message was consumed from.
+func (m Message) GetTopic() string {
+	topic, _ := m.Headers[headerTopic].(string)
+	return topic
+}
+
+// GetExtension Retrieve a CloudEvents extension attribute.
+func (m Message) GetExtension(name string) string {
+	ext, _ := m.Headers[name].(string)
+	return ext
+}
+
+// GetAttempt Retrieve the current handler execution attempt (starting from 1).
+//
+// Values greater than 1 indicate the message is being retried by a RetryPolicy.
+func (m Message) GetAttempt() int {
+	if attempt, ok := m.Headers[headerAttempt].(int); ok {
+		return attempt
+	}
+	return 1
+}
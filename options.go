@@ -20,6 +20,12 @@ type options struct {
 	cluster             []string
 	consumerMiddleware  []MiddlewareHandlerFunc
 	publisherMiddleware []MiddlewarePublisherFunc
+	retryPolicy         *RetryPolicy
+	deadLetterPolicy    *DeadLetterPolicy
+	driverHooks         DriverHooks
+	outbox              *OutboxConfig
+	replyTopic          string
+	contentMode         ContentMode
 }
 
 // Option set a specific configuration of a resource (e.g. bus).
@@ -169,3 +175,83 @@ func (o publisherMiddlewareOption) apply(opts *options) {
 func WithPublisherMiddleware(f ...MiddlewarePublisherFunc) Option {
 	return publisherMiddlewareOption(f)
 }
+
+type retryPolicyOption RetryPolicy
+
+func (o retryPolicyOption) apply(opts *options) {
+	p := RetryPolicy(o)
+	opts.retryPolicy = &p
+}
+
+// WithRetryPolicy Set a global RetryPolicy for `Gluon` consumer handlers.
+//
+// Subscribers may override it using Subscriber.RetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return retryPolicyOption(p)
+}
+
+type deadLetterPolicyOption DeadLetterPolicy
+
+func (o deadLetterPolicyOption) apply(opts *options) {
+	p := DeadLetterPolicy(o)
+	opts.deadLetterPolicy = &p
+}
+
+// WithDeadLetterPolicy Set a global DeadLetterPolicy for `Gluon` consumer handlers.
+//
+// Subscribers may override it using Subscriber.DeadLetterPolicy.
+func WithDeadLetterPolicy(p DeadLetterPolicy) Option {
+	return deadLetterPolicyOption(p)
+}
+
+type driverHooksOption DriverHooks
+
+func (o driverHooksOption) apply(opts *options) {
+	opts.driverHooks = DriverHooks(o)
+}
+
+// WithDriverHooks Set the callbacks used by drivers to report internal operations (e.g. consumer lag).
+func WithDriverHooks(h DriverHooks) Option {
+	return driverHooksOption(h)
+}
+
+type outboxOption OutboxConfig
+
+func (o outboxOption) apply(opts *options) {
+	cfg := OutboxConfig(o)
+	opts.outbox = &cfg
+}
+
+// WithOutbox Enable the transactional outbox. Publishing operations will write messages into the OutboxStore and
+// a relay worker will forward them to the driver.
+func WithOutbox(cfg OutboxConfig) Option {
+	return outboxOption(cfg)
+}
+
+type replyTopicOption string
+
+func (o replyTopicOption) apply(opts *options) {
+	opts.replyTopic = string(o)
+}
+
+// WithReplyTopic Enable request/reply messaging (Bus.Request) using the given topic to receive replies.
+//
+// The reply topic is consumed using a consumer group with the same name, so it must be unique for each Bus
+// instance (e.g. org.neutrino.warehouse.reply.<hostname>).
+func WithReplyTopic(topic string) Option {
+	return replyTopicOption(topic)
+}
+
+type contentModeOption ContentMode
+
+func (o contentModeOption) apply(opts *options) {
+	opts.contentMode = ContentMode(o)
+}
+
+// WithContentMode Set the CloudEvents content mode used by drivers to encode messages (ContentModeBinary by
+// default).
+//
+// Consumers detect the content mode of every message, so producers and consumers may use different content modes.
+func WithContentMode(m ContentMode) Option {
+	return contentModeOption(m)
+}
@@ -0,0 +1,61 @@
+package gluon
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultOutboxPollingInterval = time.Second
+	defaultOutboxBatchSize       = 100
+)
+
+// OutboxRecord Is a message stored on an OutboxStore, pending to be dispatched.
+type OutboxRecord struct {
+	// ID Unique identifier of the record within the OutboxStore (usually the message ID).
+	ID      string
+	Message *TransportMessage
+}
+
+// OutboxStore Is a database used by the transactional outbox to persist messages before they are dispatched to
+// the driver.
+//
+// Implementations may write messages within a caller's transaction (e.g. gsql.WithTx), so messages are only
+// dispatched if the transaction was committed.
+type OutboxStore interface {
+	// Save Persist messages pending to be dispatched.
+	Save(ctx context.Context, msgs ...*TransportMessage) error
+	// Fetch Retrieve up to `limit` pending records ordered by insertion, skipping records of `excludedTopics`.
+	Fetch(ctx context.Context, limit int, excludedTopics ...string) ([]OutboxRecord, error)
+	// MarkDispatched Remove records which were dispatched successfully.
+	MarkDispatched(ctx context.Context, ids ...string) error
+}
+
+// OutboxConfig Is the configuration of the transactional outbox.
+//
+// When set (WithOutbox), Bus publishing operations write messages into the OutboxStore instead of calling the driver.
+// Then, a relay worker started by Bus.ListenAndServe drains the OutboxStore and forwards messages through the
+// publisher middleware chain and the driver, preserving ordering per topic and at-least-once guarantees.
+type OutboxConfig struct {
+	Store OutboxStore
+	// PollingInterval Waiting time between OutboxStore polling operations.
+	PollingInterval time.Duration
+	// BatchSize Maximum number of records fetched per polling operation.
+	BatchSize int
+}
+
+// GetPollingInterval Retrieve the waiting time between OutboxStore polling operations.
+func (c OutboxConfig) GetPollingInterval() time.Duration {
+	if c.PollingInterval <= 0 {
+		return defaultOutboxPollingInterval
+	}
+	return c.PollingInterval
+}
+
+// GetBatchSize Retrieve the maximum number of records fetched per polling operation.
+func (c OutboxConfig) GetBatchSize() int {
+	if c.BatchSize <= 0 {
+		return defaultOutboxBatchSize
+	}
+	return c.BatchSize
+}
@@ -0,0 +1,72 @@
+package gluon
+
+import (
+	"context"
+	"sync"
+)
+
+// InMemoryOutboxStore Is a concurrent-safe OutboxStore which keeps messages in memory.
+//
+// As messages are lost if the process crashes, it is intended for local environments and testing.
+type InMemoryOutboxStore struct {
+	mu      sync.Mutex
+	records []OutboxRecord
+}
+
+var _ OutboxStore = &InMemoryOutboxStore{}
+
+// NewInMemoryOutboxStore Allocate a new InMemoryOutboxStore.
+func NewInMemoryOutboxStore() *InMemoryOutboxStore {
+	return &InMemoryOutboxStore{
+		mu:      sync.Mutex{},
+		records: make([]OutboxRecord, 0),
+	}
+}
+
+func (s *InMemoryOutboxStore) Save(_ context.Context, msgs ...*TransportMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, msg := range msgs {
+		s.records = append(s.records, OutboxRecord{
+			ID:      msg.ID,
+			Message: msg,
+		})
+	}
+	return nil
+}
+
+func (s *InMemoryOutboxStore) Fetch(_ context.Context, limit int, excludedTopics ...string) ([]OutboxRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	excluded := make(map[string]struct{}, len(excludedTopics))
+	for _, topic := range excludedTopics {
+		excluded[topic] = struct{}{}
+	}
+	records := make([]OutboxRecord, 0, len(s.records))
+	for _, record := range s.records {
+		if limit > 0 && len(records) == limit {
+			break
+		} else if _, ok := excluded[record.Message.Topic]; ok {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (s *InMemoryOutboxStore) MarkDispatched(_ context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dispatched := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		dispatched[id] = struct{}{}
+	}
+	pending := s.records[:0]
+	for _, record := range s.records {
+		if _, ok := dispatched[record.ID]; !ok {
+			pending = append(pending, record)
+		}
+	}
+	s.records = pending
+	return nil
+}
@@ -0,0 +1,99 @@
+package gluon
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// outboxRelay Is an internal worker which drains an OutboxStore and forwards messages to the driver.
+type outboxRelay struct {
+	bus *Bus
+	cfg OutboxConfig
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newOutboxRelay(b *Bus, cfg OutboxConfig) *outboxRelay {
+	return &outboxRelay{
+		bus: b,
+		cfg: cfg,
+		mu:  sync.Mutex{},
+	}
+}
+
+func (r *outboxRelay) start(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.cfg.GetPollingInterval())
+		defer ticker.Stop()
+		for {
+			// drain the store as long as full batches are being fetched. Topics which failed are excluded until the
+			// next polling operation, so they back off without holding up other topics.
+			failedTopics := map[string]struct{}{}
+			for r.dispatch(ctx, failedTopics) && ctx.Err() == nil {
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// dispatch Forward a batch of records to the driver. Returns true if more records might be pending.
+//
+// Records are dispatched in insertion order. If a record of a topic fails, the topic is added to `failedTopics` and
+// its remaining records are skipped (and excluded from later fetches) to preserve ordering per topic.
+func (r *outboxRelay) dispatch(ctx context.Context, failedTopics map[string]struct{}) bool {
+	excludedTopics := make([]string, 0, len(failedTopics))
+	for topic := range failedTopics {
+		excludedTopics = append(excludedTopics, topic)
+	}
+	records, err := r.cfg.Store.Fetch(ctx, r.cfg.GetBatchSize(), excludedTopics...)
+	if err != nil {
+		logInternalError(r.bus, NewError("OutboxFailedFetching", "Failed to fetch messages from outbox", err))
+		return false
+	}
+
+	dispatched := make([]string, 0, len(records))
+	for _, record := range records {
+		if _, ok := failedTopics[record.Message.Topic]; ok {
+			continue
+		}
+		if err = r.bus.publishTransportMessage(ctx, record.Message); err != nil {
+			failedTopics[record.Message.Topic] = struct{}{}
+			logInternalError(r.bus, NewError("OutboxFailedDispatching",
+				"Failed to dispatch message ("+record.Message.ID+") from outbox", err))
+			continue
+		}
+		dispatched = append(dispatched, record.ID)
+	}
+	if len(dispatched) == 0 {
+		// failed topics are excluded from the next fetch
+		return len(records) == r.cfg.GetBatchSize()
+	}
+	if err = r.cfg.Store.MarkDispatched(ctx, dispatched...); err != nil {
+		// messages will be dispatched again (at-least-once)
+		logInternalError(r.bus, NewError("OutboxFailedAcknowledging",
+			"Failed to mark outbox messages as dispatched", err))
+		return false
+	}
+	return len(records) == r.cfg.GetBatchSize()
+}
+
+func (r *outboxRelay) close() {
+	r.mu.Lock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+}
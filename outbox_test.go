@@ -0,0 +1,102 @@
+package gluon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type topicFailingDriver struct {
+	publishRecorderDriver
+	failingTopic string
+}
+
+func (d *topicFailingDriver) Publish(ctx context.Context, message *TransportMessage) error {
+	if message.Topic == d.failingTopic {
+		return errors.New("driver error")
+	}
+	return d.publishRecorderDriver.Publish(ctx, message)
+}
+
+func TestOutbox_Dispatch(t *testing.T) {
+	store := NewInMemoryOutboxStore()
+	driver := &topicFailingDriver{failingTopic: "bar.topic"}
+	bus := NewBus("local", WithOutbox(OutboxConfig{
+		Store:     store,
+		BatchSize: 10,
+	}))
+	bus.driver = driver
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bus.PublishRaw(ctx, &TransportMessage{ID: "foo-" + string(rune('a'+i)), Topic: "foo.topic"}))
+		require.NoError(t, bus.PublishRaw(ctx, &TransportMessage{ID: "bar-" + string(rune('a'+i)), Topic: "bar.topic"}))
+	}
+	assert.Len(t, driver.published, 0)
+	pending, err := store.Fetch(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, pending, 6)
+
+	failedTopics := map[string]struct{}{}
+	assert.False(t, bus.outbox.dispatch(ctx, failedTopics))
+	assert.Equal(t, map[string]struct{}{"bar.topic": {}}, failedTopics)
+	require.Len(t, driver.published, 3)
+	for i, msg := range driver.published {
+		// ordering per topic is kept
+		assert.Equal(t, "foo-"+string(rune('a'+i)), msg.ID)
+		assert.Equal(t, msg.ID, msg.CorrelationID)
+	}
+
+	// failed messages are kept for further dispatching (at-least-once)
+	pending, err = store.Fetch(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+	for _, record := range pending {
+		assert.Equal(t, "bar.topic", record.Message.Topic)
+	}
+
+	// failed topics are excluded until the next polling operation
+	pending, err = store.Fetch(ctx, 0, "bar.topic")
+	require.NoError(t, err)
+	assert.Len(t, pending, 0)
+
+	driver.failingTopic = ""
+	assert.False(t, bus.outbox.dispatch(ctx, map[string]struct{}{}))
+	assert.Len(t, driver.published, 6)
+	pending, err = store.Fetch(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, pending, 0)
+}
+
+func TestOutbox_DispatchFailingTopicBatch(t *testing.T) {
+	store := NewInMemoryOutboxStore()
+	driver := &topicFailingDriver{failingTopic: "bar.topic"}
+	bus := NewBus("local", WithOutbox(OutboxConfig{
+		Store:     store,
+		BatchSize: 2,
+	}))
+	bus.driver = driver
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, bus.PublishRaw(ctx, &TransportMessage{ID: "bar-" + string(rune('a'+i)), Topic: "bar.topic"}))
+	}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bus.PublishRaw(ctx, &TransportMessage{ID: "foo-" + string(rune('a'+i)), Topic: "foo.topic"}))
+	}
+
+	// records of the failing topic fill the first batch, records of other topics are still dispatched
+	failedTopics := map[string]struct{}{}
+	for bus.outbox.dispatch(ctx, failedTopics) {
+	}
+	require.Len(t, driver.published, 3)
+	for i, msg := range driver.published {
+		assert.Equal(t, "foo-"+string(rune('a'+i)), msg.ID)
+	}
+	pending, err := store.Fetch(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, pending, 4)
+}
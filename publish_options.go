@@ -0,0 +1,175 @@
+package gluon
+
+import "errors"
+
+// ExtensionPartitionKey Is the CloudEvents partitioning extension attribute. Drivers supporting partitions use it to
+// route related messages to the same partition (e.g. Apache Kafka record key).
+//
+// For more information, check https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/extensions/partitioning.md
+const ExtensionPartitionKey = "partitionkey"
+
+// maxExtensionNameLength Maximum length of extension attribute names recommended by the CloudEvents specification.
+const maxExtensionNameLength = 20
+
+// ErrInvalidExtension The extension attribute name is not a lower-case alphanumeric name (up to 20 characters) or it
+// is reserved by CloudEvents context attributes or `Gluon` internals.
+var ErrInvalidExtension = errors.New("gluon: Invalid CloudEvents extension attribute name")
+
+// reservedAttributes CloudEvents context attributes and extension attributes managed by `Gluon` internals.
+var reservedAttributes = map[string]struct{}{
+	"id":                   {},
+	"source":               {},
+	"specversion":          {},
+	"type":                 {},
+	"datacontenttype":      {},
+	"dataschema":           {},
+	"subject":              {},
+	"time":                 {},
+	"data":                 {},
+	"data_base64":          {},
+	ExtensionCorrelationID: {},
+	ExtensionCausationID:   {},
+	ExtensionTraceParent:   {},
+	ExtensionTraceState:    {},
+	ExtensionReplyTo:       {},
+	// dead-letter metadata
+	ExtensionFailureReason:  {},
+	ExtensionFailedAttempts: {},
+	ExtensionOriginalTopic:  {},
+	ExtensionConsumerGroup:  {},
+	// consumer message headers which would shadow extensions (Message.GetExtension)
+	headerAttempt: {},
+	headerTopic:   {},
+}
+
+type publishOptions struct {
+	topic      string
+	msgType    string
+	subject    string
+	extensions map[string]string
+}
+
+// PublishOption set a specific configuration of a publishing operation (e.g. Bus.Publish).
+type PublishOption interface {
+	apply(*publishOptions)
+}
+
+func newPublishOptions(opts []PublishOption) publishOptions {
+	options := publishOptions{}
+	for _, o := range opts {
+		o.apply(&options)
+	}
+	return options
+}
+
+type publishTopicOption string
+
+func (o publishTopicOption) apply(opts *publishOptions) {
+	opts.topic = string(o)
+}
+
+// WithPublishTopic Publish a message using the schema registered with the given topic instead of the schema of the
+// message data.
+func WithPublishTopic(topic string) PublishOption {
+	return publishTopicOption(topic)
+}
+
+type publishTypeOption string
+
+func (o publishTypeOption) apply(opts *publishOptions) {
+	opts.msgType = string(o)
+}
+
+// WithPublishType Publish a message using the schema registered with the given Go type name (e.g. main.ItemPaid)
+// instead of the schema of the message data.
+func WithPublishType(msgType string) PublishOption {
+	return publishTypeOption(msgType)
+}
+
+type subjectOption string
+
+func (o subjectOption) apply(opts *publishOptions) {
+	opts.subject = string(o)
+}
+
+// WithSubject Set the CloudEvents `subject` attribute of a message.
+func WithSubject(subject string) PublishOption {
+	return subjectOption(subject)
+}
+
+type extensionOption struct {
+	name  string
+	value string
+}
+
+func (o extensionOption) apply(opts *publishOptions) {
+	if opts.extensions == nil {
+		opts.extensions = map[string]string{}
+	}
+	opts.extensions[o.name] = o.value
+}
+
+// WithExtension Set a CloudEvents extension attribute of a message. The name MUST be a lower-case alphanumeric name
+// of up to 20 characters which is not reserved by `Gluon` internals (e.g. ExtensionReplyTo), otherwise the publishing
+// operation returns ErrInvalidExtension.
+//
+// Extension attributes are carried by every driver and exposed to consumers through Message.Headers
+// (Message.GetExtension).
+func WithExtension(name, value string) PublishOption {
+	return extensionOption{name: name, value: value}
+}
+
+// WithPartitionKey Set the CloudEvents partitioning extension attribute (ExtensionPartitionKey) of a message.
+func WithPartitionKey(key string) PublishOption {
+	return extensionOption{name: ExtensionPartitionKey, value: key}
+}
+
+// getMetadata Retrieve the metadata of the message schema from the internal schema registry.
+func (o publishOptions) getMetadata(r *internalSchemaRegistry, data interface{}) (*MessageMetadata, error) {
+	switch {
+	case o.topic != "":
+		if meta := r.getByTopic(o.topic); meta != nil {
+			return meta, nil
+		}
+		return nil, ErrMessageNotRegistered
+	case o.msgType != "":
+		return r.getByKey(o.msgType)
+	default:
+		return r.get(data)
+	}
+}
+
+// applyMessage Set the message attributes defined by the options.
+func (o publishOptions) applyMessage(msg *TransportMessage) error {
+	if o.subject != "" {
+		msg.Subject = o.subject
+	}
+	if len(o.extensions) == 0 {
+		return nil
+	}
+	if msg.Extensions == nil {
+		msg.Extensions = make(map[string]string, len(o.extensions))
+	}
+	for k, v := range o.extensions {
+		if !isValidExtensionName(k) {
+			return ErrInvalidExtension
+		}
+		msg.Extensions[k] = v
+	}
+	return nil
+}
+
+func isValidExtensionName(name string) bool {
+	if name == "" || len(name) > maxExtensionNameLength {
+		return false
+	}
+	if _, ok := reservedAttributes[name]; ok {
+		return false
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package gluon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type otherDummySchema struct {
+	Bar string
+}
+
+var publishOptionsTestCases = []struct {
+	Name           string
+	Data           interface{}
+	Opts           []PublishOption
+	Err            error
+	WantTopic      string
+	WantSubject    string
+	WantExtensions map[string]string
+}{
+	{
+		Name:      "Schema of data",
+		Data:      dummySchema{},
+		WantTopic: "foo.topic",
+	},
+	{
+		Name: "Unregistered schema",
+		Data: struct{}{},
+		Err:  ErrMessageNotRegistered,
+	},
+	{
+		Name:      "Topic",
+		Data:      dummySchema{},
+		Opts:      []PublishOption{WithPublishTopic("bar.topic")},
+		WantTopic: "bar.topic",
+	},
+	{
+		Name: "Unregistered topic",
+		Data: dummySchema{},
+		Opts: []PublishOption{WithPublishTopic("baz.topic")},
+		Err:  ErrMessageNotRegistered,
+	},
+	{
+		Name:      "Type",
+		Data:      dummySchema{},
+		Opts:      []PublishOption{WithPublishType("gluon.otherDummySchema")},
+		WantTopic: "bar.topic",
+	},
+	{
+		Name: "Attributes",
+		Data: dummySchema{},
+		Opts: []PublishOption{
+			WithSubject("abc"),
+			WithExtension("tenantid", "neutrino"),
+			WithPartitionKey("abc"),
+		},
+		WantTopic:   "foo.topic",
+		WantSubject: "abc",
+		WantExtensions: map[string]string{
+			"tenantid":            "neutrino",
+			ExtensionPartitionKey: "abc",
+		},
+	},
+	{
+		Name: "Invalid extension name",
+		Data: dummySchema{},
+		Opts: []PublishOption{WithExtension("tenant_id", "neutrino")},
+		Err:  ErrInvalidExtension,
+	},
+	{
+		Name: "Reserved extension name",
+		Data: dummySchema{},
+		Opts: []PublishOption{WithExtension(ExtensionCorrelationID, "123")},
+		Err:  ErrInvalidExtension,
+	},
+	{
+		Name: "Reserved reply extension name",
+		Data: dummySchema{},
+		Opts: []PublishOption{WithExtension(ExtensionReplyTo, "bar.topic")},
+		Err:  ErrInvalidExtension,
+	},
+	{
+		Name: "Reserved dead-letter extension name",
+		Data: dummySchema{},
+		Opts: []PublishOption{WithExtension(ExtensionFailedAttempts, "1")},
+		Err:  ErrInvalidExtension,
+	},
+	{
+		Name: "Reserved consumer header name",
+		Data: dummySchema{},
+		Opts: []PublishOption{WithExtension("topic", "foo.topic")},
+		Err:  ErrInvalidExtension,
+	},
+	{
+		Name: "Extension name too long",
+		Data: dummySchema{},
+		Opts: []PublishOption{WithExtension("abcdefghijklmnopqrstu", "neutrino")},
+		Err:  ErrInvalidExtension,
+	},
+}
+
+func TestBus_Publish(t *testing.T) {
+	for _, tt := range publishOptionsTestCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			driver := &publishRecorderDriver{}
+			bus := NewBus("local")
+			bus.driver = driver
+			bus.RegisterSchema(dummySchema{}, WithTopic("foo.topic"))
+			bus.RegisterSchema(otherDummySchema{}, WithTopic("bar.topic"))
+
+			err := bus.Publish(context.Background(), tt.Data, tt.Opts...)
+			assert.ErrorIs(t, err, tt.Err)
+			if tt.Err != nil {
+				assert.Empty(t, driver.published)
+				return
+			}
+			require.Len(t, driver.published, 1)
+			msg := driver.published[0]
+			assert.Equal(t, tt.WantTopic, msg.Topic)
+			assert.Equal(t, tt.WantSubject, msg.Subject)
+			assert.Equal(t, tt.WantExtensions, msg.Extensions)
+		})
+	}
+}
+
+func TestBus_Publish_SchemaPartitionKey(t *testing.T) {
+	driver := &publishRecorderDriver{}
+	bus := NewBus("local")
+	bus.driver = driver
+	bus.RegisterSchema(dummySchema{}, WithTopic("foo.topic"), WithSchemaPartitionKey(func(data interface{}) string {
+		return data.(dummySchema).Foo
+	}))
+
+	require.NoError(t, bus.Publish(context.Background(), dummySchema{Foo: "abc"}))
+	require.NoError(t, bus.Publish(context.Background(), dummySchema{Foo: "abc"}, WithPartitionKey("def")))
+	require.Len(t, driver.published, 2)
+	assert.Equal(t, "abc", driver.published[0].Extensions[ExtensionPartitionKey])
+	assert.Equal(t, "def", driver.published[1].Extensions[ExtensionPartitionKey])
+}
+
+func TestReservedAttributes_Headers(t *testing.T) {
+	// extensions sharing the name of a consumer header would be shadowed by the header
+	headers := generateHeaders(&TransportMessage{}, &Subscriber{}, "", 1)
+	for k := range headers {
+		if isValidExtensionName(k) {
+			t.Errorf("header (%s) is a valid extension name", k)
+		}
+	}
+}
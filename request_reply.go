@@ -0,0 +1,111 @@
+package gluon
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ExtensionReplyTo Is the CloudEvents extension attribute which holds the topic where a reply is expected.
+const ExtensionReplyTo = "replyto"
+
+var (
+	// ErrRequestReplyDisabled The Bus has no reply topic (WithReplyTopic).
+	ErrRequestReplyDisabled = errors.New("gluon: Request/reply is disabled, no reply topic was set")
+	// ErrMissingReplyTopic The message being handled does not expect a reply.
+	ErrMissingReplyTopic = errors.New("gluon: The message being handled has no reply topic")
+)
+
+// replyRegistry Is a concurrent-safe internal agent used to route replies to pending requests.
+type replyRegistry struct {
+	mu      sync.Mutex
+	pending map[string]chan *Message // Key: request message ID
+}
+
+func newReplyRegistry() *replyRegistry {
+	return &replyRegistry{
+		mu:      sync.Mutex{},
+		pending: map[string]chan *Message{},
+	}
+}
+
+func (r *replyRegistry) register(requestID string) <-chan *Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	replyChan := make(chan *Message, 1)
+	r.pending[requestID] = replyChan
+	return replyChan
+}
+
+func (r *replyRegistry) remove(requestID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, requestID)
+}
+
+// handle Route a reply to its request using the reply causation ID. Replies of expired requests are discarded.
+func (r *replyRegistry) handle(_ context.Context, msg *Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	replyChan, ok := r.pending[msg.GetCausationID()]
+	if !ok {
+		return nil
+	}
+	delete(r.pending, msg.GetCausationID())
+	replyChan <- msg
+	return nil
+}
+
+// Request Propagate a message to the ecosystem and wait for its reply.
+//
+// The request carries the Bus reply topic (WithReplyTopic) as the ExtensionReplyTo extension attribute. Consumers
+// answer using Bus.Reply, and the reply is matched to this request using its causation ID.
+//
+// The reply schema must be registered (Bus.RegisterSchema) to decode the reply data. Use ctx to set a timeout,
+// otherwise Request waits until a reply arrives.
+func (b *Bus) Request(ctx context.Context, data interface{}, opts ...PublishOption) (*Message, error) {
+	if b.replyTopic == "" {
+		return nil, ErrRequestReplyDisabled
+	}
+	msg, err := b.generatePublishMessage(data, newPublishOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	if msg.Extensions == nil {
+		msg.Extensions = map[string]string{}
+	}
+	msg.Extensions[ExtensionReplyTo] = b.replyTopic
+
+	replyChan := b.replies.register(msg.ID)
+	defer b.replies.remove(msg.ID)
+	// requests skip the transactional outbox as a caller waits for them
+	b.injectMessageContext(ctx, msg)
+	if err = b.publishTransportMessage(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	select {
+	case reply := <-replyChan:
+		return reply, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Reply Propagate a reply to the message being handled (ctx must be a Subscription's context).
+//
+// The reply is published to the topic set by the requester (ExtensionReplyTo) and its causation ID is the request
+// message ID.
+func (b *Bus) Reply(ctx context.Context, data interface{}, opts ...PublishOption) error {
+	replyTo, ok := ctx.Value(contextReplyTo).(gluonContextKey)
+	if !ok || replyTo == "" {
+		return ErrMissingReplyTopic
+	}
+	msg, err := b.generatePublishMessage(data, newPublishOptions(opts))
+	if err != nil {
+		return err
+	}
+	msg.Topic = string(replyTo)
+	b.injectMessageContext(ctx, msg)
+	return b.publishTransportMessage(ctx, msg)
+}
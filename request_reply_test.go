@@ -0,0 +1,79 @@
+package gluon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loopbackDriver Is an in-process driver which delivers published messages to subscribers of the same topic.
+type loopbackDriver struct {
+	publishRecorderDriver
+	bus     *Bus
+	handler InternalMessageHandler
+}
+
+func (d *loopbackDriver) SetParentBus(b *Bus) {
+	d.bus = b
+}
+
+func (d *loopbackDriver) SetInternalHandler(h InternalMessageHandler) {
+	d.handler = h
+}
+
+func (d *loopbackDriver) Publish(ctx context.Context, message *TransportMessage) error {
+	if err := d.publishRecorderDriver.Publish(ctx, message); err != nil {
+		return err
+	}
+	for _, sub := range d.bus.ListSubscribersFromTopic(message.Topic) {
+		go func(s *Subscriber) {
+			_ = d.handler(context.Background(), s, message)
+		}(sub)
+	}
+	return nil
+}
+
+type pingRequested struct {
+	Value string
+}
+
+type pongReplied struct {
+	Value string
+}
+
+func TestBus_Request(t *testing.T) {
+	bus := NewBus("local", WithReplyTopic("foo.reply.instance-1"))
+	bus.driver = &loopbackDriver{}
+	bus.RegisterSchema(pingRequested{}, WithTopic("foo.ping"))
+	bus.RegisterSchema(pongReplied{}, WithTopic("foo.pong"))
+	bus.Subscribe(pingRequested{}).HandlerFunc(func(ctx context.Context, msg *Message) error {
+		return bus.Reply(ctx, pongReplied{Value: msg.Data.(pingRequested).Value + "-pong"})
+	})
+	require.NoError(t, bus.ListenAndServe())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	reply, err := bus.Request(ctx, pingRequested{Value: "ping"})
+	require.NoError(t, err)
+	assert.Equal(t, pongReplied{Value: "ping-pong"}, reply.Data)
+	assert.Equal(t, "foo.reply.instance-1", reply.GetTopic())
+
+	// no reply
+	bus.RegisterSchema(dummySchema{}, WithTopic("foo.topic"))
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	_, err = bus.Request(ctx, dummySchema{Foo: "bar"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// not a request
+	assert.ErrorIs(t, bus.Reply(context.Background(), pongReplied{}), ErrMissingReplyTopic)
+}
+
+func TestBus_RequestDisabled(t *testing.T) {
+	bus := NewBus("local")
+	_, err := bus.Request(context.Background(), pingRequested{})
+	assert.ErrorIs(t, err, ErrRequestReplyDisabled)
+}
@@ -0,0 +1,144 @@
+package gluon
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = time.Millisecond * 100
+	defaultRetryMaxBackoff     = time.Second * 10
+	defaultRetryMultiplier     = 2
+)
+
+// RetryPolicy Is a set of rules used by `Gluon` internals to re-execute a consumer handler when it fails.
+//
+// Retries are executed by the Bus before a driver gets the handler result, so every driver (e.g. Apache Kafka,
+// AWS SNS/SQS) behaves the same way. The current attempt is exposed on Message headers (Message.GetAttempt).
+//
+// A RetryPolicy may be set globally using WithRetryPolicy or per-subscriber using Subscriber.RetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts Total number of handler executions, including the first one.
+	MaxAttempts int
+	// InitialBackoff Waiting time before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff Upper limit for the waiting time between retries.
+	MaxBackoff time.Duration
+	// Multiplier Factor applied to the backoff after each failed attempt (exponential backoff).
+	Multiplier float64
+	// Jitter Fraction (0 to 1) of the computed backoff used to randomize waiting times between retries.
+	Jitter float64
+	// IsRetryable Classifies a handler error. If nil, every error is retried except context cancellations.
+	IsRetryable func(err error) bool
+}
+
+// GetMaxAttempts Retrieve the total number of handler executions.
+func (p RetryPolicy) GetMaxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return defaultRetryMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// GetInitialBackoff Retrieve the waiting time before the first retry.
+func (p RetryPolicy) GetInitialBackoff() time.Duration {
+	if p.InitialBackoff <= 0 {
+		return defaultRetryInitialBackoff
+	}
+	return p.InitialBackoff
+}
+
+// GetMaxBackoff Retrieve the upper limit for the waiting time between retries.
+func (p RetryPolicy) GetMaxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return defaultRetryMaxBackoff
+	}
+	return p.MaxBackoff
+}
+
+// GetMultiplier Retrieve the factor applied to the backoff after each failed attempt.
+func (p RetryPolicy) GetMultiplier() float64 {
+	if p.Multiplier < 1 {
+		return defaultRetryMultiplier
+	}
+	return p.Multiplier
+}
+
+// ShouldRetry Indicate if a handler error is retryable.
+func (p RetryPolicy) ShouldRetry(err error) bool {
+	if err == nil {
+		return false
+	} else if p.IsRetryable != nil {
+		return p.IsRetryable(err)
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Backoff Compute the waiting time after the given failed attempt (starting from 1).
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	maxBackoff := float64(p.GetMaxBackoff())
+	backoff := math.Min(float64(p.GetInitialBackoff())*math.Pow(p.GetMultiplier(), float64(attempt-1)), maxBackoff)
+	if jitter := math.Min(math.Max(p.Jitter, 0), 1); jitter > 0 {
+		// spreads the backoff in the range [backoff - jitter, backoff + jitter], never exceeding MaxBackoff
+		delta := backoff * jitter
+		backoff = math.Min(backoff-delta+rand.Float64()*(2*delta), maxBackoff)
+	}
+	return time.Duration(backoff)
+}
+
+// AttemptFailedFunc Is executed by the Bus after a failed handler execution, before the handler is retried or the
+// message is routed to a dead-letter topic.
+type AttemptFailedFunc func(ctx context.Context, attempt int, err error) error
+
+type attemptFailedContextKey struct{}
+
+// WithAttemptFailedFunc Attach an AttemptFailedFunc to the context given to an InternalMessageHandler.
+//
+// Drivers use it to discard side effects of failed handler executions (e.g. messages published within a
+// transaction), so retries and dead-letter routing start from a clean state. If fn fails, the handler is not retried
+// and its error is returned instead.
+func WithAttemptFailedFunc(ctx context.Context, fn AttemptFailedFunc) context.Context {
+	return context.WithValue(ctx, attemptFailedContextKey{}, fn)
+}
+
+// getRetryPolicy Retrieve the retry policy of a Subscriber, falling back to the Bus global retry policy.
+func getRetryPolicy(b *Bus, sub *Subscriber) *RetryPolicy {
+	if p := sub.GetRetryPolicy(); p != nil {
+		return p
+	}
+	return b.Configuration.RetryPolicy
+}
+
+// execConsumerWithRetries Execute a consumer handler following the retry policy of the given Subscriber.
+//
+// Returns the total number of handler executions along the last handler error.
+func execConsumerWithRetries(ctx context.Context, b *Bus, sub *Subscriber, msg *TransportMessage,
+	data reflect.Value) (int, error) {
+	policy := getRetryPolicy(b, sub)
+	onAttemptFailed, _ := ctx.Value(attemptFailedContextKey{}).(AttemptFailedFunc)
+	for attempt := 1; ; attempt++ {
+		err := execConsumer(ctx, b, sub, msg, data, attempt)
+		if err != nil && onAttemptFailed != nil {
+			if errHook := onAttemptFailed(ctx, attempt, err); errHook != nil {
+				return attempt, errHook
+			}
+		}
+		if policy == nil || attempt >= policy.GetMaxAttempts() || !policy.ShouldRetry(err) {
+			return attempt, err
+		}
+		logInternalConsumerError(b, err)
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-time.After(policy.Backoff(attempt)):
+		}
+	}
+}
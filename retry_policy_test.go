@@ -0,0 +1,190 @@
+package gluon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var retryPolicyBackoffTestCases = []struct {
+	In      RetryPolicy
+	Attempt int
+	Want    time.Duration
+}{
+	{
+		In:      RetryPolicy{},
+		Attempt: 1,
+		Want:    defaultRetryInitialBackoff,
+	},
+	{
+		In:      RetryPolicy{},
+		Attempt: 3,
+		Want:    defaultRetryInitialBackoff * 4,
+	},
+	{
+		In: RetryPolicy{
+			InitialBackoff: time.Second,
+			MaxBackoff:     time.Second * 5,
+			Multiplier:     3,
+		},
+		Attempt: 2,
+		Want:    time.Second * 3,
+	},
+	{
+		In: RetryPolicy{
+			InitialBackoff: time.Second,
+			MaxBackoff:     time.Second * 5,
+			Multiplier:     3,
+		},
+		Attempt: 4,
+		Want:    time.Second * 5,
+	},
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	for _, tt := range retryPolicyBackoffTestCases {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, tt.Want, tt.In.Backoff(tt.Attempt))
+		})
+	}
+}
+
+func TestRetryPolicy_BackoffJitter(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: time.Second,
+		Jitter:         0.5,
+	}
+	for i := 0; i < 100; i++ {
+		backoff := policy.Backoff(1)
+		assert.GreaterOrEqual(t, int64(backoff), int64(time.Millisecond*500))
+		assert.LessOrEqual(t, int64(backoff), int64(time.Millisecond*1500))
+	}
+}
+
+func TestRetryPolicy_BackoffJitterMaxBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Second * 2,
+		Jitter:         0.5,
+	}
+	for i := 0; i < 100; i++ {
+		backoff := policy.Backoff(5)
+		assert.GreaterOrEqual(t, int64(backoff), int64(time.Second))
+		assert.LessOrEqual(t, int64(backoff), int64(time.Second*2))
+	}
+}
+
+var errRetryDummy = errors.New("dummy error")
+
+var retryPolicyExecTestCases = []struct {
+	Name         string
+	BusPolicy    *RetryPolicy
+	SubPolicy    *RetryPolicy
+	FailingTimes int
+	WantAttempts int
+	WantErr      error
+}{
+	{
+		Name:         "no policy",
+		FailingTimes: 5,
+		WantAttempts: 1,
+		WantErr:      errRetryDummy,
+	},
+	{
+		Name:         "bus policy",
+		BusPolicy:    &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+		FailingTimes: 2,
+		WantAttempts: 3,
+		WantErr:      nil,
+	},
+	{
+		Name:         "subscriber policy overrides bus policy",
+		BusPolicy:    &RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Millisecond},
+		SubPolicy:    &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		FailingTimes: 5,
+		WantAttempts: 2,
+		WantErr:      errRetryDummy,
+	},
+	{
+		Name: "non-retryable error",
+		BusPolicy: &RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+			IsRetryable: func(err error) bool {
+				return !errors.Is(err, errRetryDummy)
+			},
+		},
+		FailingTimes: 5,
+		WantAttempts: 1,
+		WantErr:      errRetryDummy,
+	},
+}
+
+func TestRetryPolicy_InternalHandler(t *testing.T) {
+	for _, tt := range retryPolicyExecTestCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			bus := NewBus("local")
+			bus.Configuration.RetryPolicy = tt.BusPolicy
+			bus.RegisterSchema(dummySchema{}, WithTopic("foo.topic"))
+
+			attempts := 0
+			sub := bus.Subscribe(dummySchema{}).
+				HandlerFunc(func(_ context.Context, msg *Message) error {
+					attempts++
+					assert.Equal(t, attempts, msg.GetAttempt())
+					if attempts <= tt.FailingTimes {
+						return errRetryDummy
+					}
+					return nil
+				})
+			if tt.SubPolicy != nil {
+				sub.RetryPolicy(*tt.SubPolicy)
+			}
+
+			err := getInternalHandler(bus)(context.Background(), sub, &TransportMessage{
+				ID:   "123",
+				Data: []byte(`{"Foo":"bar"}`),
+			})
+			assert.Equal(t, tt.WantErr, err)
+			assert.Equal(t, tt.WantAttempts, attempts)
+		})
+	}
+}
+
+func TestRetryPolicy_InternalHandlerAttemptFailed(t *testing.T) {
+	bus := NewBus("local", WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	}))
+	bus.RegisterSchema(dummySchema{}, WithTopic("foo.topic"))
+	sub := bus.Subscribe(dummySchema{}).
+		HandlerFunc(func(_ context.Context, msg *Message) error {
+			if msg.GetAttempt() < 2 {
+				return errRetryDummy
+			}
+			return nil
+		})
+	msg := &TransportMessage{
+		ID:   "123",
+		Data: []byte(`{"Foo":"bar"}`),
+	}
+
+	failed := make([]int, 0)
+	ctx := WithAttemptFailedFunc(context.Background(), func(_ context.Context, attempt int, err error) error {
+		assert.ErrorIs(t, err, errRetryDummy)
+		failed = append(failed, attempt)
+		return nil
+	})
+	assert.NoError(t, getInternalHandler(bus)(ctx, sub, msg))
+	assert.Equal(t, []int{1}, failed)
+
+	// failing hooks stop retries
+	errHook := errors.New("hook error")
+	ctx = WithAttemptFailedFunc(context.Background(), func(_ context.Context, _ int, _ error) error {
+		return errHook
+	})
+	assert.ErrorIs(t, getInternalHandler(bus)(ctx, sub, msg), errHook)
+}
@@ -10,6 +10,8 @@ type Subscriber struct {
 	handler      Handler
 	handlerFunc  HandlerFunc
 	driverConfig interface{}
+	retryPolicy  *RetryPolicy
+	deadLetter   *DeadLetterPolicy
 }
 
 func newSubscriber(key string) *Subscriber {
@@ -51,6 +53,22 @@ func (e *Subscriber) DriverConfiguration(cfg interface{}) *Subscriber {
 	return e
 }
 
+// RetryPolicy Set a RetryPolicy for the Subscriber's handler.
+//
+// This policy takes precedence over the Bus global retry policy.
+func (e *Subscriber) RetryPolicy(p RetryPolicy) *Subscriber {
+	e.retryPolicy = &p
+	return e
+}
+
+// DeadLetterPolicy Set a DeadLetterPolicy for the Subscriber's handler.
+//
+// This policy takes precedence over the Bus global dead-letter policy.
+func (e *Subscriber) DeadLetterPolicy(p DeadLetterPolicy) *Subscriber {
+	e.deadLetter = &p
+	return e
+}
+
 // GetTopic Retrieve the Subscriber's topic name.
 func (e Subscriber) GetTopic() string {
 	return e.key
@@ -86,3 +104,13 @@ func (e Subscriber) GetGroup() string {
 func (e *Subscriber) GetDriverConfiguration() interface{} {
 	return e.driverConfig
 }
+
+// GetRetryPolicy Get the RetryPolicy of the Subscriber's handler.
+func (e *Subscriber) GetRetryPolicy() *RetryPolicy {
+	return e.retryPolicy
+}
+
+// GetDeadLetterPolicy Get the DeadLetterPolicy of the Subscriber's handler.
+func (e *Subscriber) GetDeadLetterPolicy() *DeadLetterPolicy {
+	return e.deadLetter
+}
@@ -38,3 +38,14 @@ func (r *subscriberRegistry) get(topic string) []*Subscriber {
 	defer r.mu.RUnlock()
 	return r.registry[topic]
 }
+
+// list Retrieve every registered subscriber.
+func (r *subscriberRegistry) list() []*Subscriber {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := make([]*Subscriber, 0, r.totalSubscribers)
+	for _, subs := range r.registry {
+		entries = append(entries, subs...)
+	}
+	return entries
+}
@@ -0,0 +1,46 @@
+package gluon
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/neutrinocorp/gluon/gutil"
+)
+
+// Topics Retrieve the topics required by the Bus sorted by name, useful to provision the message broker
+// (see Provisioner).
+//
+// Topics are the topics of registered schemas and subscribers along with the dead-letter topics of subscribers with a
+// DeadLetterPolicy (and their retry topics if DeadLetterPolicy.UseRetryTopic is set).
+func (b *Bus) Topics() []string {
+	set := map[string]struct{}{}
+	for _, topic := range b.internalSchemaRegistry.topics() {
+		set[topic] = struct{}{}
+	}
+	for _, sub := range b.subscriberRegistry.list() {
+		topic := sub.GetTopic()
+		set[topic] = struct{}{}
+		policy := getDeadLetterPolicy(b, sub)
+		if policy == nil || strings.HasSuffix(topic, gutil.DLQTopicSuffix) {
+			continue
+		}
+		set[gutil.GenerateDLQTopicName(topic)] = struct{}{}
+		if policy.UseRetryTopic {
+			set[gutil.GenerateRetryTopicName(topic)] = struct{}{}
+		}
+	}
+	delete(set, "")
+
+	topics := make([]string, 0, len(set))
+	for topic := range set {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Subscribers Retrieve every registered subscriber, useful to provision consumer resources of the message broker
+// (e.g. queues) along Topics.
+func (b *Bus) Subscribers() []*Subscriber {
+	return b.subscriberRegistry.list()
+}
@@ -0,0 +1,68 @@
+package gluon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_Topics(t *testing.T) {
+	bus := NewBus("local", WithReplyTopic("foo.replies"))
+	bus.RegisterSchema(dummySchema{}, WithTopic("foo.topic"))
+	bus.SubscribeTopic("foo.topic")
+	bus.SubscribeTopic("bar.topic").DeadLetterPolicy(DeadLetterPolicy{})
+	bus.SubscribeTopic("baz.topic").DeadLetterPolicy(DeadLetterPolicy{UseRetryTopic: true})
+	bus.SubscribeTopic("baz.topic.dlq").DeadLetterPolicy(DeadLetterPolicy{})
+	assert.Equal(t, []string{
+		"bar.topic",
+		"bar.topic.dlq",
+		"baz.topic",
+		"baz.topic.dlq",
+		"baz.topic.retry",
+		"foo.replies",
+		"foo.topic",
+	}, bus.Topics())
+	assert.Len(t, bus.Subscribers(), 5)
+
+	// global dead-letter policy
+	bus = NewBus("local", WithDeadLetterPolicy(DeadLetterPolicy{}))
+	bus.SubscribeTopic("foo.topic")
+	assert.Equal(t, []string{"foo.topic", "foo.topic.dlq"}, bus.Topics())
+}
+
+type provisionRecorderDriver struct {
+	publishRecorderDriver
+	provisioned bool
+	started     bool
+	err         error
+}
+
+var _ Provisioner = &provisionRecorderDriver{}
+
+func (d *provisionRecorderDriver) Provision(_ context.Context) error {
+	d.provisioned = true
+	return d.err
+}
+
+func (d *provisionRecorderDriver) Start(_ context.Context) error {
+	d.started = true
+	return nil
+}
+
+func TestBus_ListenAndServe_Provisioner(t *testing.T) {
+	errProvision := errors.New("provisioning failed")
+	driver := &provisionRecorderDriver{err: errProvision}
+	bus := NewBus("local")
+	bus.driver = driver
+	assert.ErrorIs(t, bus.ListenAndServe(), errProvision)
+	assert.True(t, driver.provisioned)
+	assert.False(t, driver.started)
+
+	driver = &provisionRecorderDriver{}
+	bus.driver = driver
+	assert.NoError(t, bus.ListenAndServe())
+	assert.True(t, driver.provisioned)
+	assert.True(t, driver.started)
+}
@@ -29,11 +29,36 @@ type TransportMessage struct {
 
 	// Custom Gluon fields
 
-	CorrelationID string      `json:"gluon_correlation_id"`
-	CausationID   string      `json:"gluon_causation_id"`
-	TraceContext  interface{} `json:"gluon_trace_context"`
+	CorrelationID string `json:"gluon_correlation_id"`
+	CausationID   string `json:"gluon_causation_id"`
+	// TraceContext Distributed tracing carrier (e.g. W3C traceparent and tracestate entries).
+	TraceContext map[string]string `json:"gluon_trace_context,omitempty"`
+
+	// Extensions CloudEvents extension attributes. Keys MUST be lower-case alphanumeric names.
+	Extensions map[string]string `json:"gluon_extensions,omitempty"`
 
 	// Internal fields
 	Topic         string            `json:"-"`
 	DriverHeaders map[string]string `json:"-"`
 }
+
+// SetAttribute Set a CloudEvents extension attribute, mapping `Gluon` extension attributes (e.g. correlationid,
+// traceparent) to their TransportMessage fields. Used by protocol bindings to decode messages.
+func (m *TransportMessage) SetAttribute(name, value string) {
+	switch name {
+	case ExtensionCorrelationID:
+		m.CorrelationID = value
+	case ExtensionCausationID:
+		m.CausationID = value
+	case ExtensionTraceParent, ExtensionTraceState:
+		if m.TraceContext == nil {
+			m.TraceContext = map[string]string{}
+		}
+		m.TraceContext[name] = value
+	default:
+		if m.Extensions == nil {
+			m.Extensions = map[string]string{}
+		}
+		m.Extensions[name] = value
+	}
+}
@@ -0,0 +1,20 @@
+package gluon
+
+import "context"
+
+// TypedMessage Is a Message with a concrete data type, used by typed subscribers (SubscribeTo).
+//
+// Every Message header accessor (e.g. GetMessageID, GetCorrelationID) is available. If the message carries no data
+// (Message.Data is nil), Data is the zero value of T.
+type TypedMessage[T any] struct {
+	*Message
+	Data T
+}
+
+// TypedHandler Is a component used to subscribe to a topic with a concrete message data type.
+type TypedHandler[T any] interface {
+	Handle(context.Context, *TypedMessage[T]) error
+}
+
+// TypedHandlerFunc Is an anonymous function used to subscribe to a topic with a concrete message data type.
+type TypedHandlerFunc[T any] func(context.Context, *TypedMessage[T]) error